@@ -0,0 +1,124 @@
+// Package config parses INI, Java .properties and dotenv configuration files.
+//
+// Each format is parsed into a Document, which keeps the sections and entries in the order
+// that they were written, along with the range of the input that each key and value was
+// parsed from. The ranges allow tools to rewrite a single value in place, without disturbing
+// the comments and layout of the rest of the file.
+package config
+
+import (
+	"github.com/a-h/lexical/parse"
+)
+
+// Document is a parsed configuration file.
+type Document struct {
+	// Sections are the sections of the document in the order that they were written. The
+	// first section has an empty name, and contains the entries which come before the first
+	// section header. Properties and dotenv files only have the first section.
+	Sections []*Section
+}
+
+// Section is a named group of entries, e.g. the [server] section of an INI file.
+type Section struct {
+	Name string
+	// NameRange is the range of the name within the section header. It's empty for the
+	// first section of a document.
+	NameRange parse.Range
+	Entries   []*Entry
+}
+
+// Entry is a key/value pair.
+type Entry struct {
+	Key      string
+	KeyRange parse.Range
+	// Value is the value after escape sequences have been decoded, continuation lines have
+	// been joined and variables have been interpolated.
+	Value string
+	// ValueRange is the range of the value as it was written, including any quotes, escape
+	// sequences and continuation lines.
+	ValueRange parse.Range
+}
+
+// Section returns the first section with the name, or nil if there isn't one. The entries
+// before the first section header are in the section with an empty name.
+func (d *Document) Section(name string) *Section {
+	for _, s := range d.Sections {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Get returns the value of the key within the named section.
+func (d *Document) Get(section, key string) (value string, ok bool) {
+	if s := d.Section(section); s != nil {
+		return s.Get(key)
+	}
+	return "", false
+}
+
+// Entry returns the last entry with the key, or nil if there isn't one.
+func (s *Section) Entry(key string) *Entry {
+	for i := len(s.Entries) - 1; i >= 0; i-- {
+		if s.Entries[i].Key == key {
+			return s.Entries[i]
+		}
+	}
+	return nil
+}
+
+// Get returns the value of the key. If the key is repeated, the last value is returned.
+func (s *Section) Get(key string) (value string, ok bool) {
+	if e := s.Entry(key); e != nil {
+		return e.Value, true
+	}
+	return "", false
+}
+
+// Map returns the values of the section's entries, keyed by their keys.
+func (s *Section) Map() map[string]string {
+	m := make(map[string]string, len(s.Entries))
+	for _, e := range s.Entries {
+		m[e.Key] = e.Value
+	}
+	return m
+}
+
+var spaces = parse.Span(parse.ClassOf(" \t"), 0, 0)
+var newline = parse.Or(parse.String("\r\n"), parse.Rune('\n'))
+var lineEnd = parse.ClassOf("\r\n")
+var lineText = lineEnd.Not()
+var restOfLine = parse.Span(lineText, 0, 0)
+
+// endOfLine captures a newline, or the end of the input.
+func endOfLine(pi parse.Input) bool {
+	return parse.Accept(pi, newline).Success || parse.EOF(pi).Success
+}
+
+// comment captures the rest of the line if it starts with one of the runes.
+func comment(pi parse.Input, starts string) bool {
+	if !parse.Accept(pi, parse.RuneIn(starts)).Success {
+		return false
+	}
+	restOfLine(pi)
+	return true
+}
+
+// trimmed captures runes in the class, and the spaces between them, and returns them
+// without leading and trailing spaces, along with their range. The trailing spaces are
+// consumed.
+func trimmed(pi parse.Input, c parse.Class) (s string, r parse.Range) {
+	text := parse.Span(c.Minus(parse.ClassOf(" \t")), 1, 0)
+	spaces(pi)
+	r.Start, r.End = parse.PosOf(pi), parse.PosOf(pi)
+	for {
+		ws := spaces(pi).Item.(string)
+		t := text(pi)
+		if !t.Success {
+			return s, r
+		}
+		s += ws + t.Item.(string)
+		r.End = parse.PosOf(pi)
+	}
+}
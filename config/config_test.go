@@ -0,0 +1,101 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/parse"
+)
+
+// entry is an entry of a document, and the text of its ranges.
+type entry struct {
+	section, key, value string
+	rawKey, rawValue    string
+}
+
+func entries(d *Document, src string) (actual []entry) {
+	for _, s := range d.Sections {
+		for _, e := range s.Entries {
+			actual = append(actual, entry{
+				section:  s.Name,
+				key:      e.Key,
+				value:    e.Value,
+				rawKey:   textOf(src, e.KeyRange),
+				rawValue: textOf(src, e.ValueRange),
+			})
+		}
+	}
+	return actual
+}
+
+func textOf(src string, r parse.Range) string {
+	return string([]rune(src)[r.Start.Index:r.End.Index])
+}
+
+type parserTest struct {
+	input         string
+	expected      []entry
+	expectedError string
+}
+
+func runParserTests(t *testing.T, parser func(pi parse.Input) (*Document, error), tests []parserTest) {
+	t.Helper()
+	for i, test := range tests {
+		d, err := parser(input.NewFromString(test.input))
+		if test.expectedError != "" {
+			if err == nil || err.Error() != test.expectedError {
+				t.Errorf("test %v: for input '%v' expected error %q, got %v", i, test.input, test.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %v: for input '%v' unexpected error: %v", i, test.input, err)
+			continue
+		}
+		if actual := entries(d, test.input); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("test %v: for input '%v'\nexpected %+v\ngot      %+v", i, test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestDocument(t *testing.T) {
+	src := "a = 1\n[server]\nhost = example.com\nport = 80\nport = 8080\n[empty]\n"
+	d, err := ParseINI(input.NewFromString(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, ok := d.Get("", "a"); !ok || v != "1" {
+		t.Errorf("expected a to be 1, got %q, %v", v, ok)
+	}
+	if v, ok := d.Get("server", "port"); !ok || v != "8080" {
+		t.Errorf("expected the last port, 8080, got %q, %v", v, ok)
+	}
+	if _, ok := d.Get("missing", "port"); ok {
+		t.Errorf("expected a missing section not to be found")
+	}
+	if _, ok := d.Get("empty", "port"); ok {
+		t.Errorf("expected a missing key not to be found")
+	}
+	if s := d.Section("server"); s == nil || textOf(src, s.NameRange) != "server" {
+		t.Errorf("expected the range of the section name to be found")
+	}
+	if m, expected := d.Section("server").Map(), map[string]string{"host": "example.com", "port": "8080"}; !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %v, got %v", expected, m)
+	}
+}
+
+func TestRewriteInPlace(t *testing.T) {
+	src := "# The server.\n[server]\nhost = example.com ; not a comment\nport = 80\n"
+	d, err := ParseINI(input.NewFromString(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Replace the value of the port, and leave everything else as it was.
+	r := d.Section("server").Entry("port").ValueRange
+	runes := []rune(src)
+	actual := string(runes[:r.Start.Index]) + "8080" + string(runes[r.End.Index:])
+	if expected := "# The server.\n[server]\nhost = example.com ; not a comment\nport = 8080\n"; actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
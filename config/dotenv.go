@@ -0,0 +1,201 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// ParseDotenv parses a dotenv file, e.g.:
+//
+//	# comment
+//	export HOST=example.com
+//	URL="https://${HOST}:${PORT:-443}/"
+//	GREETING='Hello, $USER' # not interpolated
+//
+// Values can be unquoted, single quoted or double quoted. Unquoted values end at the end of
+// the line, or at a '#' which follows a space, and have trailing spaces removed. Single
+// quoted values are used as they are written. Double quoted values support the escapes
+// \n \r \t \" \\ and \$. Quoted values may span multiple lines.
+//
+// Unquoted and double quoted values can refer to variables as $NAME, ${NAME}, or
+// ${NAME:-default}, where the default is used if the variable is unset or empty. Variables
+// are looked up in the entries which come before the reference, and then with lookup, e.g.
+// os.LookupEnv, if it's not nil. Variables which aren't found are replaced with an empty
+// string. All entries are in the first section of the document.
+func ParseDotenv(pi parse.Input, lookup func(name string) (value string, ok bool)) (*Document, error) {
+	section := &Section{}
+	d := &Document{Sections: []*Section{section}}
+	vars := map[string]string{}
+	get := func(name string) string {
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		if lookup != nil {
+			if v, ok := lookup(name); ok {
+				return v
+			}
+		}
+		return ""
+	}
+	for !parse.EOF(pi).Success {
+		spaces(pi)
+		if endOfLine(pi) || comment(pi, "#") {
+			continue
+		}
+		parse.Accept(pi, export)
+		e := &Entry{KeyRange: parse.Range{Start: parse.PosOf(pi)}}
+		k := parse.Accept(pi, variableName)
+		if !k.Success {
+			return nil, parse.Unexpected(pi, e.KeyRange.Start)
+		}
+		e.Key, e.KeyRange.End = k.Item.(string), parse.PosOf(pi)
+		spaces(pi)
+		if !parse.Accept(pi, parse.Rune('=')).Success {
+			return nil, parse.Errorf(parse.PosOf(pi), "expected '=' after %s", e.Key)
+		}
+		spaces(pi)
+		var err error
+		if e.Value, e.ValueRange, err = dotenvValue(pi, get); err != nil {
+			return nil, err
+		}
+		spaces(pi)
+		comment(pi, "#")
+		if pos := parse.PosOf(pi); !endOfLine(pi) {
+			return nil, parse.Unexpected(pi, pos)
+		}
+		vars[e.Key] = e.Value
+		section.Entries = append(section.Entries, e)
+	}
+	return d, nil
+}
+
+var export = parse.Then(parse.WithStringConcatCombiner, parse.String("export"), parse.Span(parse.ClassOf(" \t"), 1, 0))
+
+var nameStart = parse.ClassRange('a', 'z').Union(parse.ClassRange('A', 'Z'), parse.ClassOf("_"))
+var nameContinue = nameStart.Union(parse.ClassRange('0', '9'))
+var variableName = parse.Then(parse.WithStringConcatCombiner, parse.Span(nameStart, 1, 1), parse.Span(nameContinue.Union(parse.ClassOf(".")), 0, 0))
+
+// interpolatedName is the name of a variable within a value, which can't contain dots, so
+// that "$HOST." is the value of HOST followed by a dot.
+var interpolatedName = parse.Then(parse.WithStringConcatCombiner, parse.Span(nameStart, 1, 1), parse.Span(nameContinue, 0, 0))
+
+var singleQuoted = parse.StringLiteral(parse.StringLiteralOptions{
+	Quotes:    "'",
+	MultiLine: true,
+})
+
+var unquotedText = parse.Span(parse.ClassOf(" \t\r\n$").Not(), 1, 0)
+var doubleQuotedText = parse.Span(parse.ClassOf("\"\\$").Not(), 1, 0)
+
+func dotenvValue(pi parse.Input, get func(name string) string) (s string, r parse.Range, err error) {
+	r.Start = parse.PosOf(pi)
+	defer func() {
+		r.End = parse.PosOf(pi)
+	}()
+	if v := parse.Accept(pi, singleQuoted); v.Success {
+		return v.Item.(string), r, nil
+	} else if v.Error != nil {
+		return "", r, v.Error
+	}
+	var sb strings.Builder
+	if parse.Accept(pi, parse.Rune('"')).Success {
+		for {
+			if t := doubleQuotedText(pi); t.Success {
+				sb.WriteString(t.Item.(string))
+				continue
+			}
+			pos := parse.PosOf(pi)
+			if parse.Accept(pi, parse.Rune('"')).Success {
+				return sb.String(), r, nil
+			}
+			if parse.Accept(pi, parse.Rune('\\')).Success {
+				e := parse.Accept(pi, parse.AnyRune())
+				if !e.Success {
+					break
+				}
+				switch c := e.Item.(rune); c {
+				case 'n':
+					sb.WriteByte('\n')
+				case 'r':
+					sb.WriteByte('\r')
+				case 't':
+					sb.WriteByte('\t')
+				case '"', '\\', '$':
+					sb.WriteRune(c)
+				default:
+					sb.WriteByte('\\')
+					sb.WriteRune(c)
+				}
+				continue
+			}
+			v, ok, err := variable(pi, pos, get)
+			if err != nil {
+				return "", r, err
+			}
+			if !ok {
+				break
+			}
+			sb.WriteString(v)
+		}
+		return "", r, parse.Errorf(r.Start, "unterminated string")
+	}
+	// The end of an unquoted value is the end of the last text before a comment or the end
+	// of the line, so it's tracked separately.
+	end := parse.PosOf(pi)
+	for {
+		ws := spaces(pi).Item.(string)
+		pos := parse.PosOf(pi)
+		if ws != "" && parse.Accept(pi, parse.Rune('#')).Success {
+			parse.RewindTo(pi, pos.Index)
+			break
+		}
+		if t := unquotedText(pi); t.Success {
+			sb.WriteString(ws + t.Item.(string))
+			end = parse.PosOf(pi)
+			continue
+		}
+		v, ok, err := variable(pi, pos, get)
+		if err != nil {
+			return "", r, err
+		}
+		if !ok {
+			break
+		}
+		sb.WriteString(ws + v)
+		end = parse.PosOf(pi)
+	}
+	parse.RewindTo(pi, end.Index)
+	return sb.String(), r, nil
+}
+
+// variable captures a variable reference which starts with a '$', and returns its value. A
+// '$' which isn't followed by a name stands for itself.
+func variable(pi parse.Input, pos parse.Pos, get func(name string) string) (v string, ok bool, err error) {
+	if !parse.Accept(pi, parse.Rune('$')).Success {
+		return "", false, nil
+	}
+	if n := parse.Accept(pi, interpolatedName); n.Success {
+		return get(n.Item.(string)), true, nil
+	}
+	if !parse.Accept(pi, parse.Rune('{')).Success {
+		return "$", true, nil
+	}
+	n := parse.Accept(pi, interpolatedName)
+	if !n.Success {
+		return "", false, parse.Errorf(pos, "invalid variable reference")
+	}
+	v = get(n.Item.(string))
+	if parse.Accept(pi, parse.String(":-")).Success {
+		d := defaultValue(pi).Item.(string)
+		if v == "" {
+			v = d
+		}
+	}
+	if !parse.Accept(pi, parse.Rune('}')).Success {
+		return "", false, parse.Errorf(parse.PosOf(pi), "expected '}' at the end of the variable %s", n.Item)
+	}
+	return v, true, nil
+}
+
+var defaultValue = parse.Span(parse.ClassOf("}\r\n").Not(), 0, 0)
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/a-h/lexical/parse"
+)
+
+func TestDotenv(t *testing.T) {
+	env := map[string]string{"USER": "alice", "EMPTY": ""}
+	parser := func(pi parse.Input) (*Document, error) {
+		return ParseDotenv(pi, func(name string) (string, bool) {
+			v, ok := env[name]
+			return v, ok
+		})
+	}
+	runParserTests(t, parser, []parserTest{
+		{input: "# comment\n\n", expected: nil},
+		{
+			input: "A=1\nexport B = two words  # comment\nC=#not a comment\nexport=3\n",
+			expected: []entry{
+				{key: "A", value: "1", rawKey: "A", rawValue: "1"},
+				{key: "B", value: "two words", rawKey: "B", rawValue: "two words"},
+				{key: "C", value: "#not a comment", rawKey: "C", rawValue: "#not a comment"},
+				{key: "export", value: "3", rawKey: "export", rawValue: "3"},
+			},
+		},
+		{
+			input: "S='single $USER \\n'\nD=\"double $USER\\n\\\"q\\\" \\$USER\" # comment\n",
+			expected: []entry{
+				{key: "S", value: "single $USER \\n", rawKey: "S", rawValue: "'single $USER \\n'"},
+				{key: "D", value: "double alice\n\"q\" $USER", rawKey: "D", rawValue: "\"double $USER\\n\\\"q\\\" \\$USER\""},
+			},
+		},
+		{
+			input: "HOST=example.com\nURL=https://${HOST}:${PORT:-443}/$USER.$MISSING/$\nE=${EMPTY:-default}\n",
+			expected: []entry{
+				{key: "HOST", value: "example.com", rawKey: "HOST", rawValue: "example.com"},
+				{key: "URL", value: "https://example.com:443/alice./$", rawKey: "URL", rawValue: "https://${HOST}:${PORT:-443}/$USER.$MISSING/$"},
+				{key: "E", value: "default", rawKey: "E", rawValue: "${EMPTY:-default}"},
+			},
+		},
+		{
+			input:    "CERT=\"line 1\nline 2\"\n",
+			expected: []entry{{key: "CERT", value: "line 1\nline 2", rawKey: "CERT", rawValue: "\"line 1\nline 2\""}},
+		},
+		{input: "A=\"unterminated", expectedError: "line 1, column 2: unterminated string"},
+		{input: "A='unterminated", expectedError: "line 1, column 2: unterminated string literal"},
+		{input: "A=${B", expectedError: "line 1, column 5: expected '}' at the end of the variable B"},
+		{input: "A=${}", expectedError: "line 1, column 2: invalid variable reference"},
+		{input: "A='1' 2", expectedError: "line 1, column 6: unexpected '2'"},
+		{input: "1A=1", expectedError: "line 1, column 0: unexpected '1'"},
+		{input: "A 1", expectedError: "line 1, column 2: expected '=' after A"},
+	})
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"github.com/a-h/lexical/parse"
+)
+
+// ParseINI parses an INI file, e.g.:
+//
+//	; comment
+//	[server]
+//	host = example.com
+//	description: a value which
+//	  continues on indented lines
+//
+// Keys are separated from values by '=' or ':', and the keys, values and section names
+// have leading and trailing spaces removed. Lines which start with ';' or '#' are comments.
+// Comments can't follow a value on the same line, so that values can contain those runes.
+// An indented line which follows an entry continues its value, and is joined to it with a
+// newline. If the input is invalid, the error is a *parse.Error which contains the position
+// of the problem.
+func ParseINI(pi parse.Input) (*Document, error) {
+	section := &Section{}
+	d := &Document{Sections: []*Section{section}}
+	var last *Entry
+	for !parse.EOF(pi).Success {
+		indented := spaces(pi).Item.(string) != ""
+		switch {
+		case endOfLine(pi):
+			// A blank line ends a value.
+			last = nil
+			continue
+		case comment(pi, ";#"):
+		case indented && last != nil:
+			value, r := trimmed(pi, lineText)
+			last.Value += "\n" + value
+			last.ValueRange.End = r.End
+		case parse.Accept(pi, parse.Rune('[')).Success:
+			name, r := trimmed(pi, iniSectionName)
+			if !parse.Accept(pi, parse.Rune(']')).Success {
+				return nil, parse.Errorf(parse.PosOf(pi), "expected ']' at the end of the section name")
+			}
+			spaces(pi)
+			comment(pi, ";#")
+			section = &Section{Name: name, NameRange: r}
+			d.Sections = append(d.Sections, section)
+			last = nil
+		default:
+			e, err := iniEntry(pi)
+			if err != nil {
+				return nil, err
+			}
+			section.Entries = append(section.Entries, e)
+			last = e
+		}
+		if pos := parse.PosOf(pi); !endOfLine(pi) {
+			return nil, parse.Unexpected(pi, pos)
+		}
+	}
+	return d, nil
+}
+
+var iniKey = parse.ClassOf("=:\r\n").Not()
+var iniSectionName = parse.ClassOf("]\r\n").Not()
+
+func iniEntry(pi parse.Input) (e *Entry, err error) {
+	e = &Entry{}
+	e.Key, e.KeyRange = trimmed(pi, iniKey)
+	if e.Key == "" {
+		return nil, parse.Unexpected(pi, parse.PosOf(pi))
+	}
+	if !parse.Accept(pi, parse.RuneIn("=:")).Success {
+		return nil, parse.Errorf(parse.PosOf(pi), "expected '=' or ':' after the key %q", e.Key)
+	}
+	e.Value, e.ValueRange = trimmed(pi, lineText)
+	return e, nil
+}
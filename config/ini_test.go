@@ -0,0 +1,44 @@
+package config
+
+import "testing"
+
+func TestINI(t *testing.T) {
+	runParserTests(t, ParseINI, []parserTest{
+		{input: "", expected: nil},
+		{input: "\n\n; comment\n# comment\n", expected: nil},
+		{
+			input:    "key=value",
+			expected: []entry{{key: "key", value: "value", rawKey: "key", rawValue: "value"}},
+		},
+		{
+			input: "  spaced key  =  spaced value  \r\nb: 2\n",
+			expected: []entry{
+				{key: "spaced key", value: "spaced value", rawKey: "spaced key", rawValue: "spaced value"},
+				{key: "b", value: "2", rawKey: "b", rawValue: "2"},
+			},
+		},
+		{
+			input: "a = 1\n[ section one ] ; comment\nb = 2\n[two]\nc =\n",
+			expected: []entry{
+				{key: "a", value: "1", rawKey: "a", rawValue: "1"},
+				{section: "section one", key: "b", value: "2", rawKey: "b", rawValue: "2"},
+				{section: "two", key: "c", rawKey: "c"},
+			},
+		},
+		{
+			input: "[paths]\nsearch = /usr/bin\n   /usr/local/bin\n\t/opt/bin\n\n  indented = new key\n",
+			expected: []entry{
+				{section: "paths", key: "search", value: "/usr/bin\n/usr/local/bin\n/opt/bin", rawKey: "search", rawValue: "/usr/bin\n   /usr/local/bin\n\t/opt/bin"},
+				{section: "paths", key: "indented", value: "new key", rawKey: "indented", rawValue: "new key"},
+			},
+		},
+		{
+			input:    "url = http://example.com/#top ; see: docs",
+			expected: []entry{{key: "url", value: "http://example.com/#top ; see: docs", rawKey: "url", rawValue: "http://example.com/#top ; see: docs"}},
+		},
+		{input: "[section", expectedError: "line 1, column 8: expected ']' at the end of the section name"},
+		{input: "[section] x", expectedError: "line 1, column 10: unexpected 'x'"},
+		{input: "a = 1\nkey only\n", expectedError: "line 2, column 8: expected '=' or ':' after the key \"key only\""},
+		{input: "= value", expectedError: "line 1, column 0: unexpected '='"},
+	})
+}
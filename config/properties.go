@@ -0,0 +1,116 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// ParseProperties parses a Java .properties file, in the same way as the load method of
+// java.util.Properties, e.g.:
+//
+//	# comment
+//	key = value
+//	greeting: café
+//	path=/usr/local/\
+//	     bin
+//
+// The key ends at the first unescaped '=', ':' or space, and the value is the rest of the
+// line, including trailing spaces. Lines which start with '#' or '!' are comments. A
+// backslash at the end of a line continues the key or value on the next line, without the
+// next line's leading spaces. The escapes \t \n \r \f and \uXXXX are decoded, and other
+// escaped runes stand for themselves. All entries are in the first section of the document.
+func ParseProperties(pi parse.Input) (*Document, error) {
+	section := &Section{}
+	d := &Document{Sections: []*Section{section}}
+	for !parse.EOF(pi).Success {
+		propertiesSpaces(pi)
+		if endOfLine(pi) || comment(pi, "#!") {
+			continue
+		}
+		e := &Entry{}
+		var err error
+		if e.Key, e.KeyRange, err = propertiesText(pi, propertiesKey); err != nil {
+			return nil, err
+		}
+		propertiesSpaces(pi)
+		if parse.Accept(pi, parse.RuneIn("=:")).Success {
+			propertiesSpaces(pi)
+		}
+		if e.Value, e.ValueRange, err = propertiesText(pi, lineText); err != nil {
+			return nil, err
+		}
+		section.Entries = append(section.Entries, e)
+		// The value runs to the end of the line.
+		endOfLine(pi)
+	}
+	return d, nil
+}
+
+var propertiesSpaces = parse.Span(parse.ClassOf(" \t\f"), 0, 0)
+var propertiesKey = parse.ClassOf("=: \t\f\r\n").Not()
+
+var hexDigits = parse.Span(parse.ClassRange('0', '9').Union(parse.ClassRange('a', 'f'), parse.ClassRange('A', 'F')), 4, 4)
+
+// propertiesText captures runes in the class, escape sequences and continuation lines, and
+// returns the decoded text and its range.
+func propertiesText(pi parse.Input, c parse.Class) (s string, r parse.Range, err error) {
+	text := parse.Span(c.Minus(parse.ClassOf(`\`)), 1, 0)
+	var sb strings.Builder
+	// A rune outside the Basic Multilingual Plane is written as a pair of \u escapes, so the
+	// escapes are decoded as UTF-16.
+	var units []uint16
+	flush := func() {
+		sb.WriteString(string(utf16.Decode(units)))
+		units = units[:0]
+	}
+	r.Start = parse.PosOf(pi)
+	for {
+		if t := text(pi); t.Success {
+			flush()
+			sb.WriteString(t.Item.(string))
+			continue
+		}
+		pos := parse.PosOf(pi)
+		if !parse.Accept(pi, parse.Rune('\\')).Success {
+			break
+		}
+		if parse.Accept(pi, newline).Success {
+			propertiesSpaces(pi)
+			continue
+		}
+		if parse.Accept(pi, parse.Rune('u')).Success {
+			h := parse.Accept(pi, hexDigits)
+			if !h.Success {
+				return "", r, parse.Errorf(pos, "invalid \\u escape")
+			}
+			v, _ := strconv.ParseUint(h.Item.(string), 16, 16)
+			units = append(units, uint16(v))
+			continue
+		}
+		flush()
+		e := parse.AnyRune()(pi)
+		if !e.Success {
+			// A backslash at the end of the input is ignored.
+			parse.RewindTo(pi, pos.Index+1)
+			break
+		}
+		switch e.Item.(rune) {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		default:
+			sb.WriteRune(e.Item.(rune))
+		}
+	}
+	flush()
+	r.End = parse.PosOf(pi)
+	return sb.String(), r, nil
+}
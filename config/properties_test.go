@@ -0,0 +1,39 @@
+package config
+
+import "testing"
+
+func TestProperties(t *testing.T) {
+	runParserTests(t, ParseProperties, []parserTest{
+		{input: "# comment\n! comment\n\n", expected: nil},
+		{
+			input: "a=1\nb : 2\nc 3\n  d\t=\t4  \ne\n",
+			expected: []entry{
+				{key: "a", value: "1", rawKey: "a", rawValue: "1"},
+				{key: "b", value: "2", rawKey: "b", rawValue: "2"},
+				{key: "c", value: "3", rawKey: "c", rawValue: "3"},
+				{key: "d", value: "4  ", rawKey: "d", rawValue: "4  "},
+				{key: "e", rawKey: "e"},
+			},
+		},
+		{
+			input:    `key\ with\=specials = a\tb\nc\\d\e`,
+			expected: []entry{{key: "key with=specials", value: "a\tb\nc\\de", rawKey: `key\ with\=specials`, rawValue: `a\tb\nc\\d\e`}},
+		},
+		{
+			input:    `greeting = caf\u00e9 \ud83d\ude00 \u0041`,
+			expected: []entry{{key: "greeting", value: "café 😀 A", rawKey: "greeting", rawValue: `caf\u00e9 \ud83d\ude00 \u0041`}},
+		},
+		{
+			input: "path=/usr/bin:\\\n     /usr/local/bin\nnext=1\\",
+			expected: []entry{
+				{key: "path", value: "/usr/bin:/usr/local/bin", rawKey: "path", rawValue: "/usr/bin:\\\n     /usr/local/bin"},
+				{key: "next", value: "1", rawKey: "next", rawValue: "1\\"},
+			},
+		},
+		{
+			input:    "windows=1\r\nnext=2\r\n",
+			expected: []entry{{key: "windows", value: "1", rawKey: "windows", rawValue: "1"}, {key: "next", value: "2", rawKey: "next", rawValue: "2"}},
+		},
+		{input: `a = \u00g1`, expectedError: `line 1, column 4: invalid \u escape`},
+	})
+}
@@ -0,0 +1,178 @@
+// Package csv reads delimiter-separated values, such as RFC 4180 CSV and TSV, using the
+// parse package's combinators.
+//
+// Records are read one at a time from a parse.Input, which is collected after each record,
+// so large files can be streamed. Each field is returned with the range of the input it was
+// parsed from, and errors contain the line and column of the problem.
+//
+// The grammar of a field can be replaced, e.g. to parse numbers or dates into Go values, by
+// setting Options.Field. The Field function returns the default grammar, so that it can be
+// combined with custom parsers.
+package csv
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// Options configures the syntax of the input.
+type Options struct {
+	// Delimiter separates fields, e.g. ',' or '\t'.
+	Delimiter rune
+	// Quote starts and ends a quoted field, which may contain delimiters and newlines, or is
+	// zero if fields can't be quoted.
+	Quote rune
+	// Escape escapes the quote, or itself, within a quoted field, e.g. '\\'. If it's zero, the
+	// quote is escaped by doubling it, as in RFC 4180.
+	Escape rune
+	// Comment starts a line which is ignored, e.g. '#', or is zero if comments aren't allowed.
+	Comment rune
+	// FieldsPerRecord is the number of fields that each record must have. If it's zero, all
+	// records must have the same number of fields as the first. If it's negative, records may
+	// have any number of fields.
+	FieldsPerRecord int
+	// Field captures the value of a single field. If it's nil, Field(opts) is used. The
+	// parser must stop before the delimiter or the end of the line.
+	Field parse.Function
+}
+
+// CSV is the syntax of RFC 4180 comma-separated values.
+var CSV = Options{
+	Delimiter: ',',
+	Quote:     '"',
+}
+
+// TSV is the syntax of tab-separated values, which can't contain quoted fields.
+var TSV = Options{
+	Delimiter: '\t',
+}
+
+// Field returns the default grammar for a field, which captures a quoted or unquoted field,
+// and returns its value as a string.
+func Field(opts Options) parse.Function {
+	special := []rune{opts.Delimiter, '\r', '\n'}
+	if opts.Quote != 0 {
+		special = append(special, opts.Quote)
+	}
+	unquoted := parse.Span(parse.ClassOf(string(special)).Not(), 0, 0)
+	if opts.Quote == 0 {
+		return unquoted
+	}
+	quoted := parse.StringLiteral(parse.StringLiteralOptions{
+		Quotes:        string(opts.Quote),
+		Escape:        opts.Escape,
+		Escapes:       string([]rune{opts.Quote, opts.Escape}),
+		DoubledQuotes: opts.Escape == 0,
+		MultiLine:     true,
+	})
+	return parse.Or(quoted, unquoted)
+}
+
+// Record is a row of fields. Each item is a field value, e.g. a string, and the range of the
+// input it was parsed from.
+type Record []parse.Ranged
+
+// Strings returns the values of the fields as strings.
+func (r Record) Strings() []string {
+	s := make([]string, len(r))
+	for i, f := range r {
+		if v, ok := f.Item.(string); ok {
+			s[i] = v
+			continue
+		}
+		s[i] = fmt.Sprint(f.Item)
+	}
+	return s
+}
+
+// Reader reads records from an input.
+type Reader struct {
+	input     parse.Input
+	opts      Options
+	delimiter parse.Function
+	header    []string
+	columns   map[string]int
+	bindings  map[reflect.Type][]binding
+	err       error
+}
+
+var newline = parse.Or(parse.String("\r\n"), parse.Rune('\n'))
+
+// NewReader creates a Reader which reads from the input.
+func NewReader(pi parse.Input, opts Options) *Reader {
+	if opts.Field == nil {
+		opts.Field = Field(opts)
+	}
+	opts.Field = parse.WithRange(opts.Field)
+	return &Reader{
+		input:     pi,
+		opts:      opts,
+		delimiter: parse.Rune(opts.Delimiter),
+	}
+}
+
+// Read returns the next record. At the end of the input, io.EOF is returned. Empty lines and
+// comment lines are skipped. If the input is invalid, the error is a *parse.Error which
+// contains the position of the problem, and all further calls return the same error.
+func (r *Reader) Read() (rec Record, err error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	rec, err = r.read()
+	if err != nil {
+		r.err = err
+	}
+	return rec, err
+}
+
+func (r *Reader) read() (rec Record, err error) {
+	r.skip()
+	if parse.EOF(r.input).Success {
+		return nil, io.EOF
+	}
+	start := parse.PosOf(r.input)
+	for {
+		pos := parse.PosOf(r.input)
+		result := r.opts.Field(r.input)
+		if !result.Success {
+			if result.Error != nil && result.Error != io.EOF {
+				return nil, result.Error
+			}
+			return nil, parse.Unexpected(r.input, pos)
+		}
+		rec = append(rec, result.Item.(parse.Ranged))
+		if r.delimiter(r.input).Success {
+			continue
+		}
+		if newline(r.input).Success || parse.EOF(r.input).Success {
+			break
+		}
+		return nil, parse.Unexpected(r.input, parse.PosOf(r.input))
+	}
+	r.input.Collect()
+	if r.opts.FieldsPerRecord == 0 {
+		r.opts.FieldsPerRecord = len(rec)
+	}
+	if r.opts.FieldsPerRecord > 0 && len(rec) != r.opts.FieldsPerRecord {
+		return nil, parse.Errorf(start, "wrong number of fields, expected %d, got %d", r.opts.FieldsPerRecord, len(rec))
+	}
+	return rec, nil
+}
+
+// skip skips empty lines and comment lines.
+func (r *Reader) skip() {
+	for {
+		if newline(r.input).Success {
+			continue
+		}
+		if r.opts.Comment != 0 && parse.Rune(r.opts.Comment)(r.input).Success {
+			parse.StringUntilDelimiterOrEOF(newline)(r.input)
+			continue
+		}
+		r.input.Collect()
+		return
+	}
+}
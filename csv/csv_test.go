@@ -0,0 +1,239 @@
+package csv
+
+import (
+	stdcsv "encoding/csv"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/parse"
+)
+
+func readAll(r *Reader) (records [][]string, err error) {
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, rec.Strings())
+	}
+}
+
+// conformance contains documents which are checked against encoding/csv, which must agree
+// on whether they're valid, and on their records.
+var conformance = []string{
+	``,
+	"a,b,c\n",
+	"a,b,c",
+	"a,b,c\r\n1,2,3\r\n",
+	"a,,c\n,,\n",
+	"\n\na,b\n\n\nc,d\n",
+	`"a","b,c","d""e"`,
+	"\"multi\nline\",x\n",
+	`"",""`,
+	`a "b",c`,
+	`"a"b,c`,
+	`"unterminated`,
+	"a,b\nc\n",
+	"a,b\nc,d,e\n",
+	"héllo,wörld\n",
+	" a , b \n",
+}
+
+func TestConformance(t *testing.T) {
+	for _, doc := range conformance {
+		expected, expectedErr := stdcsv.NewReader(strings.NewReader(doc)).ReadAll()
+		actual, actualErr := readAll(NewReader(input.NewFromString(doc), CSV))
+		if (expectedErr == nil) != (actualErr == nil) {
+			t.Errorf("for %q, encoding/csv returned error %v, but got %v", doc, expectedErr, actualErr)
+			continue
+		}
+		if expectedErr == nil && !reflect.DeepEqual(expected, actual) {
+			t.Errorf("for %q, expected %q, got %q", doc, expected, actual)
+		}
+	}
+}
+
+func TestOptions(t *testing.T) {
+	tests := []struct {
+		input    string
+		opts     Options
+		expected [][]string
+	}{
+		{
+			// Unlike encoding/csv, line endings within quoted fields are preserved.
+			input:    "\"multi\r\nline\",x\r\n",
+			opts:     CSV,
+			expected: [][]string{{"multi\r\nline", "x"}},
+		},
+		{
+			input:    "a\tb\n\"c\"\td\n",
+			opts:     TSV,
+			expected: [][]string{{"a", "b"}, {`"c"`, "d"}},
+		},
+		{
+			input:    "a;'b;c'\n",
+			opts:     Options{Delimiter: ';', Quote: '\''},
+			expected: [][]string{{"a", "b;c"}},
+		},
+		{
+			input:    `"a\"b\\c",d`,
+			opts:     Options{Delimiter: ',', Quote: '"', Escape: '\\'},
+			expected: [][]string{{`a"b\c`, "d"}},
+		},
+		{
+			input:    "# comment, with comma\na,b\n#another\nc,d\n",
+			opts:     Options{Delimiter: ',', Quote: '"', Comment: '#'},
+			expected: [][]string{{"a", "b"}, {"c", "d"}},
+		},
+		{
+			input:    "a,b\nc\nd,e,f\n",
+			opts:     Options{Delimiter: ',', Quote: '"', FieldsPerRecord: -1},
+			expected: [][]string{{"a", "b"}, {"c"}, {"d", "e", "f"}},
+		},
+	}
+
+	for i, test := range tests {
+		actual, err := readAll(NewReader(input.NewFromString(test.input), test.opts))
+		if err != nil {
+			t.Errorf("test %v: for input '%v' unexpected error: %v", i, test.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("test %v: for input '%v' expected %q, got %q", i, test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		opts     Options
+		expected string
+	}{
+		{
+			input:    "a,b\n\"c,d\n",
+			opts:     CSV,
+			expected: "line 2, column 0: unterminated string literal",
+		},
+		{
+			input:    "a,b\nc,\"d\"e\n",
+			opts:     CSV,
+			expected: "line 2, column 5: unexpected 'e'",
+		},
+		{
+			input:    "a,b\nc,d\"\n",
+			opts:     CSV,
+			expected: "line 2, column 3: unexpected '\"'",
+		},
+		{
+			input:    "a,b\n\nc\n",
+			opts:     CSV,
+			expected: "line 3, column 0: wrong number of fields, expected 2, got 1",
+		},
+		{
+			input:    `"a\qb"`,
+			opts:     Options{Delimiter: ',', Quote: '"', Escape: '\\'},
+			expected: `line 1, column 2: invalid escape sequence "\\q"`,
+		},
+	}
+
+	for i, test := range tests {
+		_, err := readAll(NewReader(input.NewFromString(test.input), test.opts))
+		if err == nil {
+			t.Errorf("test %v: for input '%v' expected error %q, got nil", i, test.input, test.expected)
+			continue
+		}
+		if _, ok := err.(*parse.Error); !ok {
+			t.Errorf("test %v: for input '%v' expected *parse.Error, got %T", i, test.input, err)
+		}
+		if err.Error() != test.expected {
+			t.Errorf("test %v: for input '%v' expected error %q, got %q", i, test.input, test.expected, err.Error())
+		}
+	}
+}
+
+func TestRanges(t *testing.T) {
+	r := NewReader(input.NewFromString("a,\"b\nc\"\nd,e"), CSV)
+	expected := [][]parse.Range{
+		{
+			{Start: parse.Pos{Index: 0, Line: 1, Column: 0}, End: parse.Pos{Index: 1, Line: 1, Column: 1}},
+			{Start: parse.Pos{Index: 2, Line: 1, Column: 2}, End: parse.Pos{Index: 7, Line: 2, Column: 2}},
+		},
+		{
+			{Start: parse.Pos{Index: 8, Line: 3, Column: 0}, End: parse.Pos{Index: 9, Line: 3, Column: 1}},
+			{Start: parse.Pos{Index: 10, Line: 3, Column: 2}, End: parse.Pos{Index: 11, Line: 3, Column: 3}},
+		},
+	}
+	for i, ranges := range expected {
+		rec, err := r.Read()
+		if err != nil {
+			t.Fatalf("record %d: unexpected error: %v", i, err)
+		}
+		for j, f := range rec {
+			if f.Range != ranges[j] {
+				t.Errorf("record %d, field %d: expected range %v, got %v", i, j, ranges[j], f.Range)
+			}
+		}
+	}
+}
+
+func TestCustomField(t *testing.T) {
+	opts := CSV
+	opts.Field = parse.Or(parse.Float(64), Field(CSV))
+	r := NewReader(input.NewFromString("name,1.5\n\"2\",-3\n"), opts)
+	var actual [][]interface{}
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var values []interface{}
+		for _, f := range rec {
+			values = append(values, f.Item)
+		}
+		actual = append(actual, values)
+	}
+	expected := [][]interface{}{{"name", 1.5}, {"2", -3.0}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCustomFieldMustEndAtDelimiter(t *testing.T) {
+	opts := CSV
+	opts.Field = parse.Float(64)
+	_, err := readAll(NewReader(input.NewFromString("1,2x\n"), opts))
+	expected := "line 1, column 3: unexpected 'x'"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
+
+var benchmarkDocument = strings.Repeat("1234,\"quoted, field\",plain text,\"with \"\"quotes\"\"\"\n", 100)
+
+func BenchmarkRead(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		if _, err := readAll(NewReader(input.NewFromString(benchmarkDocument), CSV)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodingCSV(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		if _, err := stdcsv.NewReader(strings.NewReader(benchmarkDocument)).ReadAll(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
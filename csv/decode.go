@@ -0,0 +1,183 @@
+package csv
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// ReadHeader reads the next record, and uses it as the names of the columns for Decode.
+func (r *Reader) ReadHeader() ([]string, error) {
+	rec, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	r.header = rec.Strings()
+	r.columns = make(map[string]int, len(r.header))
+	for i, name := range r.header {
+		if _, ok := r.columns[name]; !ok {
+			r.columns[name] = i
+		}
+	}
+	return r.header, nil
+}
+
+// Header returns the names of the columns, or nil if the header hasn't been read.
+func (r *Reader) Header() []string {
+	return r.header
+}
+
+// Decode reads the next record into the struct that v points to. If the header hasn't been
+// read, it's read first. Each exported field is set from the column named by its `csv` tag,
+// or its name, in any case, if it doesn't have a tag. Fields with the tag `csv:"-"` are ignored.
+//
+// String values are converted to the type of the field, which can be a string, bool,
+// integer, float, encoding.TextUnmarshaler, or a pointer to one of these. Empty values leave
+// the field as its zero value. Values which have been parsed into other types by a custom
+// field grammar are assigned if they're assignable to the field.
+//
+// At the end of the input, io.EOF is returned.
+func (r *Reader) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("csv: Decode requires a non-nil pointer to a struct")
+	}
+	if r.header == nil {
+		if _, err := r.ReadHeader(); err != nil {
+			return err
+		}
+	}
+	rv = rv.Elem()
+	bindings, err := r.bind(rv.Type())
+	if err != nil {
+		return err
+	}
+	rec, err := r.Read()
+	if err != nil {
+		return err
+	}
+	for _, b := range bindings {
+		if b.column >= len(rec) {
+			continue
+		}
+		f := rec[b.column]
+		if err := set(rv.Field(b.field), f.Item); err != nil {
+			return &parse.Error{Pos: f.Range.Start, Err: fmt.Errorf("column %q: %w", r.header[b.column], err)}
+		}
+	}
+	return nil
+}
+
+type binding struct {
+	field  int
+	column int
+}
+
+// bind maps the fields of the struct type to columns of the header.
+func (r *Reader) bind(t reflect.Type) (bindings []binding, err error) {
+	if bindings, ok := r.bindings[t]; ok {
+		return bindings, nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("csv"); ok {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+		column, ok := r.column(name)
+		if !ok {
+			return nil, fmt.Errorf("csv: column %q of field %s not found in header", name, f.Name)
+		}
+		bindings = append(bindings, binding{field: i, column: column})
+	}
+	if r.bindings == nil {
+		r.bindings = make(map[reflect.Type][]binding)
+	}
+	r.bindings[t] = bindings
+	return bindings, nil
+}
+
+// column returns the index of the named column, matching the case of the name if possible.
+func (r *Reader) column(name string) (int, bool) {
+	if i, ok := r.columns[name]; ok {
+		return i, true
+	}
+	for i, h := range r.header {
+		if strings.EqualFold(h, name) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// set sets the field to the value.
+func set(field reflect.Value, value interface{}) error {
+	s, ok := value.(string)
+	if !ok {
+		v := reflect.ValueOf(value)
+		if !v.IsValid() || !v.Type().AssignableTo(field.Type()) {
+			return fmt.Errorf("cannot assign %T to %s", value, field.Type())
+		}
+		field.Set(v)
+		return nil
+	}
+	if s == "" {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	if field.Kind() == reflect.Ptr {
+		p := reflect.New(field.Type().Elem())
+		if err := set(p.Elem(), s); err != nil {
+			return err
+		}
+		field.Set(p)
+		return nil
+	}
+	if reflect.PtrTo(field.Type()).Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		field.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(s, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(v)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
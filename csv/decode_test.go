@@ -0,0 +1,114 @@
+package csv
+
+import (
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/parse"
+)
+
+type person struct {
+	Name     string    `csv:"name"`
+	Age      int       `csv:"age"`
+	Score    *float64  `csv:"score"`
+	Admin    bool      `csv:"admin"`
+	Joined   time.Time `csv:"joined"`
+	Ignored  string    `csv:"-"`
+	Email    string
+	internal string
+}
+
+func TestDecode(t *testing.T) {
+	doc := "email,name,age,score,admin,joined\n" +
+		"a@example.com,Alice,30,1.5,true,2020-01-02T03:04:05Z\n" +
+		"b@example.com,\"Bob, Jr\",,,false,0001-01-01T00:00:00Z\n"
+	r := NewReader(input.NewFromString(doc), CSV)
+	var actual []person
+	for {
+		var p person
+		err := r.Decode(&p)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		actual = append(actual, p)
+	}
+	score := 1.5
+	expected := []person{
+		{Name: "Alice", Age: 30, Score: &score, Admin: true, Joined: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), Email: "a@example.com"},
+		{Name: "Bob, Jr", Email: "b@example.com"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+	if h := r.Header(); !reflect.DeepEqual(h, []string{"email", "name", "age", "score", "admin", "joined"}) {
+		t.Errorf("unexpected header: %v", h)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		v        interface{}
+		field    parse.Function
+		expected string
+	}{
+		{
+			input: "name,age\nAlice,thirty\n",
+			v: &struct {
+				Age int `csv:"age"`
+			}{},
+			expected: `line 2, column 6: column "age": strconv.ParseInt: parsing "thirty": invalid syntax`,
+		},
+		{
+			input: "name\nAlice\n",
+			v: &struct {
+				Age int `csv:"age"`
+			}{},
+			expected: `csv: column "age" of field Age not found in header`,
+		},
+		{
+			input:    "name\nAlice\n",
+			v:        struct{}{},
+			expected: "csv: Decode requires a non-nil pointer to a struct",
+		},
+		{
+			input: "name\n1\n",
+			v: &struct {
+				Name string `csv:"name"`
+			}{},
+			field:    parse.Or(parse.Int(10, 64), Field(CSV)),
+			expected: `line 2, column 0: column "name": cannot assign int64 to string`,
+		},
+	}
+
+	for i, test := range tests {
+		opts := CSV
+		opts.Field = test.field
+		err := NewReader(input.NewFromString(test.input), opts).Decode(test.v)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("test %v: for input '%v' expected error %q, got %v", i, test.input, test.expected, err)
+		}
+	}
+}
+
+func TestDecodeParsedValues(t *testing.T) {
+	opts := CSV
+	opts.Field = parse.Or(parse.Int(10, 64), Field(CSV))
+	r := NewReader(input.NewFromString("id,name\n42,x\n"), opts)
+	var v struct {
+		ID   int64  `csv:"id"`
+		Name string `csv:"name"`
+	}
+	if err := r.Decode(&v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.ID != 42 || v.Name != "x" {
+		t.Errorf("unexpected value: %+v", v)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build go1.23
+
+package csv
+
+import (
+	"io"
+	"iter"
+)
+
+// All returns an iterator over the remaining records. Iteration stops at the end of the
+// input, or after the first error is yielded.
+//
+// All is only built with Go 1.23 or later, because it uses the iter package. The rest of the
+// package supports the Go version of the module, so with earlier versions, use Read instead.
+func (r *Reader) All() iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		for {
+			rec, err := r.Read()
+			if err == io.EOF {
+				return
+			}
+			if !yield(rec, err) || err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,38 @@
+//go:build go1.23
+
+package csv
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+)
+
+func TestAll(t *testing.T) {
+	var actual [][]string
+	for rec, err := range NewReader(input.NewFromString("a,b\nc,d\n"), CSV).All() {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		actual = append(actual, rec.Strings())
+	}
+	expected := [][]string{{"a", "b"}, {"c", "d"}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestAllStopsAtError(t *testing.T) {
+	var records, errors int
+	for _, err := range NewReader(input.NewFromString("a,b\n\"c\n"), CSV).All() {
+		if err != nil {
+			errors++
+			continue
+		}
+		records++
+	}
+	if records != 1 || errors != 1 {
+		t.Errorf("expected 1 record and 1 error, got %d and %d", records, errors)
+	}
+}
@@ -0,0 +1,486 @@
+// Package edn is a reader for S-expressions and EDN (extensible data notation,
+// https://github.com/edn-format/edn) built from the parse package's combinators.
+//
+// Forms are read into a tree of Nodes, which records the range of the input that each form was
+// parsed from. Commas are whitespace, comments start with a semicolon, and the form after #_
+// is discarded.
+//
+// The reader macros 'a, `a, ~a, ~@a and @a are read as the lists (quote a), (syntax-quote a),
+// (unquote a), (unquote-splicing a) and (deref a), where the range of the symbol is the range
+// of the macro character, so that the tree only contains EDN forms.
+package edn
+
+import (
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// Kind is the type of a form.
+type Kind int
+
+// The kinds of form.
+const (
+	Nil Kind = iota
+	Bool
+	Integer
+	Float
+	Character
+	String
+	Symbol
+	Keyword
+	List
+	Vector
+	Map
+	Set
+	Tagged
+)
+
+var kindNames = []string{"nil", "bool", "integer", "float", "character", "string", "symbol", "keyword", "list", "vector", "map", "set", "tagged element"}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "Kind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return kindNames[k]
+}
+
+// Node is a form, and the range of the input it was parsed from.
+type Node struct {
+	Kind  Kind
+	Range parse.Range
+	// Value is the value of a Bool (bool), Integer (int64, or *big.Int if it has the N suffix
+	// or doesn't fit in an int64), Float (float64, or *big.Rat if it has the M suffix),
+	// Character (rune), String (string), Symbol (string, e.g. "ns/name") or Keyword (string,
+	// without the colon). The Value of a Tagged element is its tag, without the #.
+	Value interface{}
+	// Elements are the forms within a List, Vector or Set, the keys and values of a Map, which
+	// alternate, and the single form of a Tagged element.
+	Elements []*Node
+}
+
+// Read reads a single form from the input, which may be surrounded by whitespace and
+// comments. If the input is invalid, the error is a *parse.Error which contains the position
+// of the problem.
+func Read(pi parse.Input) (*Node, error) {
+	if err := ignored(pi); err != nil {
+		return nil, err
+	}
+	pos := parse.PosOf(pi)
+	r := Form(pi)
+	if !r.Success {
+		return nil, failed(pi, pos, r)
+	}
+	pos = parse.PosOf(pi)
+	if !parse.EOF(pi).Success {
+		return nil, parse.Unexpected(pi, pos)
+	}
+	return r.Item.(*Node), nil
+}
+
+// ReadAll reads every form from the input, e.g. the expressions of a source file.
+func ReadAll(pi parse.Input) (forms []*Node, err error) {
+	if err = ignored(pi); err != nil {
+		return nil, err
+	}
+	for !parse.EOF(pi).Success {
+		pos := parse.PosOf(pi)
+		r := Form(pi)
+		if !r.Success {
+			return nil, failed(pi, pos, r)
+		}
+		forms = append(forms, r.Item.(*Node))
+	}
+	return forms, nil
+}
+
+// failed returns the error of a form which couldn't be read.
+func failed(pi parse.Input, pos parse.Pos, r parse.Result) error {
+	if r.Error != nil && r.Error != io.EOF {
+		return r.Error
+	}
+	return parse.Unexpected(pi, pos)
+}
+
+// Form captures a form, and any whitespace, comments and discarded forms which follow it, and
+// returns a *Node. It can be used to parse forms embedded within another grammar.
+var Form parse.Function = form
+
+// anyForm is set by init to break the initialization cycle between forms and collections.
+var anyForm parse.Function
+
+func init() {
+	anyForm = parse.Any(list, vector, set, mapForm, stringForm, character, macro, tagged, number, token)
+}
+
+func form(pi parse.Input) parse.Result {
+	r := anyForm(pi)
+	if !r.Success {
+		return r
+	}
+	if err := ignored(pi); err != nil {
+		return parse.Failure("form", err)
+	}
+	return r
+}
+
+var whitespace = parse.Span(parse.ClassOf(" \t\r\n\f,"), 1, 0)
+var comment = parse.Then(parse.WithStringConcatCombiner, parse.Rune(';'), parse.Span(parse.ClassOf("\r\n").Not(), 0, 0))
+
+// ignored captures whitespace, commas, comments and discarded forms.
+func ignored(pi parse.Input) error {
+	for {
+		if parse.Accept(pi, whitespace).Success || parse.Accept(pi, comment).Success {
+			continue
+		}
+		if !parse.Accept(pi, parse.String("#_")).Success {
+			return nil
+		}
+		if _, err := following(pi, "#_"); err != nil {
+			return err
+		}
+	}
+}
+
+// following captures the form which follows a prefix, e.g. the quoted form of 'a.
+func following(pi parse.Input, prefix string) (*Node, error) {
+	if err := ignored(pi); err != nil {
+		return nil, err
+	}
+	pos := parse.PosOf(pi)
+	r := form(pi)
+	if r.Success {
+		return r.Item.(*Node), nil
+	}
+	if r.Error != nil && r.Error != io.EOF {
+		return nil, r.Error
+	}
+	return nil, parse.Errorf(pos, "expected a form after %s", prefix)
+}
+
+var list = collection(List, "(", ')')
+var vector = collection(Vector, "[", ']')
+var set = collection(Set, "#{", '}')
+var mapForm = collection(Map, "{", '}')
+
+// collection captures the forms between the opening and closing brackets.
+func collection(kind Kind, open string, close rune) parse.Function {
+	return func(pi parse.Input) parse.Result {
+		name := kind.String()
+		start := parse.PosOf(pi)
+		if !parse.Accept(pi, parse.String(open)).Success {
+			return parse.Failure(name, nil)
+		}
+		if err := ignored(pi); err != nil {
+			return parse.Failure(name, err)
+		}
+		n := &Node{Kind: kind}
+		for {
+			pos := parse.PosOf(pi)
+			if parse.Accept(pi, parse.Rune(close)).Success {
+				break
+			}
+			r := form(pi)
+			if r.Success {
+				n.Elements = append(n.Elements, r.Item.(*Node))
+				continue
+			}
+			if r.Error != nil && r.Error != io.EOF {
+				return r
+			}
+			if parse.EOF(pi).Success {
+				return parse.Failure(name, parse.Errorf(start, "unterminated %s", name))
+			}
+			return parse.Failure(name, parse.Unexpected(pi, pos))
+		}
+		n.Range = parse.Range{Start: start, End: parse.PosOf(pi)}
+		if err := unique(n); err != nil {
+			return parse.Failure(name, err)
+		}
+		return parse.Success(name, n, nil)
+	}
+}
+
+// unique checks that a map has a value for each key, and that the keys of a map, and the
+// elements of a set, aren't repeated.
+func unique(n *Node) error {
+	step, what := 1, "element"
+	switch n.Kind {
+	case Map:
+		if len(n.Elements)%2 != 0 {
+			return parse.Errorf(n.Range.Start, "map has a key without a value")
+		}
+		step, what = 2, "key"
+	case Set:
+	default:
+		return nil
+	}
+	seen := make(map[string]bool, len(n.Elements)/step)
+	for i := 0; i < len(n.Elements); i += step {
+		e := n.Elements[i]
+		s := e.String()
+		if seen[s] {
+			return parse.Errorf(e.Range.Start, "duplicate %s %s in %s", what, s, n.Kind)
+		}
+		seen[s] = true
+	}
+	return nil
+}
+
+var stringLiteral = parse.StringLiteral(parse.StringLiteralOptions{
+	Quotes:    `"`,
+	Escape:    '\\',
+	Escapes:   `"\bfnrtu`,
+	MultiLine: true,
+})
+
+var stringForm = atom(String, stringLiteral)
+
+// atom creates a Node of the kind from the item of f.
+func atom(kind Kind, f parse.Function) parse.Function {
+	return parse.All(func(items []interface{}) (interface{}, bool) {
+		ranged := items[0].(parse.Ranged)
+		return &Node{Kind: kind, Range: ranged.Range, Value: ranged.Item}, true
+	}, parse.WithRange(f))
+}
+
+// delimiters end a symbol, keyword, number or character.
+var delimiters = parse.ClassOf(" \t\r\n\f,\";@^`~()[]{}\\")
+var tokenText = parse.Span(delimiters.Not(), 1, 0)
+
+var characterNames = map[string]rune{
+	"newline":   '\n',
+	"return":    '\r',
+	"space":     ' ',
+	"tab":       '\t',
+	"backspace": '\b',
+	"formfeed":  '\f',
+}
+
+// character captures a character, e.g. \a, \newline or é.
+func character(pi parse.Input) parse.Result {
+	name := "character"
+	start := parse.PosOf(pi)
+	if !parse.Accept(pi, parse.Rune('\\')).Success {
+		return parse.Failure(name, nil)
+	}
+	r := parse.Accept(pi, parse.AnyRune())
+	if !r.Success || unicode.IsSpace(r.Item.(rune)) {
+		return parse.Failure(name, parse.Errorf(start, "expected a character after \\"))
+	}
+	// The first rune can be a delimiter, e.g. \(, but the rest of the name can't.
+	text := string(r.Item.(rune))
+	if t := parse.Accept(pi, tokenText); t.Success {
+		text += t.Item.(string)
+	}
+	c, ok := characterValue(text)
+	if !ok {
+		return parse.Failure(name, parse.Errorf(start, "invalid character \\%s", text))
+	}
+	return parse.Success(name, &Node{Kind: Character, Range: parse.Range{Start: start, End: parse.PosOf(pi)}, Value: c}, nil)
+}
+
+func characterValue(text string) (c rune, ok bool) {
+	if utf8.RuneCountInString(text) == 1 {
+		c, _ = utf8.DecodeRuneInString(text)
+		return c, true
+	}
+	if c, ok = characterNames[text]; ok {
+		return c, true
+	}
+	if len(text) == 5 && text[0] == 'u' {
+		v, err := strconv.ParseUint(text[1:], 16, 32)
+		return rune(v), err == nil && utf8.ValidRune(rune(v))
+	}
+	return 0, false
+}
+
+// macros are the reader macros which stand for a list of a symbol and the next form.
+var macros = []struct {
+	prefix string
+	symbol string
+}{
+	{prefix: "'", symbol: "quote"},
+	{prefix: "`", symbol: "syntax-quote"},
+	{prefix: "~@", symbol: "unquote-splicing"},
+	{prefix: "~", symbol: "unquote"},
+	{prefix: "@", symbol: "deref"},
+}
+
+func macro(pi parse.Input) parse.Result {
+	name := "reader macro"
+	start := parse.PosOf(pi)
+	for _, m := range macros {
+		if !parse.Accept(pi, parse.String(m.prefix)).Success {
+			continue
+		}
+		symbol := &Node{Kind: Symbol, Range: parse.Range{Start: start, End: parse.PosOf(pi)}, Value: m.symbol}
+		n, err := following(pi, m.prefix)
+		if err != nil {
+			return parse.Failure(name, err)
+		}
+		return parse.Success(name, &Node{
+			Kind:     List,
+			Range:    parse.Range{Start: start, End: n.Range.End},
+			Elements: []*Node{symbol, n},
+		}, nil)
+	}
+	return parse.Failure(name, nil)
+}
+
+// tagged captures a tagged element, e.g. #inst "1985-04-12T23:20:50.52Z".
+func tagged(pi parse.Input) parse.Result {
+	name := "tagged element"
+	start := parse.PosOf(pi)
+	if !parse.Accept(pi, parse.Rune('#')).Success {
+		return parse.Failure(name, nil)
+	}
+	t := parse.Accept(pi, tokenText)
+	if !t.Success {
+		return parse.Failure(name, parse.Unexpected(pi, parse.PosOf(pi)))
+	}
+	tag := t.Item.(string)
+	if r, _ := utf8.DecodeRuneInString(tag); !unicode.IsLetter(r) || !validSymbol(tag) {
+		return parse.Failure(name, parse.Errorf(start, "invalid tag #%s", tag))
+	}
+	n, err := following(pi, "#"+tag)
+	if err != nil {
+		return parse.Failure(name, err)
+	}
+	return parse.Success(name, &Node{
+		Kind:     Tagged,
+		Range:    parse.Range{Start: start, End: n.Range.End},
+		Value:    tag,
+		Elements: []*Node{n},
+	}, nil)
+}
+
+// token captures nil, true, false, a keyword or a symbol.
+func token(pi parse.Input) parse.Result {
+	name := "token"
+	start := parse.PosOf(pi)
+	t := parse.Accept(pi, tokenText)
+	if !t.Success {
+		return parse.Failure(name, nil)
+	}
+	n, err := tokenValue(t.Item.(string), start)
+	if err != nil {
+		return parse.Failure(name, err)
+	}
+	n.Range = parse.Range{Start: start, End: parse.PosOf(pi)}
+	return parse.Success(name, n, nil)
+}
+
+func tokenValue(text string, pos parse.Pos) (*Node, error) {
+	switch {
+	case text == "nil":
+		return &Node{Kind: Nil}, nil
+	case text == "true" || text == "false":
+		return &Node{Kind: Bool, Value: text == "true"}, nil
+	case text[0] == ':':
+		if !validSymbol(text[1:]) {
+			return nil, parse.Errorf(pos, "invalid keyword %s", text)
+		}
+		return &Node{Kind: Keyword, Value: text[1:]}, nil
+	}
+	if !validSymbol(text) {
+		return nil, parse.Errorf(pos, "invalid symbol %s", text)
+	}
+	return &Node{Kind: Symbol, Value: text}, nil
+}
+
+func isDigit(s string, i int) bool {
+	return i < len(s) && s[i] >= '0' && s[i] <= '9'
+}
+
+// numberSyntax matches an integer, e.g. 42 or 42N, or a float, e.g. 1.5, 1e3 or 1.5M. The
+// subexpressions are the fraction, the exponent and the suffix.
+var numberSyntax = parse.RegexpSubmatch(`[+-]?(?:0|[1-9][0-9]*)(\.[0-9]*)?([eE][+-]?[0-9]+)?([NM])?`)
+
+var delimiter = parse.Or(parse.EOF, parse.RuneInClass(delimiters))
+
+// number captures an integer or a float, which must be followed by a delimiter. Integers which
+// don't fit into an int64, and integers with the N suffix, are read as a *big.Int, and floats
+// with the M suffix are read as a *big.Rat.
+func number(pi parse.Input) parse.Result {
+	name := "number"
+	start := parse.PosOf(pi)
+	r := parse.Accept(pi, numberSyntax)
+	if !r.Success {
+		return parse.Failure(name, nil)
+	}
+	end := pi.Index()
+	m := r.Item.([]string)
+	text, fraction, exponent, suffix := m[0], m[1], m[2], m[3]
+	valid := delimiter(pi).Success && !(suffix == "N" && (fraction != "" || exponent != ""))
+	parse.RewindTo(pi, end)
+	if !valid {
+		// Report the whole token, e.g. 2x or 1.5N.
+		parse.RewindTo(pi, start.Index)
+		return parse.Failure(name, parse.Errorf(start, "invalid number %s", tokenText(pi).Item))
+	}
+	n := &Node{Kind: Float, Range: parse.Range{Start: start, End: parse.PosOf(pi)}}
+	switch {
+	case fraction == "" && exponent == "" && suffix != "M":
+		n.Kind = Integer
+		if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+			n.Value = i
+			break
+		}
+		n.Value, _ = new(big.Int).SetString(strings.TrimSuffix(text, "N"), 10)
+	case suffix == "M":
+		n.Value, _ = new(big.Rat).SetString(strings.TrimSuffix(text, "M"))
+	default:
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			parse.RewindTo(pi, start.Index)
+			return parse.Failure(name, parse.Errorf(start, "float %s out of range", text))
+		}
+		n.Value = f
+	}
+	return parse.Success(name, n, nil)
+}
+
+// validSymbol returns true if the text is a symbol, e.g. a, my.ns/name, + or /.
+func validSymbol(text string) bool {
+	if text == "/" {
+		return true
+	}
+	parts := strings.Split(text, "/")
+	if len(parts) > 2 {
+		return false
+	}
+	for _, part := range parts {
+		if !validName(part) {
+			return false
+		}
+	}
+	return true
+}
+
+var symbolRunes = parse.ClassOf(".*+!-_?$%&=<>")
+
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r) || symbolRunes.Contains(r):
+		case unicode.IsDigit(r) || r == '#' || r == ':' || r == '\'':
+			if i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	// A name which starts with -, + or . can't be followed by a digit, because it would be read
+	// as a number.
+	return !(strings.ContainsRune("-+.", rune(name[0])) && isDigit(name, 1))
+}
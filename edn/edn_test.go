@@ -0,0 +1,262 @@
+package edn
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/parse"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// Literals.
+		{input: "nil", expected: "nil"},
+		{input: "true", expected: "true"},
+		{input: " false ", expected: "false"},
+		// Numbers.
+		{input: "0", expected: "0"},
+		{input: "-42", expected: "-42"},
+		{input: "+7", expected: "7"},
+		{input: "9223372036854775808", expected: "9223372036854775808N"},
+		{input: "12N", expected: "12N"},
+		{input: "1.5", expected: "1.5"},
+		{input: "1.", expected: "1.0"},
+		{input: "-2e3", expected: "-2000.0"},
+		{input: "1.25E-2", expected: "0.0125"},
+		{input: "1.10M", expected: "1.1M"},
+		{input: "3M", expected: "3M"},
+		// Strings and characters.
+		{input: `""`, expected: `""`},
+		{input: `"a\"b\\c\né"`, expected: `"a\"b\\c\né"`},
+		{input: "\"two\nlines\"", expected: `"two\nlines"`},
+		{input: `\a`, expected: `\a`},
+		{input: `\newline`, expected: `\newline`},
+		{input: `\é`, expected: `\é`},
+		{input: `[\( \)]`, expected: `[\( \)]`},
+		// Symbols and keywords.
+		{input: "a", expected: "a"},
+		{input: "my.ns/name", expected: "my.ns/name"},
+		{input: "/", expected: "/"},
+		{input: "+", expected: "+"},
+		{input: "-a", expected: "-a"},
+		{input: "a'b#", expected: "a'b#"},
+		{input: "<=", expected: "<="},
+		{input: ":a", expected: ":a"},
+		{input: ":ns/a?", expected: ":ns/a?"},
+		// Collections.
+		{input: "()", expected: "()"},
+		{input: "(+ 1 (* 2 3))", expected: "(+ 1 (* 2 3))"},
+		{input: "[1, 2,, 3]", expected: "[1 2 3]"},
+		{input: "{:a 1, :b [2 3]}", expected: "{:a 1 :b [2 3]}"},
+		{input: "#{1 2}", expected: "#{1 2}"},
+		{input: "{[1 2] #{}}", expected: "{[1 2] #{}}"},
+		// Comments and discarded forms.
+		{input: "; comment\n(a ; comment\n b)", expected: "(a b)"},
+		{input: "[1 #_2 3]", expected: "[1 3]"},
+		{input: "[1 #_ #_ 2 3 4]", expected: "[1 4]"},
+		{input: "#_(ignored) kept #_ignored", expected: "kept"},
+		// Reader macros and tags.
+		{input: "'a", expected: "(quote a)"},
+		{input: "'(1 2)", expected: "(quote (1 2))"},
+		{input: "`(a ~b ~@c)", expected: "(syntax-quote (a (unquote b) (unquote-splicing c)))"},
+		{input: "@state", expected: "(deref state)"},
+		{input: "' a", expected: "(quote a)"},
+		{input: `#inst "1985-04-12T23:20:50.52Z"`, expected: `#inst "1985-04-12T23:20:50.52Z"`},
+		{input: "#myapp/Person {:first \"Fred\"}", expected: "#myapp/Person {:first \"Fred\"}"},
+	}
+	for i, test := range tests {
+		n, err := Read(input.NewFromString(test.input))
+		if err != nil {
+			t.Errorf("test %v: for input '%v' unexpected error: %v", i, test.input, err)
+			continue
+		}
+		if actual := n.String(); actual != test.expected {
+			t.Errorf("test %v: for input '%v' expected %v, got %v", i, test.input, test.expected, actual)
+		}
+		// The printed form can be read again.
+		again, err := Read(input.NewFromString(n.String()))
+		if err != nil || again.String() != n.String() {
+			t.Errorf("test %v: for input '%v' failed to read %v again: %v", i, test.input, n.String(), err)
+		}
+	}
+}
+
+func TestValues(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedKind  Kind
+		expectedValue interface{}
+	}{
+		{input: "nil", expectedKind: Nil, expectedValue: nil},
+		{input: "true", expectedKind: Bool, expectedValue: true},
+		{input: "-1", expectedKind: Integer, expectedValue: int64(-1)},
+		{input: "1N", expectedKind: Integer, expectedValue: big.NewInt(1)},
+		{input: "0.5", expectedKind: Float, expectedValue: 0.5},
+		{input: "0.5M", expectedKind: Float, expectedValue: big.NewRat(1, 2)},
+		{input: "+9223372036854775808", expectedKind: Integer, expectedValue: new(big.Int).Lsh(big.NewInt(1), 63)},
+		{input: "1.", expectedKind: Float, expectedValue: 1.0},
+		{input: "-2.5e-1", expectedKind: Float, expectedValue: -0.25},
+		{input: `\space`, expectedKind: Character, expectedValue: ' '},
+		{input: `"s"`, expectedKind: String, expectedValue: "s"},
+		{input: "ns/s", expectedKind: Symbol, expectedValue: "ns/s"},
+		{input: ":k", expectedKind: Keyword, expectedValue: "k"},
+		{input: "#tag 1", expectedKind: Tagged, expectedValue: "tag"},
+	}
+	for i, test := range tests {
+		n, err := Read(input.NewFromString(test.input))
+		if err != nil {
+			t.Errorf("test %v: for input '%v' unexpected error: %v", i, test.input, err)
+			continue
+		}
+		if n.Kind != test.expectedKind {
+			t.Errorf("test %v: for input '%v' expected kind %v, got %v", i, test.input, test.expectedKind, n.Kind)
+		}
+		if !reflect.DeepEqual(n.Value, test.expectedValue) {
+			t.Errorf("test %v: for input '%v' expected value %#v, got %#v", i, test.input, test.expectedValue, n.Value)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "", expected: "line 1, column 0: unexpected end of input"},
+		{input: "(a b", expected: "line 1, column 0: unterminated list"},
+		{input: "[1\n {:a 1", expected: "line 2, column 1: unterminated map"},
+		{input: "(a]", expected: "line 1, column 2: unexpected ']'"},
+		{input: "a)", expected: "line 1, column 1: unexpected ')'"},
+		{input: "{:a 1 :b}", expected: "line 1, column 0: map has a key without a value"},
+		{input: "{:a 1 :a 2}", expected: "line 1, column 6: duplicate key :a in map"},
+		{input: "#{1 2 1}", expected: "line 1, column 6: duplicate element 1 in set"},
+		{input: "(1 2x)", expected: "line 1, column 3: invalid number 2x"},
+		{input: "01", expected: "line 1, column 0: invalid number 01"},
+		{input: "1e999", expected: "line 1, column 0: float 1e999 out of range"},
+		{input: "[1\n 1e999]", expected: "line 2, column 1: float 1e999 out of range"},
+		{input: "(1.5N)", expected: "line 1, column 1: invalid number 1.5N"},
+		{input: "1/2", expected: "line 1, column 0: invalid number 1/2"},
+		{input: "[a/b/c]", expected: "line 1, column 1: invalid symbol a/b/c"},
+		{input: "::a", expected: "line 1, column 0: invalid keyword ::a"},
+		{input: `\abc`, expected: `line 1, column 0: invalid character \abc`},
+		{input: `"\q"`, expected: `line 1, column 1: invalid escape sequence "\\q"`},
+		{input: `"abc`, expected: "line 1, column 0: unterminated string literal"},
+		{input: "(quote ')", expected: "line 1, column 8: expected a form after '"},
+		{input: "[1 #_]", expected: "line 1, column 5: expected a form after #_"},
+		{input: "#1 2", expected: "line 1, column 0: invalid tag #1"},
+		{input: "#tag", expected: "line 1, column 4: expected a form after #tag"},
+		{input: "a b", expected: "line 1, column 2: unexpected 'b'"},
+	}
+	for i, test := range tests {
+		_, err := Read(input.NewFromString(test.input))
+		if err == nil {
+			t.Errorf("test %v: for input '%v' expected error %q, got nil", i, test.input, test.expected)
+			continue
+		}
+		if _, ok := err.(*parse.Error); !ok {
+			t.Errorf("test %v: for input '%v' expected *parse.Error, got %T", i, test.input, err)
+		}
+		if err.Error() != test.expected {
+			t.Errorf("test %v: for input '%v' expected error %q, got %q", i, test.input, test.expected, err.Error())
+		}
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	src := `; Rules.
+(defrule adult [person] (>= (:age person) 18))
+#_(defrule disabled [] false)
+(defrule named [person] (some? (:name person)))
+`
+	forms, err := ReadAll(input.NewFromString(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var actual []string
+	for _, f := range forms {
+		actual = append(actual, f.String())
+	}
+	expected := []string{
+		"(defrule adult [person] (>= (:age person) 18))",
+		"(defrule named [person] (some? (:name person)))",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if forms, err = ReadAll(input.NewFromString(" ; nothing\n")); err != nil || len(forms) != 0 {
+		t.Errorf("expected no forms, got %v, %v", forms, err)
+	}
+}
+
+func TestRanges(t *testing.T) {
+	src := "(when\n  'ready? [1 \"two\"])"
+	n, err := Read(input.NewFromString(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	quoted := n.Elements[1]
+	vector := n.Elements[2]
+	tests := []struct {
+		name     string
+		r        parse.Range
+		expected string
+	}{
+		{name: "list", r: n.Range, expected: src},
+		{name: "symbol", r: n.Elements[0].Range, expected: "when"},
+		{name: "quoted", r: quoted.Range, expected: "'ready?"},
+		{name: "quote", r: quoted.Elements[0].Range, expected: "'"},
+		{name: "vector", r: vector.Range, expected: `[1 "two"]`},
+		{name: "string", r: vector.Elements[1].Range, expected: `"two"`},
+	}
+	for _, test := range tests {
+		if actual := src[test.r.Start.Index:test.r.End.Index]; actual != test.expected {
+			t.Errorf("%s: expected range to contain %q, got %q", test.name, test.expected, actual)
+		}
+	}
+	if s := vector.Range.Start; s.Line != 2 || s.Column != 10 {
+		t.Errorf("expected the vector to start at line 2, column 10, got %v", s)
+	}
+}
+
+func TestFormWithinGrammar(t *testing.T) {
+	// A rule is a name, followed by a colon and an expression.
+	rule := parse.All(func(items []interface{}) (interface{}, bool) {
+		return items[2], true
+	}, parse.String("rule"), parse.String(": "), Form, parse.EOF)
+	r := rule(input.NewFromString("rule: (= x 1) ; comment"))
+	if !r.Success {
+		t.Fatalf("expected success, got %v", r.Error)
+	}
+	if s := r.Item.(*Node).String(); s != "(= x 1)" {
+		t.Errorf("expected (= x 1), got %v", s)
+	}
+}
+
+func TestKindString(t *testing.T) {
+	if s := Tagged.String(); s != "tagged element" {
+		t.Errorf("expected 'tagged element', got %q", s)
+	}
+	if s := Kind(100).String(); s != "Kind(100)" {
+		t.Errorf("expected 'Kind(100)', got %q", s)
+	}
+}
+
+const benchmarkDocument = `{:rules [(defrule adult [person] (>= (:age person) 18))
+         (defrule named [person] (some? (:name person)))]
+ :version 2
+ :tags #{:a :b :c}
+ :created #inst "2020-01-01T00:00:00Z"}`
+
+func BenchmarkRead(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		if _, err := Read(input.NewFromString(benchmarkDocument)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
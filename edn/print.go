@@ -0,0 +1,139 @@
+package edn
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// String returns the form as EDN. Whitespace and comments aren't kept, so the result is the
+// same for equal forms, whatever their layout.
+func (n *Node) String() string {
+	var sb strings.Builder
+	n.write(&sb)
+	return sb.String()
+}
+
+func (n *Node) write(sb *strings.Builder) {
+	switch n.Kind {
+	case Nil:
+		sb.WriteString("nil")
+	case Bool:
+		sb.WriteString(strconv.FormatBool(n.Value.(bool)))
+	case Integer:
+		switch v := n.Value.(type) {
+		case int64:
+			sb.WriteString(strconv.FormatInt(v, 10))
+		case *big.Int:
+			sb.WriteString(v.String() + "N")
+		}
+	case Float:
+		switch v := n.Value.(type) {
+		case float64:
+			sb.WriteString(formatFloat(v))
+		case *big.Rat:
+			sb.WriteString(formatDecimal(v) + "M")
+		}
+	case Character:
+		writeCharacter(sb, n.Value.(rune))
+	case String:
+		writeString(sb, n.Value.(string))
+	case Symbol:
+		sb.WriteString(n.Value.(string))
+	case Keyword:
+		sb.WriteString(":" + n.Value.(string))
+	case List:
+		writeElements(sb, "(", n.Elements, ")")
+	case Vector:
+		writeElements(sb, "[", n.Elements, "]")
+	case Map:
+		writeElements(sb, "{", n.Elements, "}")
+	case Set:
+		writeElements(sb, "#{", n.Elements, "}")
+	case Tagged:
+		sb.WriteString("#" + n.Value.(string) + " ")
+		n.Elements[0].write(sb)
+	}
+}
+
+func writeElements(sb *strings.Builder, open string, elements []*Node, close string) {
+	sb.WriteString(open)
+	for i, e := range elements {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		e.write(sb)
+	}
+	sb.WriteString(close)
+}
+
+// formatFloat formats the float so that it's read as a float, e.g. 1.0 rather than 1.
+func formatFloat(f float64) string {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		// EDN has no syntax for infinity or NaN, but Clojure reads these symbolic values.
+		switch {
+		case math.IsNaN(f):
+			return "##NaN"
+		case f > 0:
+			return "##Inf"
+		}
+		return "##-Inf"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// formatDecimal formats the exact decimal value of r, which was read from a decimal number.
+func formatDecimal(r *big.Rat) string {
+	digits := 0
+	for x := new(big.Rat).Set(r); !x.IsInt(); digits++ {
+		x.Mul(x, big.NewRat(10, 1))
+	}
+	return r.FloatString(digits)
+}
+
+func writeCharacter(sb *strings.Builder, c rune) {
+	for name, r := range characterNames {
+		if r == c {
+			sb.WriteString(`\` + name)
+			return
+		}
+	}
+	if unicode.IsPrint(c) {
+		sb.WriteString(`\` + string(c))
+		return
+	}
+	fmt.Fprintf(sb, `\u%04x`, c)
+}
+
+var stringEscapes = map[rune]string{
+	'"':  `\"`,
+	'\\': `\\`,
+	'\n': `\n`,
+	'\r': `\r`,
+	'\t': `\t`,
+	'\b': `\b`,
+	'\f': `\f`,
+}
+
+func writeString(sb *strings.Builder, s string) {
+	sb.WriteByte('"')
+	for _, r := range s {
+		if e, ok := stringEscapes[r]; ok {
+			sb.WriteString(e)
+			continue
+		}
+		if r < 0x20 || r == 0x7f {
+			fmt.Fprintf(sb, `\u%04x`, r)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteByte('"')
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/iso8601"
 	"github.com/a-h/lexical/parse"
 )
 
@@ -60,3 +61,20 @@ func dateConverter(items []interface{}) (item interface{}, value bool) {
 	}
 	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC), true
 }
+
+func TestISO8601Date(t *testing.T) {
+	// The iso8601 package contains complete date, time, duration and interval parsers.
+	result := iso8601.Date(input.NewFromString("2001-02-03"))
+	if !result.Success {
+		t.Fatalf("expected success, got %v", result)
+	}
+	if expected := time.Date(2001, 02, 03, 0, 0, 0, 0, time.UTC); !result.Item.(time.Time).Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, result.Item)
+	}
+
+	// Invalid dates result in an error which contains the position of the problem.
+	result = iso8601.Date(input.NewFromString("2001-02-30"))
+	if result.Error == nil || result.Error.Error() != "line 1, column 8: day 30 out of range for February 2001" {
+		t.Errorf("expected a day out of range error, got %v", result.Error)
+	}
+}
@@ -5,102 +5,65 @@ import (
 
 	"github.com/a-h/lexical/input"
 	"github.com/a-h/lexical/parse"
+	"github.com/a-h/lexical/uri"
 )
 
 func TestURLParsing(t *testing.T) {
 	tests := []struct {
 		input         string
 		expectedMatch bool
-		expectedValue URL
+		expectedHost  string
+		expectedPath  string
 	}{
 		{
-			input:         "example.com",
+			input:         "GET http://example.com HTTP/1.1",
 			expectedMatch: true,
-			expectedValue: URL{
-				Host: "example.com",
-			},
+			expectedHost:  "example.com",
 		},
 		{
-			input:         "example.co.uk/",
+			input:         "GET https://example.co.uk/ HTTP/1.1",
 			expectedMatch: true,
-			expectedValue: URL{
-				Host: "example.co.uk",
-				Path: "/",
-			},
+			expectedHost:  "example.co.uk",
+			expectedPath:  "/",
 		},
 		{
-			input:         "example.com:80/path",
+			input:         "GET //example.com:80/path%20name HTTP/1.1",
 			expectedMatch: true,
-			expectedValue: URL{
-				Host: "example.com:80",
-				Path: "/path",
-			},
+			expectedHost:  "example.com:80",
+			expectedPath:  "/path name",
+		},
+		{
+			input:         "GET http://example.com/a b HTTP/1.1",
+			expectedMatch: false,
 		},
 	}
 
 	for _, test := range tests {
 		ip := input.NewFromString(test.input)
-		result := url(ip)
+		result := requestLine(ip)
 		if result.Success != test.expectedMatch {
 			t.Errorf("for input '%v', expected success '%v', but was %v", test.input, test.expectedMatch, result.Success)
 		}
-		if result.Item != test.expectedValue {
-			t.Errorf("for input '%v', expected sucess '%v', but was %v", test.input, test.expectedValue, result.Item)
+		if !result.Success {
+			continue
+		}
+		// The URI can be converted to a *url.URL.
+		u := result.Item.(*uri.URI).URL()
+		if u.Host != test.expectedHost {
+			t.Errorf("for input '%v', expected host '%v', but was %v", test.input, test.expectedHost, u.Host)
+		}
+		if u.Path != test.expectedPath {
+			t.Errorf("for input '%v', expected path '%v', but was %v", test.input, test.expectedPath, u.Path)
 		}
 	}
 }
 
-type URL struct {
-	Host string
-	Path string
-}
-
-var head = parse.Any(parse.Letter, parse.ZeroToNine)
-var tailCharacter = parse.Any(parse.Letter, parse.ZeroToNine, parse.Rune('-'))
-var tail = parse.Many(parse.WithStringConcatCombiner, 0, 63, tailCharacter)
-
-var host = parse.All(parse.WithStringConcatCombiner, head, tail)
-
-var singleTLD = parse.All(parse.WithStringConcatCombiner,
-	parse.Rune('.'),
-	parse.Many(parse.WithStringConcatCombiner, 0, 3, head),
-)
-
-var allTLDs = parse.Many(parse.WithStringConcatCombiner, 1, 2, singleTLD)
-
-var port = parse.Many(parse.WithStringConcatCombiner, 0, 1,
-	parse.All(parse.WithStringConcatCombiner,
-		parse.Rune(':'),
-		parse.Many(parse.WithStringConcatCombiner, 1, 5, parse.ZeroToNine),
-	),
+// requestLine captures the URI from within an HTTP request line, e.g. "GET / HTTP/1.1".
+var requestLine = parse.All(func(items []interface{}) (interface{}, bool) {
+	return items[2], true
+},
+	parse.String("GET"),
+	parse.Rune(' '),
+	uri.ReferenceParser,
+	parse.String(" HTTP/1.1"),
 )
-
-var domain = parse.All(parse.WithStringConcatCombiner,
-	host,
-	allTLDs,
-	port,
-)
-
-var path = parse.All(parse.WithStringConcatCombiner,
-	parse.Rune('/'),
-	parse.Many(parse.WithStringConcatCombiner,
-		0,     // minimum match count
-		65536, // maximum match count
-		parse.AnyRune()),
-)
-
-var optionalPath = parse.Optional(parse.WithStringConcatCombiner, path)
-
-func urlParser(results []interface{}) (rv interface{}, ok bool) {
-	host, _ := results[0].(string)
-	path, _ := results[1].(string)
-
-	ok = true
-	url := URL{
-		Host: host,
-		Path: path,
-	}
-	return url, ok
-}
-
-var url = parse.All(urlParser, domain, optionalPath)
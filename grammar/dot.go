@@ -0,0 +1,87 @@
+package grammar
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DOT returns the grammar as a Graphviz graph, where each rule is the root of the tree of its
+// definition, and references are dashed edges to the rules that they refer to.
+func (g *Grammar) DOT() string {
+	var sb strings.Builder
+	g.WriteDOT(&sb)
+	return sb.String()
+}
+
+// WriteDOT writes the grammar as a Graphviz graph.
+func (g *Grammar) WriteDOT(w io.Writer) error {
+	d := &dot{ids: make(map[string]string)}
+	d.line("digraph grammar {")
+	d.line("\tnode [fontname=\"Helvetica\"];")
+	for i, name := range g.names {
+		d.ids[name] = "r" + strconv.Itoa(i)
+	}
+	for _, name := range g.names {
+		d.line("\t%s [label=%s, shape=box, style=bold];", d.ids[name], quote(name))
+		d.edge(d.ids[name], g.rules[name])
+	}
+	d.line("}")
+	_, err := io.WriteString(w, d.sb.String())
+	return err
+}
+
+type dot struct {
+	sb strings.Builder
+	// ids are the IDs of the rules.
+	ids map[string]string
+	n   int
+}
+
+func (d *dot) line(format string, args ...interface{}) {
+	fmt.Fprintf(&d.sb, format, args...)
+	d.sb.WriteByte('\n')
+}
+
+// edge writes an edge from the node with the ID to n, and writes n and its descendants. A
+// reference to a rule is drawn as a dashed edge to the rule.
+func (d *dot) edge(from string, n *Node) {
+	if n.Kind == Reference {
+		if id, ok := d.ids[n.Name]; ok {
+			d.line("\t%s -> %s [style=dashed];", from, id)
+			return
+		}
+	}
+	d.line("\t%s -> %s;", from, d.node(n))
+}
+
+// node writes the node and its descendants, and returns the ID of the node.
+func (d *dot) node(n *Node) string {
+	id := "n" + strconv.Itoa(d.n)
+	d.n++
+	switch n.Kind {
+	case Sequence:
+		d.line("\t%s [label=\"seq\", shape=circle];", id)
+	case Choice:
+		d.line("\t%s [label=\"|\", shape=circle];", id)
+	case Repeat:
+		d.line("\t%s [label=%s, shape=circle];", id, quote(repetition(n.Min, n.Max)))
+	case Literal:
+		d.line("\t%s [label=%s, shape=box, style=rounded];", id, quote(strconv.Quote(n.Text)))
+	case Class:
+		d.line("\t%s [label=%s, shape=box, style=rounded];", id, quote(n.Text))
+	default:
+		// Terminals, and references to rules which aren't defined.
+		d.line("\t%s [label=%s, shape=box];", id, quote(n.Name))
+	}
+	for _, c := range n.Children {
+		d.edge(id, c)
+	}
+	return id
+}
+
+// quote returns the string as a quoted DOT ID.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
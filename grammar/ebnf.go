@@ -0,0 +1,163 @@
+package grammar
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// EBNF returns the rules of the grammar in the EBNF notation of the W3C XML specification, with
+// a rule on each line, e.g. `list ::= item ("," item)*`. Terminals are written as their label in
+// angle brackets, e.g. <integer>.
+func (g *Grammar) EBNF() string {
+	var sb strings.Builder
+	g.WriteEBNF(&sb)
+	return sb.String()
+}
+
+// WriteEBNF writes the rules of the grammar in EBNF.
+func (g *Grammar) WriteEBNF(w io.Writer) error {
+	for _, name := range g.names {
+		if _, err := io.WriteString(w, name+" ::= "+ebnf(g.rules[name])+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String returns the node in EBNF.
+func (n *Node) String() string {
+	return ebnf(n)
+}
+
+func ebnf(n *Node) string {
+	switch n.Kind {
+	case Sequence:
+		if len(n.Children) == 0 {
+			return "()"
+		}
+		parts := make([]string, len(n.Children))
+		for i, c := range n.Children {
+			parts[i] = ebnf(c)
+			if c.Kind == Choice && compound(c) {
+				parts[i] = "(" + parts[i] + ")"
+			}
+		}
+		return strings.Join(parts, " ")
+	case Choice:
+		parts := make([]string, len(n.Children))
+		for i, c := range n.Children {
+			parts[i] = ebnf(c)
+		}
+		return strings.Join(parts, " | ")
+	case Repeat:
+		c := ebnf(n.Children[0])
+		if compound(n.Children[0]) {
+			c = "(" + c + ")"
+		}
+		return c + repetition(n.Min, n.Max)
+	case Literal:
+		return literal(n.Text)
+	case Class:
+		return class(n.class)
+	case Reference:
+		return n.Name
+	case Terminal:
+		return "<" + n.Name + ">"
+	}
+	return n.Kind.String()
+}
+
+// literal returns the text as a string. EBNF strings can't contain escapes, so text which
+// contains both kinds of quote is written as a sequence of strings, e.g. "it's a " '"' "quote" '"'.
+func literal(s string) string {
+	if !strings.Contains(s, `"`) {
+		return `"` + s + `"`
+	}
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	var parts []string
+	for s != "" {
+		// Take the double quotes at the start of the text, or the text up to the next one.
+		i := strings.IndexFunc(s, func(r rune) bool { return r != '"' })
+		if s[0] != '"' {
+			i = strings.IndexByte(s, '"')
+		}
+		if i < 0 {
+			i = len(s)
+		}
+		parts = append(parts, literal(s[:i]))
+		s = s[i:]
+	}
+	return strings.Join(parts, " ")
+}
+
+// class returns the class as a W3C character class, e.g. [a-z]. Runes which aren't printable
+// ASCII, or which have a meaning in a class, are written as #xN, e.g. [#x2D#x3B1-#x3C9]. Classes
+// which contain the first and last runes are written as the runes that they don't contain, e.g.
+// [^"].
+func class(c parse.Class) string {
+	ranges := c.Ranges()
+	prefix := "["
+	if len(ranges) > 0 && ranges[0][0] == 0 && ranges[len(ranges)-1][1] == unicode.MaxRune {
+		if not := c.Not().Ranges(); len(not) > 0 {
+			ranges, prefix = not, "[^"
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	for _, r := range ranges {
+		sb.WriteString(classRune(r[0]))
+		if r[1] > r[0] {
+			sb.WriteByte('-')
+			sb.WriteString(classRune(r[1]))
+		}
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+func classRune(r rune) string {
+	if r <= ' ' || r > '~' || strings.ContainsRune("#-[]^", r) {
+		return fmt.Sprintf("#x%X", r)
+	}
+	return string(r)
+}
+
+// compound returns true if the node is written as more than one item, so it needs parentheses
+// when it's repeated.
+func compound(n *Node) bool {
+	switch n.Kind {
+	case Literal:
+		return strings.Contains(n.Text, `"`) && strings.Contains(n.Text, "'")
+	case Sequence, Choice:
+		return len(n.Children) > 1
+	case Repeat:
+		return true
+	}
+	return false
+}
+
+// repetition returns the operator for repeating between min and max times, where a max of zero
+// is unlimited. Counts which don't have an operator in the W3C notation are written in the
+// style of a regular expression, e.g. {2,5}.
+func repetition(min, max int) string {
+	switch {
+	case min == 0 && max == 1:
+		return "?"
+	case min == 0 && max <= 0:
+		return "*"
+	case min == 1 && max <= 0:
+		return "+"
+	case max <= 0:
+		return "{" + strconv.Itoa(min) + ",}"
+	case min == max:
+		return "{" + strconv.Itoa(min) + "}"
+	}
+	return "{" + strconv.Itoa(min) + "," + strconv.Itoa(max) + "}"
+}
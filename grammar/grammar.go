@@ -0,0 +1,204 @@
+// Package grammar builds parsers from the parse package's combinators along with a description
+// of their structure, so that the same grammar that parses the input can be printed as EBNF, or
+// drawn as a Graphviz graph or as railroad diagrams, e.g. for the syntax diagrams of a manual.
+package grammar
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// Kind is the type of a Node.
+type Kind int
+
+// The kinds of Node.
+const (
+	// Sequence matches each of its children in turn.
+	Sequence Kind = iota
+	// Choice matches the first of its children which matches.
+	Choice
+	// Repeat matches its child between Min and Max times.
+	Repeat
+	// Literal matches its Text.
+	Literal
+	// Class matches a rune of the class in its Text.
+	Class
+	// Reference matches the rule with the Name.
+	Reference
+	// Terminal matches a parser which isn't described, e.g. parse.Int, with the Name as its label.
+	Terminal
+)
+
+var kindNames = []string{"sequence", "choice", "repeat", "literal", "class", "reference", "terminal"}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "Kind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return kindNames[k]
+}
+
+// Node describes a parser, and holds the parser.
+type Node struct {
+	Kind Kind
+	// Name is the name of the rule of a Reference, or the label of a Terminal.
+	Name string
+	// Text is the text of a Literal, or the class of a Class, e.g. "[a-z]".
+	Text string
+	// Min and Max are the number of times that a Repeat matches, where a Max of zero is unlimited.
+	Min, Max int
+	// Children are the nodes of a Sequence or Choice, or the node of a Repeat.
+	Children []*Node
+	class    parse.Class
+	parser   parse.Function
+}
+
+// Parser returns the parser that the node describes.
+func (n *Node) Parser() parse.Function {
+	return n.parser
+}
+
+func parsers(nodes []*Node) []parse.Function {
+	fs := make([]parse.Function, len(nodes))
+	for i, n := range nodes {
+		fs[i] = n.parser
+	}
+	return fs
+}
+
+// Seq matches each of the nodes in turn, and combines their items, in the same way as parse.All.
+func Seq(combiner parse.MultipleResultCombiner, nodes ...*Node) *Node {
+	return &Node{Kind: Sequence, Children: nodes, parser: parse.All(combiner, parsers(nodes)...)}
+}
+
+// Or matches the first of the nodes which matches, in the same way as parse.Any.
+func Or(nodes ...*Node) *Node {
+	return &Node{Kind: Choice, Children: nodes, parser: parse.Any(parsers(nodes)...)}
+}
+
+// Many matches the node at least atLeast times, and at most atMost times, where an atMost of zero
+// is unlimited, in the same way as parse.Many.
+func Many(combiner parse.MultipleResultCombiner, atLeast, atMost int, n *Node) *Node {
+	return &Node{
+		Kind:     Repeat,
+		Min:      atLeast,
+		Max:      atMost,
+		Children: []*Node{n},
+		parser:   parse.Many(combiner, atLeast, atMost, n.parser),
+	}
+}
+
+// Optional matches the node once, or not at all, in the same way as parse.Optional.
+func Optional(combiner parse.MultipleResultCombiner, n *Node) *Node {
+	return &Node{Kind: Repeat, Min: 0, Max: 1, Children: []*Node{n}, parser: parse.Optional(combiner, n.parser)}
+}
+
+// String matches the string, in the same way as parse.String.
+func String(s string) *Node {
+	return &Node{Kind: Literal, Text: s, parser: parse.String(s)}
+}
+
+// Rune matches the rune, in the same way as parse.Rune.
+func Rune(r rune) *Node {
+	return &Node{Kind: Literal, Text: string(r), parser: parse.Rune(r)}
+}
+
+// RuneInClass matches a rune of the class, in the same way as parse.RuneInClass.
+func RuneInClass(c parse.Class) *Node {
+	return &Node{Kind: Class, Text: c.String(), class: c, parser: parse.RuneInClass(c)}
+}
+
+// Span matches a run of runes of the class as a string, in the same way as parse.Span.
+func Span(c parse.Class, atLeast, atMost int) *Node {
+	return &Node{
+		Kind:     Repeat,
+		Min:      atLeast,
+		Max:      atMost,
+		Children: []*Node{{Kind: Class, Text: c.String(), class: c}},
+		parser:   parse.Span(c, atLeast, atMost),
+	}
+}
+
+// Describe labels a parser which doesn't have a description, e.g. Describe("integer",
+// parse.Int(10, 64)), so that it can be part of a grammar.
+func Describe(label string, f parse.Function) *Node {
+	return &Node{Kind: Terminal, Name: label, parser: f}
+}
+
+// Grammar is a set of named rules, which can refer to each other, and to themselves. Rules
+// mustn't be defined once the grammar is in use.
+type Grammar struct {
+	rules map[string]*Node
+	// names are the names of the rules, in the order that they were defined.
+	names []string
+}
+
+// New creates an empty Grammar.
+func New() *Grammar {
+	return &Grammar{
+		rules: make(map[string]*Node),
+	}
+}
+
+// Rule defines the rule, and returns a reference to it. Each rule is a parse.Named parser, so
+// the rules are recorded when a parse.Grammar is Tracing or Profiling.
+func (g *Grammar) Rule(name string, n *Node) *Node {
+	if _, ok := g.rules[name]; !ok {
+		g.names = append(g.names, name)
+	}
+	g.rules[name] = n
+	return g.Ref(name)
+}
+
+// Ref returns a reference to the rule, which can be defined later, e.g. so that a rule can refer
+// to itself.
+func (g *Grammar) Ref(name string) *Node {
+	return &Node{Kind: Reference, Name: name, parser: parse.Named(name, func(pi parse.Input) parse.Result {
+		n, ok := g.rules[name]
+		if !ok {
+			return parse.Failure(name, fmt.Errorf("grammar: rule %q isn't defined", name))
+		}
+		return n.parser(pi)
+	})}
+}
+
+// Rules returns the names of the rules, in the order that they were defined.
+func (g *Grammar) Rules() []string {
+	return append([]string(nil), g.names...)
+}
+
+// Lookup returns the definition of the rule, or nil if it isn't defined.
+func (g *Grammar) Lookup(name string) *Node {
+	return g.rules[name]
+}
+
+// Check returns an error if a rule refers to a rule which isn't defined.
+func (g *Grammar) Check() error {
+	for _, name := range g.names {
+		var err error
+		walk(g.rules[name], func(n *Node) {
+			if _, ok := g.rules[n.Name]; err == nil && n.Kind == Reference && !ok {
+				err = fmt.Errorf("grammar: rule %q refers to %q, which isn't defined", name, n.Name)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Compile creates a parse.Grammar which starts with the rule.
+func (g *Grammar) Compile(start string) *parse.Grammar {
+	return parse.Compile(g.Ref(start).parser)
+}
+
+// walk calls f with the node, and each of its descendants.
+func walk(n *Node, f func(n *Node)) {
+	f(n)
+	for _, c := range n.Children {
+		walk(c, f)
+	}
+}
@@ -0,0 +1,226 @@
+package grammar
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/parse"
+)
+
+func asSlice(items []interface{}) (interface{}, bool) {
+	return items, true
+}
+
+// fold applies the operators of items[1] to items[0], e.g. 1 + 2 - 3.
+func fold(items []interface{}) (interface{}, bool) {
+	v := items[0].(int64)
+	for _, item := range items[1].([]interface{}) {
+		op := item.([]interface{})
+		switch r := op[1].(int64); op[0].(rune) {
+		case '+':
+			v += r
+		case '-':
+			v -= r
+		case '*':
+			v *= r
+		case '/':
+			v /= r
+		}
+	}
+	return v, true
+}
+
+// arithmetic is a grammar of arithmetic expressions, e.g. "2*(3+4)".
+func arithmetic() *Grammar {
+	g := New()
+	g.Rule("expr", Seq(fold, g.Ref("term"), Many(asSlice, 0, 0, Seq(asSlice, Or(Rune('+'), Rune('-')), g.Ref("term")))))
+	g.Rule("term", Seq(fold, g.Ref("factor"), Many(asSlice, 0, 0, Seq(asSlice, Or(Rune('*'), Rune('/')), g.Ref("factor")))))
+	g.Rule("factor", Or(
+		g.Ref("number"),
+		Seq(func(items []interface{}) (interface{}, bool) { return items[1], true }, Rune('('), g.Ref("expr"), Rune(')')),
+	))
+	g.Rule("number", Describe("integer", parse.Int(10, 64)))
+	return g
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{input: "1", expected: 1},
+		{input: "1+2*3", expected: 7},
+		{input: "2*(3+4)-1", expected: 13},
+		{input: "((8))/2", expected: 4},
+	}
+	g := arithmetic()
+	expr := g.Ref("expr").Parser()
+	for i, test := range tests {
+		r := expr(input.NewFromString(test.input))
+		if !r.Success || r.Item != test.expected {
+			t.Errorf("test %v: for input '%v' expected %v, got %v", i, test.input, test.expected, r)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	pg := arithmetic().Compile("expr")
+	pg.Tracing = true
+	r, run := pg.Parse(context.Background(), input.NewFromString("1+2"))
+	if !r.Success || r.Item != int64(3) {
+		t.Fatalf("expected 3, got %v", r)
+	}
+	// Each rule is a named parser, so it appears in the trace.
+	if e := run.Trace.Events[0]; e.Name != "expr" || e.Exit {
+		t.Errorf("expected the trace to start by entering expr, got %v", e)
+	}
+	if !strings.Contains(run.Trace.String(), `← number ✓ "2"`) {
+		t.Errorf("expected the trace to contain the second number, got:\n%v", run.Trace)
+	}
+}
+
+func TestEBNF(t *testing.T) {
+	g := arithmetic()
+	g.Rule("identifier", Seq(asSlice, RuneInClass(parse.ClassRange('a', 'z')), Span(parse.ClassRange('a', 'z').Union(parse.ClassRange('0', '9')), 0, 0)))
+	g.Rule("string", Seq(asSlice, Rune('"'), Many(asSlice, 0, 0, Or(String(`\"`), RuneInClass(parse.ClassOf(`"`).Not()))), Rune('"')))
+	g.Rule("hex", Seq(asSlice, String("0x"), Many(asSlice, 1, 8, RuneInClass(parse.ClassOf("0123456789abcdef")))))
+	g.Rule("list", Seq(asSlice, g.Ref("identifier"), Optional(asSlice, Many(asSlice, 2, 0, Seq(asSlice, Rune(','), g.Ref("identifier"))))))
+	g.Rule("quotes", Many(asSlice, 1, 0, Or(String(`'"'`), String(`say "it's"`))))
+	g.Rule("quoted", Many(asSlice, 0, 0, String(`a'"`)))
+	expected := `expr ::= term (("+" | "-") term)*
+term ::= factor (("*" | "/") factor)*
+factor ::= number | "(" expr ")"
+number ::= <integer>
+identifier ::= [a-z] [0-9a-z]*
+string ::= '"' ('\"' | [^"])* '"'
+hex ::= "0x" [0-9a-f]{1,8}
+list ::= identifier (("," identifier){2,})?
+quotes ::= ("'" '"' "'" | "say " '"' "it's" '"')+
+quoted ::= ("a'" '"')*
+`
+	if actual := g.EBNF(); actual != expected {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, actual)
+	}
+}
+
+func TestEBNFClass(t *testing.T) {
+	tests := []struct {
+		class    parse.Class
+		expected string
+	}{
+		{
+			class:    parse.ClassRange('a', 'z').Union(parse.ClassRange('0', '9')),
+			expected: "[0-9a-z]",
+		},
+		{
+			class:    parse.ClassOf("-]^a"),
+			expected: "[#x2D#x5D-#x5Ea]",
+		},
+		{
+			class:    parse.ClassOf("#[ \t").Union(parse.ClassRange('α', 'ω'), parse.ClassRange(0x10000, 0x1FFFF)),
+			expected: "[#x9#x20#x23#x5B#x3B1-#x3C9#x10000-#x1FFFF]",
+		},
+		{
+			class:    parse.ClassOf("-]^\x01").Union(parse.ClassRange('é', 'ü')).Not(),
+			expected: "[^#x1#x2D#x5D-#x5E#xE9-#xFC]",
+		},
+		{
+			class:    parse.ClassRange(0, unicode.MaxRune),
+			expected: "[#x0-#x10FFFF]",
+		},
+	}
+
+	for i, test := range tests {
+		if actual := RuneInClass(test.class).String(); actual != test.expected {
+			t.Errorf("test %v: for class '%v' expected '%v', got '%v'", i, test.class, test.expected, actual)
+		}
+	}
+}
+
+func TestDOT(t *testing.T) {
+	g := New()
+	g.Rule("list", Seq(asSlice, g.Ref("item"), Many(asSlice, 0, 0, Seq(asSlice, Rune(','), g.Ref("item")))))
+	g.Rule("item", Span(parse.ClassRange('a', 'z'), 1, 0))
+	expected := `digraph grammar {
+	node [fontname="Helvetica"];
+	r0 [label="list", shape=box, style=bold];
+	n0 [label="seq", shape=circle];
+	n0 -> r1 [style=dashed];
+	n1 [label="*", shape=circle];
+	n2 [label="seq", shape=circle];
+	n3 [label="\",\"", shape=box, style=rounded];
+	n2 -> n3;
+	n2 -> r1 [style=dashed];
+	n1 -> n2;
+	n0 -> n1;
+	r0 -> n0;
+	r1 [label="item", shape=box, style=bold];
+	n4 [label="+", shape=circle];
+	n5 [label="[a-z]", shape=box, style=rounded];
+	n4 -> n5;
+	r1 -> n4;
+}
+`
+	if actual := g.DOT(); actual != expected {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, actual)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	g := arithmetic()
+	svg := g.SVG()
+	// The document must be well formed, and have a title for each rule.
+	d := xml.NewDecoder(strings.NewReader(svg))
+	var titles []string
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expected a well formed document, got %v:\n%v", err, svg)
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "text" && len(se.Attr) > 0 && se.Attr[0].Value == "rule" {
+			cd, _ := d.Token()
+			titles = append(titles, string(cd.(xml.CharData)))
+		}
+	}
+	if strings.Join(titles, " ") != "expr term factor number" {
+		t.Errorf("expected a diagram of each rule, got %v", titles)
+	}
+
+	// A loop around a box of 5 characters.
+	g = New()
+	g.Rule("item", Span(parse.ClassRange('a', 'z'), 1, 0))
+	if svg := g.SVG(); !strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="140" height="75" viewBox="0 0 140 75">`) {
+		t.Errorf("unexpected size:\n%v", svg)
+	}
+	if err := g.WriteSVG(io.Discard, "missing"); err == nil {
+		t.Errorf("expected an error for a rule which isn't defined")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := arithmetic().Check(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	g := New()
+	g.Rule("a", Seq(asSlice, Rune('a'), g.Ref("b")))
+	if err := g.Check(); err == nil || err.Error() != `grammar: rule "a" refers to "b", which isn't defined` {
+		t.Errorf("expected an undefined rule error, got %v", err)
+	}
+	if r := g.Ref("a").Parser()(input.NewFromString("ab")); r.Success || r.Error == nil {
+		t.Errorf("expected parsing an undefined rule to fail with an error, got %v", r)
+	}
+}
+
+func TestKindString(t *testing.T) {
+	if Repeat.String() != "repeat" || Kind(-1).String() != "Kind(-1)" {
+		t.Errorf("unexpected kind names %q and %q", Repeat, Kind(-1))
+	}
+}
@@ -0,0 +1,256 @@
+package grammar
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The dimensions of railroad diagrams, in pixels.
+const (
+	charWidth = 8.0
+	boxHeight = 22.0
+	padding   = 10.0
+	gap       = 10.0
+	radius    = 10.0
+	vgap      = 8.0
+	margin    = 10.0
+	// ends is the length of the lines at the start and end of a rule.
+	ends = 20.0
+	// title is the height of the name of a rule above its diagram.
+	title = 24.0
+)
+
+// SVG returns railroad diagrams of the rules, or of every rule if none are named, as an SVG
+// document.
+func (g *Grammar) SVG(rules ...string) string {
+	var sb strings.Builder
+	g.WriteSVG(&sb, rules...)
+	return sb.String()
+}
+
+// WriteSVG writes railroad diagrams of the rules, or of every rule if none are named, one
+// below the other, as an SVG document.
+func (g *Grammar) WriteSVG(w io.Writer, rules ...string) error {
+	if len(rules) == 0 {
+		rules = g.names
+	}
+	var body strings.Builder
+	var width, height float64
+	for _, name := range rules {
+		n, ok := g.rules[name]
+		if !ok {
+			return fmt.Errorf("grammar: rule %q isn't defined", name)
+		}
+		d := layout(n)
+		y := height + title + d.up + margin
+		fmt.Fprintf(&body, `<text class="rule" x="%s" y="%s">%s</text>`+"\n", num(margin), num(height+margin+14), html.EscapeString(name))
+		x := margin
+		fmt.Fprintf(&body, `<path d="M%s %sv16M%s %sh%s"/>`+"\n", num(x), num(y-8), num(x), num(y), num(ends))
+		d.draw(&body, x+ends, y)
+		x += ends + d.w
+		fmt.Fprintf(&body, `<path d="M%s %sh%sv-8v16"/>`+"\n", num(x), num(y), num(ends))
+		if right := x + ends + margin; right > width {
+			width = right
+		}
+		height = y + d.down + margin
+	}
+	_, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %[1]s %[2]s">
+<style>path{fill:none;stroke:#333;stroke-width:1.5}rect{fill:#fff;stroke:#333;stroke-width:1.5}text{font:14px monospace;text-anchor:middle}text.rule{font-weight:bold;text-anchor:start}text.count{font-size:11px}</style>
+%s</svg>
+`, num(width), num(height), body.String())
+	return err
+}
+
+// diagram is the layout of a node, which is drawn along a horizontal line. It's w wide, and
+// extends up above the line, and down below it.
+type diagram struct {
+	kind     Kind
+	w        float64
+	up, down float64
+	// text is the text of a box, or the count of a loop.
+	text    string
+	rounded bool
+	// children are the diagrams of a sequence, the branches of a choice, or the body of a loop,
+	// and offsets are the distances of the lines of the branches below the line of the choice.
+	children []*diagram
+	offsets  []float64
+}
+
+// layout returns the diagram of the node.
+func layout(n *Node) *diagram {
+	switch n.Kind {
+	case Sequence:
+		return sequence(layouts(n.Children))
+	case Choice:
+		return choice(layouts(n.Children))
+	case Repeat:
+		body := layout(n.Children[0])
+		if n.Max == 1 {
+			if n.Min == 1 {
+				return body
+			}
+			return choice([]*diagram{sequence(nil), body})
+		}
+		count := repetition(n.Min, n.Max)
+		if count == "*" || count == "+" {
+			count = ""
+		}
+		l := loop(body, count)
+		if n.Min == 0 {
+			return choice([]*diagram{sequence(nil), l})
+		}
+		return l
+	case Literal:
+		return box(n.Text, true)
+	case Class:
+		return box(n.Text, true)
+	}
+	return box(n.Name, false)
+}
+
+func layouts(nodes []*Node) []*diagram {
+	ds := make([]*diagram, len(nodes))
+	for i, n := range nodes {
+		ds[i] = layout(n)
+	}
+	return ds
+}
+
+// box is a terminal, drawn as a box with rounded ends, or a reference to a rule, drawn as a
+// rectangle.
+func box(text string, rounded bool) *diagram {
+	return &diagram{
+		kind:    Literal,
+		w:       float64(utf8.RuneCountInString(text))*charWidth + 2*padding,
+		up:      boxHeight / 2,
+		down:    boxHeight / 2,
+		text:    text,
+		rounded: rounded,
+	}
+}
+
+// sequence draws the diagrams one after the other. An empty sequence is an empty line.
+func sequence(children []*diagram) *diagram {
+	d := &diagram{kind: Sequence, children: children}
+	for i, c := range children {
+		if i > 0 {
+			d.w += gap
+		}
+		d.w += c.w
+		d.up = max(d.up, c.up)
+		d.down = max(d.down, c.down)
+	}
+	return d
+}
+
+// choice draws the first diagram on the line, and the others below it, with branches from the
+// start of the choice to each diagram, which join at the end.
+func choice(children []*diagram) *diagram {
+	d := &diagram{kind: Choice, children: children}
+	var bottom float64
+	for i, c := range children {
+		d.w = max(d.w, c.w+4*radius)
+		if i == 0 {
+			d.up, bottom = c.up, c.down
+			d.offsets = append(d.offsets, 0)
+			continue
+		}
+		offset := max(bottom+vgap+c.up, 2*radius)
+		d.offsets = append(d.offsets, offset)
+		bottom = offset + c.down
+	}
+	d.down = bottom
+	return d
+}
+
+// loop draws the diagram on the line, with a line back from its end to its start below it, and
+// the count, e.g. "{2,5}", below that.
+func loop(body *diagram, count string) *diagram {
+	d := &diagram{
+		kind:     Repeat,
+		w:        body.w + 2*radius,
+		up:       body.up,
+		children: []*diagram{body},
+		text:     count,
+	}
+	d.offsets = []float64{max(body.down+vgap, 2*radius)}
+	d.down = d.offsets[0]
+	if count != "" {
+		d.down += 14
+	}
+	return d
+}
+
+// draw writes the diagram as SVG, where the line starts at x, y.
+func (d *diagram) draw(sb *strings.Builder, x, y float64) {
+	switch d.kind {
+	case Literal:
+		rx := 0.0
+		if d.rounded {
+			rx = boxHeight / 2
+		}
+		fmt.Fprintf(sb, `<rect x="%s" y="%s" width="%s" height="%s" rx="%s"/>`+"\n", num(x), num(y-boxHeight/2), num(d.w), num(boxHeight), num(rx))
+		fmt.Fprintf(sb, `<text x="%s" y="%s">%s</text>`+"\n", num(x+d.w/2), num(y+5), html.EscapeString(d.text))
+	case Sequence:
+		for i, c := range d.children {
+			if i > 0 {
+				line(sb, x, y, gap)
+				x += gap
+			}
+			c.draw(sb, x, y)
+			x += c.w
+		}
+	case Choice:
+		for i, c := range d.children {
+			cy := y + d.offsets[i]
+			if i == 0 {
+				line(sb, x, y, 2*radius)
+			} else {
+				fmt.Fprintf(sb, `<path d="M%s %sq%s 0 %s %sV%sq0 %s %s %s"/>`+"\n",
+					num(x), num(y), num(radius), num(radius), num(radius), num(cy-radius), num(radius), num(radius), num(radius))
+			}
+			c.draw(sb, x+2*radius, cy)
+			line(sb, x+2*radius+c.w, cy, d.w-4*radius-c.w)
+			if i == 0 {
+				line(sb, x+d.w-2*radius, y, 2*radius)
+			} else {
+				fmt.Fprintf(sb, `<path d="M%s %sq%s 0 %s -%sV%sq0 -%s %s -%s"/>`+"\n",
+					num(x+d.w-2*radius), num(cy), num(radius), num(radius), num(radius), num(y+radius), num(radius), num(radius), num(radius))
+			}
+		}
+	case Repeat:
+		body := d.children[0]
+		line(sb, x, y, radius)
+		body.draw(sb, x+radius, y)
+		line(sb, x+radius+body.w, y, radius)
+		ly := y + d.offsets[0]
+		fmt.Fprintf(sb, `<path d="M%s %sq%s 0 %s %sV%sq0 %s -%s %sH%sq-%s 0 -%s -%sV%sq0 -%s %s -%s"/>`+"\n",
+			num(x+d.w-radius), num(y), num(radius), num(radius), num(radius), num(ly-radius), num(radius), num(radius), num(radius),
+			num(x+radius), num(radius), num(radius), num(radius), num(y+radius), num(radius), num(radius), num(radius))
+		if d.text != "" {
+			fmt.Fprintf(sb, `<text class="count" x="%s" y="%s">%s</text>`+"\n", num(x+d.w/2), num(ly+12), html.EscapeString(d.text))
+		}
+	}
+}
+
+// line draws a horizontal line.
+func line(sb *strings.Builder, x, y, length float64) {
+	if length > 0 {
+		fmt.Fprintf(sb, `<path d="M%s %sh%s"/>`+"\n", num(x), num(y), num(length))
+	}
+}
+
+func num(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func max(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -224,22 +224,30 @@ func (l *Stream) PeekN(n int) (s string, err error) {
 // retreat any further.
 var ErrStartOfFile = errors.New("SOF")
 
-// Retreat steps back a rune.
+// Retreat steps back a rune. Advancing past the end of the input moves the index, but not the
+// line and column, so stepping back from the end of the input, e.g. after a Peek that returned
+// io.EOF, doesn't change the Position either.
 func (l *Stream) Retreat() (r rune, err error) {
 	if l.Current == 0 {
 		return 0x0, ErrStartOfFile
 	}
 	l.Current--
+	atEOF := l.lastErr != nil
 
 	r, ok := fromBuffer(l.Start, l.Current, l.Buffer)
 	if !ok {
 		l.CurrentRune = 0x0
-		l.position.Retreat(l.CurrentRune)
+		if !atEOF {
+			l.position.Retreat(l.CurrentRune)
+		}
+		l.lastErr = nil
 		return 0x0, ErrStartOfFile
 	}
 
 	l.CurrentRune = r
-	l.position.Retreat(l.CurrentRune)
+	if !atEOF {
+		l.position.Retreat(l.CurrentRune)
+	}
 	l.lastErr = nil
 	return r, err
 }
@@ -284,3 +284,24 @@ func TestStreamPositionSOF(t *testing.T) {
 		t.Errorf("expected position 1:1, got %v:%v", line, col)
 	}
 }
+
+func TestStreamPositionPeekAtEOF(t *testing.T) {
+	s := NewFromString("ab\nc")
+	for i := 0; i < 4; i++ {
+		s.Advance()
+	}
+	if _, err := s.Peek(); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+	// Step back from the end of the input to where the stream was before the peek.
+	s.Retreat()
+	line, col := s.Position()
+	if line != 2 || col != 1 {
+		t.Errorf("expected position 2:1, got %v:%v", line, col)
+	}
+	s.Retreat()
+	line, col = s.Position()
+	if line != 2 || col != 0 {
+		t.Errorf("expected position 2:0, got %v:%v", line, col)
+	}
+}
@@ -0,0 +1,216 @@
+// Package ip contains parsers for IPv4 and IPv6 addresses, IPv6 zone IDs and CIDR
+// prefixes, which return net.IP, *net.IPAddr and *net.IPNet values.
+//
+// The parsers stop at the first rune which can't be part of the address, so they can be used
+// within larger grammars, e.g. a firewall rule or an access log line. Addresses which match
+// the syntax, but are invalid, e.g. an octet of 256, result in a failure with a *parse.Error
+// that contains the position of the invalid value.
+package ip
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// IPv4 captures an IPv4 address in dotted decimal form, e.g. 192.0.2.1, and returns a 4 byte
+// net.IP. Octets which are greater than 255, or have leading zeros, are errors.
+var IPv4 parse.Function = ipv4
+
+// IPv6 captures an IPv6 address, e.g. 2001:db8::1 or ::ffff:192.0.2.1, and returns a 16 byte
+// net.IP.
+var IPv6 parse.Function = ipv6
+
+// IP captures an IPv6 or IPv4 address, and returns a net.IP.
+var IP = parse.Any(IPv6, IPv4)
+
+// Zone captures an IPv6 zone ID, e.g. the "%eth0" of fe80::1%eth0, and returns the zone
+// without the percent sign.
+var Zone = parse.Then(func(items []interface{}) (interface{}, bool) {
+	return items[1], true
+}, parse.Rune('%'), parse.Span(zoneID, 1, 0))
+
+var zoneID = parse.ClassRange('a', 'z').Union(parse.ClassRange('A', 'Z'), parse.ClassRange('0', '9'), parse.ClassOf("-._~"))
+
+// IPAddr captures an IPv6 address with an optional zone ID, or an IPv4 address, and returns a
+// *net.IPAddr.
+var IPAddr parse.Function = func(pi parse.Input) parse.Result {
+	name := "IP address"
+	r := IPv6(pi)
+	if r.Success {
+		addr := &net.IPAddr{IP: r.Item.(net.IP)}
+		if z := parse.Accept(pi, Zone); z.Success {
+			addr.Zone = z.Item.(string)
+		}
+		return parse.Success(name, addr, nil)
+	}
+	if r.Error != nil {
+		return r
+	}
+	if r = IPv4(pi); r.Success {
+		return parse.Success(name, &net.IPAddr{IP: r.Item.(net.IP)}, nil)
+	}
+	return r
+}
+
+// CIDR captures an IP address followed by a prefix length, e.g. 192.0.2.0/24 or 2001:db8::/32,
+// and returns a *net.IPNet. Unlike net.ParseCIDR, the IP is kept as it was written, so that
+// the address of an interface, e.g. 192.0.2.1/24, isn't lost. The network number is
+// IP.Mask(Mask).
+var CIDR parse.Function = cidr
+
+// decimal captures a run of digits, and its range.
+var decimal = parse.WithRange(parse.Many(parse.WithStringConcatCombiner, 1, 0, parse.ZeroToNine))
+
+// dottedDecimal captures four decimals separated by dots, and returns them as a []parse.Ranged.
+var dottedDecimal = parse.All(func(items []interface{}) (interface{}, bool) {
+	octets := []parse.Ranged{items[0].(parse.Ranged)}
+	for _, item := range items[1].([]interface{}) {
+		octets = append(octets, item.(parse.Ranged))
+	}
+	return octets, true
+}, decimal, parse.Times(passThrough, 3, parse.Then(second, parse.Rune('.'), decimal)))
+
+func passThrough(items []interface{}) (interface{}, bool) {
+	return items, true
+}
+
+func second(items []interface{}) (interface{}, bool) {
+	return items[1], true
+}
+
+func ipv4(pi parse.Input) parse.Result {
+	name := "IPv4 address"
+	start := pi.Index()
+	r := dottedDecimal(pi)
+	if !r.Success {
+		parse.RewindTo(pi, start)
+		return parse.Failure(name, nil)
+	}
+	ip := make(net.IP, net.IPv4len)
+	for i, octet := range r.Item.([]parse.Ranged) {
+		s := octet.Item.(string)
+		if len(s) > 1 && s[0] == '0' {
+			parse.RewindTo(pi, start)
+			return parse.Failure(name, parse.Errorf(octet.Range.Start, "octet %s has a leading zero", s))
+		}
+		v, err := strconv.ParseUint(s, 10, 8)
+		if err != nil {
+			parse.RewindTo(pi, start)
+			return parse.Failure(name, parse.Errorf(octet.Range.Start, "octet %s out of range", s))
+		}
+		ip[i] = byte(v)
+	}
+	return parse.Success(name, ip, nil)
+}
+
+var hexDigit = parse.ClassRange('0', '9').Union(parse.ClassRange('a', 'f'), parse.ClassRange('A', 'F'))
+
+// group captures the 1 to 4 hex digits of a 16 bit group of an IPv6 address.
+var group = parse.Span(hexDigit, 1, 4)
+
+var ellipsis = parse.String("::")
+
+func ipv6(pi parse.Input) parse.Result {
+	name := "IPv6 address"
+	start := pi.Index()
+	ip := make(net.IP, net.IPv6len)
+	// i is the number of bytes of the address which have been written, and elided is the
+	// index where "::" was found, if it has been.
+	i, elided := 0, -1
+	if parse.Accept(pi, ellipsis).Success {
+		elided = 0
+	}
+	for {
+		// When there's an ellipsis, it must stand for at least one group.
+		limit := net.IPv6len
+		if elided >= 0 {
+			limit -= 2
+		}
+		// If the last separator was a single colon, it's only part of the address if a group
+		// follows it.
+		sep, trailingColon := pi.Index(), i > 0 && i != elided
+		// An embedded IPv4 address can only be at the end of the address.
+		if i+net.IPv4len <= limit {
+			r := parse.Accept(pi, IPv4)
+			if r.Success {
+				copy(ip[i:], r.Item.(net.IP))
+				i += net.IPv4len
+				break
+			}
+			if r.Error != nil {
+				parse.RewindTo(pi, start)
+				return parse.Failure(name, r.Error)
+			}
+		}
+		r := parse.Failure(name, nil)
+		if i+2 <= limit {
+			r = parse.Accept(pi, group)
+		}
+		if !r.Success {
+			if trailingColon {
+				parse.RewindTo(pi, sep-1)
+			}
+			break
+		}
+		v, _ := strconv.ParseUint(r.Item.(string), 16, 16)
+		ip[i], ip[i+1] = byte(v>>8), byte(v)
+		i += 2
+		if i == net.IPv6len {
+			break
+		}
+		if parse.Accept(pi, ellipsis).Success {
+			if elided >= 0 {
+				// Only one ellipsis is allowed, so the address ends before the second.
+				parse.RewindTo(pi, pi.Index()-2)
+				break
+			}
+			elided = i
+			continue
+		}
+		if !parse.Accept(pi, parse.Rune(':')).Success {
+			break
+		}
+	}
+	if elided < 0 {
+		if i != net.IPv6len {
+			parse.RewindTo(pi, start)
+			return parse.Failure(name, nil)
+		}
+		return parse.Success(name, ip, nil)
+	}
+	// Move the groups after the ellipsis to the end of the address.
+	n := net.IPv6len - i
+	copy(ip[elided+n:], ip[elided:i])
+	for j := elided; j < elided+n; j++ {
+		ip[j] = 0
+	}
+	return parse.Success(name, ip, nil)
+}
+
+// prefixLength captures the "/24" of a CIDR prefix.
+var prefixLength = parse.Then(second, parse.Rune('/'), decimal)
+
+func cidr(pi parse.Input) parse.Result {
+	name := "CIDR"
+	start := pi.Index()
+	r := IP(pi)
+	if !r.Success {
+		return parse.Failure(name, r.Error)
+	}
+	ip := r.Item.(net.IP)
+	r = parse.Accept(pi, prefixLength)
+	if !r.Success {
+		parse.RewindTo(pi, start)
+		return parse.Failure(name, nil)
+	}
+	length := r.Item.(parse.Ranged)
+	s, bits := length.Item.(string), len(ip)*8
+	n, err := strconv.Atoi(s)
+	if err != nil || n > bits || (len(s) > 1 && s[0] == '0') {
+		parse.RewindTo(pi, start)
+		return parse.Failure(name, parse.Errorf(length.Range.Start, "prefix length %s out of range", s))
+	}
+	return parse.Success(name, &net.IPNet{IP: ip, Mask: net.CIDRMask(n, bits)}, nil)
+}
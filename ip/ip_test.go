@@ -0,0 +1,156 @@
+package ip
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/parse"
+)
+
+// conformance contains addresses which must be parsed in the same way as net.ParseIP.
+var conformance = []string{
+	"0.0.0.0",
+	"192.0.2.1",
+	"255.255.255.255",
+	"::",
+	"::1",
+	"1::",
+	"2001:db8::1",
+	"2001:DB8:0:0:8:800:200C:417A",
+	"1:2:3:4:5:6:7:8",
+	"1:2:3:4:5:6:7::",
+	"::2:3:4:5:6:7:8",
+	"1::8",
+	"fe80::1:2",
+	"::ffff:192.0.2.1",
+	"::192.0.2.1",
+	"64:ff9b::192.0.2.33",
+	"1:2:3:4:5:6:192.0.2.1",
+	"1:2:3:4:5::192.0.2.1",
+}
+
+func TestConformance(t *testing.T) {
+	for _, test := range conformance {
+		expected := net.ParseIP(test)
+		if expected == nil {
+			t.Fatalf("net.ParseIP failed for %q", test)
+		}
+		pi := input.NewFromString(test)
+		r := IP(pi)
+		if !r.Success {
+			t.Errorf("for %q, expected success, got %v", test, r)
+			continue
+		}
+		if pi.Index() != int64(len(test)) {
+			t.Errorf("for %q, expected the whole input to be captured, got %d runes", test, pi.Index())
+		}
+		if actual := r.Item.(net.IP); !actual.Equal(expected) {
+			t.Errorf("for %q, expected %v, got %v", test, expected, actual)
+		}
+	}
+}
+
+func TestParsers(t *testing.T) {
+	tests := []struct {
+		input         string
+		parser        parse.Function
+		expected      bool
+		expectedItem  interface{}
+		expectedIndex int64
+		expectedError string
+	}{
+		{input: "192.0.2.1", parser: IPv4, expected: true, expectedItem: net.IP{192, 0, 2, 1}, expectedIndex: 9},
+		{input: "192.0.2.1.5", parser: IPv4, expected: true, expectedItem: net.IP{192, 0, 2, 1}, expectedIndex: 9},
+		{input: "192.0.2", parser: IPv4, expected: false},
+		{input: "192.0.2.", parser: IPv4, expected: false},
+		{input: "a.b.c.d", parser: IPv4, expected: false},
+		{input: "192.0.2.256", parser: IPv4, expected: false, expectedError: "line 1, column 8: octet 256 out of range"},
+		{input: "1000.0.2.1", parser: IPv4, expected: false, expectedError: "line 1, column 0: octet 1000 out of range"},
+		{input: "192.0.02.1", parser: IPv4, expected: false, expectedError: "line 1, column 6: octet 02 has a leading zero"},
+		{input: "::1", parser: IPv4, expected: false},
+		{input: "2001:db8::1]", parser: IPv6, expected: true, expectedItem: net.ParseIP("2001:db8::1"), expectedIndex: 11},
+		{input: "2001:db8::1:", parser: IPv6, expected: true, expectedItem: net.ParseIP("2001:db8::1"), expectedIndex: 11},
+		{input: "2001:db8::1::2", parser: IPv6, expected: true, expectedItem: net.ParseIP("2001:db8::1"), expectedIndex: 11},
+		{input: "1:2:3:4:5:6:7:8:9", parser: IPv6, expected: true, expectedItem: net.ParseIP("1:2:3:4:5:6:7:8"), expectedIndex: 15},
+		{input: "1::2:3:4:5:6:7:8", parser: IPv6, expected: true, expectedItem: net.ParseIP("1::2:3:4:5:6:7"), expectedIndex: 14},
+		{input: "::12345", parser: IPv6, expected: true, expectedItem: net.ParseIP("::1234"), expectedIndex: 6},
+		{input: "::ffff:1.2.3.400", parser: IPv6, expected: false, expectedError: "line 1, column 13: octet 400 out of range"},
+		{input: "1:2:3:4:5:6:7", parser: IPv6, expected: false},
+		{input: ":1::", parser: IPv6, expected: false},
+		{input: "192.0.2.1", parser: IPv6, expected: false},
+		{input: "cafe", parser: IPv6, expected: false},
+		{input: "192.0.2.1", parser: IP, expected: true, expectedItem: net.IP{192, 0, 2, 1}, expectedIndex: 9},
+		{input: "::1", parser: IP, expected: true, expectedItem: net.IPv6loopback, expectedIndex: 3},
+		{input: "fe80::1%eth0", parser: IPAddr, expected: true, expectedItem: &net.IPAddr{IP: net.ParseIP("fe80::1"), Zone: "eth0"}, expectedIndex: 12},
+		{input: "fe80::1%", parser: IPAddr, expected: true, expectedItem: &net.IPAddr{IP: net.ParseIP("fe80::1")}, expectedIndex: 7},
+		{input: "192.0.2.1%eth0", parser: IPAddr, expected: true, expectedItem: &net.IPAddr{IP: net.IP{192, 0, 2, 1}}, expectedIndex: 9},
+		{input: "%eth0", parser: Zone, expected: true, expectedItem: "eth0", expectedIndex: 5},
+		{input: "192.0.2.1/24", parser: CIDR, expected: true, expectedItem: &net.IPNet{IP: net.IP{192, 0, 2, 1}, Mask: net.CIDRMask(24, 32)}, expectedIndex: 12},
+		{input: "2001:db8::/32", parser: CIDR, expected: true, expectedItem: &net.IPNet{IP: net.ParseIP("2001:db8::"), Mask: net.CIDRMask(32, 128)}, expectedIndex: 13},
+		{input: "::ffff:192.0.2.0/120", parser: CIDR, expected: true, expectedItem: &net.IPNet{IP: net.ParseIP("::ffff:192.0.2.0"), Mask: net.CIDRMask(120, 128)}, expectedIndex: 20},
+		{input: "192.0.2.1", parser: CIDR, expected: false},
+		{input: "192.0.2.1/", parser: CIDR, expected: false},
+		{input: "192.0.2.1/33", parser: CIDR, expected: false, expectedError: "line 1, column 10: prefix length 33 out of range"},
+		{input: "192.0.2.1/024", parser: CIDR, expected: false, expectedError: "line 1, column 10: prefix length 024 out of range"},
+		{input: "2001:db8::/129", parser: CIDR, expected: false, expectedError: "line 1, column 11: prefix length 129 out of range"},
+	}
+
+	for i, test := range tests {
+		pi := input.NewFromString(test.input)
+		r := test.parser(pi)
+		if r.Success != test.expected {
+			t.Errorf("test %v: for input '%v' expected success %v, got %v", i, test.input, test.expected, r)
+			continue
+		}
+		if test.expectedError != "" || r.Error != nil {
+			actual := ""
+			if r.Error != nil {
+				actual = r.Error.Error()
+			}
+			if actual != test.expectedError {
+				t.Errorf("test %v: for input '%v' expected error %q, got %q", i, test.input, test.expectedError, actual)
+			}
+		}
+		if !r.Success {
+			if pi.Index() != 0 {
+				t.Errorf("test %v: for input '%v' expected index 0, got %d", i, test.input, pi.Index())
+			}
+			continue
+		}
+		if !reflect.DeepEqual(r.Item, test.expectedItem) {
+			t.Errorf("test %v: for input '%v' expected %v, got %v", i, test.input, test.expectedItem, r.Item)
+		}
+		if pi.Index() != test.expectedIndex {
+			t.Errorf("test %v: for input '%v' expected index %d, got %d", i, test.input, test.expectedIndex, pi.Index())
+		}
+	}
+}
+
+func TestWithinGrammar(t *testing.T) {
+	rule := parse.All(func(items []interface{}) (interface{}, bool) {
+		return items[2], true
+	}, parse.String("allow"), parse.Rune(' '), CIDR, parse.String(" port 22"))
+	r := rule(input.NewFromString("allow 10.0.0.0/8 port 22"))
+	if !r.Success {
+		t.Fatalf("expected success, got %v", r)
+	}
+	if n := r.Item.(*net.IPNet); !n.Contains(net.IP{10, 1, 2, 3}) {
+		t.Errorf("expected %v to contain 10.1.2.3", n)
+	}
+}
+
+func BenchmarkIP(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		IP(input.NewFromString("2001:db8:85a3::8a2e:370:7334"))
+	}
+}
+
+func BenchmarkNetParseIP(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		net.ParseIP("2001:db8:85a3::8a2e:370:7334")
+	}
+}
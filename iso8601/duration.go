@@ -0,0 +1,215 @@
+package iso8601
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// Duration is an ISO 8601 duration, e.g. P1Y2M3DT4H5M6.5S. Years, months and days vary in
+// length, so they're kept separate from the hours, minutes and seconds.
+type Duration struct {
+	Years  int
+	Months int
+	Weeks  int
+	Days   int
+	// Time is the hours, minutes and seconds of the duration.
+	Time time.Duration
+}
+
+// AddTo returns the time plus the duration. Years, months, weeks and days are added using
+// time.AddDate, so they keep the same time of day.
+func (d Duration) AddTo(t time.Time) time.Time {
+	return t.AddDate(d.Years, d.Months, d.Weeks*7+d.Days).Add(d.Time)
+}
+
+// SubtractFrom returns the time minus the duration.
+func (d Duration) SubtractFrom(t time.Time) time.Time {
+	return t.Add(-d.Time).AddDate(-d.Years, -d.Months, -(d.Weeks*7 + d.Days))
+}
+
+// Duration returns the duration as a time.Duration, where days are 24 hours long. If the
+// duration has years or months, exact is false, and they're approximated using the average
+// length of a year and month in the Gregorian calendar.
+func (d Duration) Duration() (td time.Duration, exact bool) {
+	const day = 24 * time.Hour
+	const year = 365*day + day*97/400
+	td = time.Duration(d.Weeks*7+d.Days)*day + d.Time
+	td += time.Duration(d.Years)*year + time.Duration(d.Months)*(year/12)
+	return td, d.Years == 0 && d.Months == 0
+}
+
+// String returns the duration in ISO 8601 format, e.g. P1DT2H.
+func (d Duration) String() string {
+	var sb strings.Builder
+	sb.WriteRune('P')
+	for _, c := range []struct {
+		value int
+		unit  rune
+	}{{d.Years, 'Y'}, {d.Months, 'M'}, {d.Weeks, 'W'}, {d.Days, 'D'}} {
+		if c.value != 0 {
+			sb.WriteString(strconv.Itoa(c.value))
+			sb.WriteRune(c.unit)
+		}
+	}
+	if d.Time == 0 {
+		if sb.Len() == 1 {
+			return "PT0S"
+		}
+		return sb.String()
+	}
+	sb.WriteRune('T')
+	if h := d.Time / time.Hour; h != 0 {
+		sb.WriteString(strconv.FormatInt(int64(h), 10) + "H")
+	}
+	if m := d.Time % time.Hour / time.Minute; m != 0 {
+		sb.WriteString(strconv.FormatInt(int64(m), 10) + "M")
+	}
+	if s := d.Time % time.Minute; s != 0 {
+		sb.WriteString(strconv.FormatFloat(s.Seconds(), 'f', -1, 64) + "S")
+	}
+	return sb.String()
+}
+
+// durationComponents are the units of a duration, in the order they must be written.
+var durationComponents = []struct {
+	unit      rune
+	name      string
+	time      time.Duration
+	component parse.Function
+}{
+	{unit: 'Y', name: "years"},
+	{unit: 'M', name: "months"},
+	{unit: 'W', name: "weeks"},
+	{unit: 'D', name: "days"},
+	{unit: 'H', name: "hours", time: time.Hour},
+	{unit: 'M', name: "minutes", time: time.Minute},
+	{unit: 'S', name: "seconds", time: time.Second},
+}
+
+func init() {
+	for i, c := range durationComponents {
+		durationComponents[i].component = parse.All(func(items []interface{}) (interface{}, bool) {
+			return items[0], true
+		}, decimal(1, 0, ".,"), parse.Rune(c.unit))
+	}
+}
+
+// DurationParser captures an ISO 8601 duration, e.g. P1Y2M10DT2H30M or PT0.5S, and returns a
+// Duration. Only the last component may have a fraction, and only hours, minutes and
+// seconds may be fractional.
+var DurationParser parse.Function = duration
+
+func duration(pi parse.Input) parse.Result {
+	name := "duration"
+	start := pi.Index()
+	if !parse.Rune('P')(pi).Success {
+		parse.RewindTo(pi, start)
+		return parse.Failure(name, nil)
+	}
+	var d Duration
+	var count int
+	var fractional *field
+	for i, c := range durationComponents {
+		if c.unit == 'H' {
+			// The time components must follow a 'T'.
+			beforeT := pi.Index()
+			if !parse.Rune('T')(pi).Success {
+				parse.RewindTo(pi, beforeT)
+				break
+			}
+			count = -1
+		}
+		r := c.component(pi)
+		if r.Error != nil && r.Error != io.EOF {
+			parse.RewindTo(pi, start)
+			return parse.Failure(name, r.Error)
+		}
+		if !r.Success {
+			continue
+		}
+		f := r.Item.(field)
+		if fractional != nil {
+			parse.RewindTo(pi, start)
+			return parse.Failure(name, parse.Errorf(fractional.pos, "only the last component of a duration may have a fraction"))
+		}
+		if f.fractional {
+			if c.time == 0 {
+				parse.RewindTo(pi, start)
+				return parse.Failure(name, parse.Errorf(f.pos, "fractional %s are not supported", c.name))
+			}
+			fractional = &f
+			d.Time += time.Duration(f.fraction) * (c.time / time.Second)
+		}
+		d.Time += time.Duration(f.value) * c.time
+		switch i {
+		case 0:
+			d.Years = f.value
+		case 1:
+			d.Months = f.value
+		case 2:
+			d.Weeks = f.value
+		case 3:
+			d.Days = f.value
+		}
+		if count < 0 {
+			count = 0
+		}
+		count++
+	}
+	if count <= 0 {
+		// There must be at least one component, and at least one after a 'T'.
+		parse.RewindTo(pi, start)
+		return parse.Failure(name, nil)
+	}
+	return parse.Success(name, d, nil)
+}
+
+// Interval is a time interval. If the interval was written with a duration, e.g.
+// 2007-03-01T13:00:00Z/P1Y2M10DT2H30M, Duration contains it.
+type Interval struct {
+	Start    time.Time
+	End      time.Time
+	Duration Duration
+}
+
+var instant = parse.Or(DateTime, Date)
+var slash = parse.Rune('/')
+
+var intervalForms = parse.Any(
+	parse.All(func(items []interface{}) (interface{}, bool) {
+		return Interval{Start: items[0].(time.Time), End: items[2].(time.Time)}, true
+	}, instant, slash, instant),
+	parse.All(func(items []interface{}) (interface{}, bool) {
+		start, d := items[0].(time.Time), items[2].(Duration)
+		return Interval{Start: start, End: d.AddTo(start), Duration: d}, true
+	}, instant, slash, DurationParser),
+	parse.All(func(items []interface{}) (interface{}, bool) {
+		d, end := items[0].(Duration), items[2].(time.Time)
+		return Interval{Start: d.SubtractFrom(end), End: end, Duration: d}, true
+	}, DurationParser, slash, instant),
+)
+
+// IntervalParser captures an ISO 8601 time interval written as a start and end, a start and
+// duration, or a duration and end, e.g. 2007-03-01T13:00:00Z/2008-05-11T15:30:00Z,
+// 2007-03-01/P1Y or P1D/2007-03-01T00:00:00Z, and returns an Interval. An interval which
+// ends before it starts results in an error.
+var IntervalParser parse.Function = interval
+
+func interval(pi parse.Input) parse.Result {
+	name := "interval"
+	pos := parse.PosOf(pi)
+	r := intervalForms(pi)
+	if !r.Success {
+		return r
+	}
+	i := r.Item.(Interval)
+	if i.End.Before(i.Start) {
+		parse.RewindTo(pi, pos.Index)
+		return parse.Failure(name, parse.Errorf(pos, "interval ends before it starts"))
+	}
+	return parse.Success(name, i, nil)
+}
@@ -0,0 +1,96 @@
+package iso8601
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	runParserTests(t, []parserTest{
+		{input: "P1Y2M3DT4H5M6S", parser: DurationParser, expected: true, expectedItem: Duration{Years: 1, Months: 2, Days: 3, Time: 4*time.Hour + 5*time.Minute + 6*time.Second}, expectedIndex: 14},
+		{input: "P1M", parser: DurationParser, expected: true, expectedItem: Duration{Months: 1}, expectedIndex: 3},
+		{input: "PT1M", parser: DurationParser, expected: true, expectedItem: Duration{Time: time.Minute}, expectedIndex: 4},
+		{input: "P2W", parser: DurationParser, expected: true, expectedItem: Duration{Weeks: 2}, expectedIndex: 3},
+		{input: "PT0.5S", parser: DurationParser, expected: true, expectedItem: Duration{Time: 500 * time.Millisecond}, expectedIndex: 6},
+		{input: "PT1,5H", parser: DurationParser, expected: true, expectedItem: Duration{Time: 90 * time.Minute}, expectedIndex: 6},
+		{input: "P1DT12H", parser: DurationParser, expected: true, expectedItem: Duration{Days: 1, Time: 12 * time.Hour}, expectedIndex: 7},
+		{input: "PT36H", parser: DurationParser, expected: true, expectedItem: Duration{Time: 36 * time.Hour}, expectedIndex: 5},
+		{input: "P1D rest", parser: DurationParser, expected: true, expectedItem: Duration{Days: 1}, expectedIndex: 3},
+		{input: "P", parser: DurationParser, expected: false},
+		{input: "PT", parser: DurationParser, expected: false},
+		{input: "P1DT", parser: DurationParser, expected: false},
+		{input: "Paris", parser: DurationParser, expected: false},
+		{input: "1D", parser: DurationParser, expected: false},
+		{input: "P1.5Y", parser: DurationParser, expected: false, expectedError: "line 1, column 1: fractional years are not supported"},
+		{input: "PT1.5H30M", parser: DurationParser, expected: false, expectedError: "line 1, column 2: only the last component of a duration may have a fraction"},
+	})
+}
+
+func TestDurationMethods(t *testing.T) {
+	d := Duration{Years: 1, Months: 1, Days: 1, Time: 90 * time.Minute}
+	start := time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC)
+	if actual, expected := d.AddTo(start), time.Date(2021, 3, 4, 1, 30, 0, 0, time.UTC); !actual.Equal(expected) {
+		t.Errorf("AddTo: expected %v, got %v", expected, actual)
+	}
+	if actual, expected := (Duration{Days: 1, Time: time.Hour}).SubtractFrom(start), time.Date(2020, 1, 29, 23, 0, 0, 0, time.UTC); !actual.Equal(expected) {
+		t.Errorf("SubtractFrom: expected %v, got %v", expected, actual)
+	}
+	if actual, exact := (Duration{Weeks: 1, Time: time.Hour}).Duration(); actual != 169*time.Hour || !exact {
+		t.Errorf("Duration: expected 169h exactly, got %v, %v", actual, exact)
+	}
+	if _, exact := d.Duration(); exact {
+		t.Errorf("Duration: expected a duration with months to be inexact")
+	}
+
+	tests := []struct {
+		input    Duration
+		expected string
+	}{
+		{input: Duration{}, expected: "PT0S"},
+		{input: Duration{Years: 1, Days: 2}, expected: "P1Y2D"},
+		{input: Duration{Weeks: 3}, expected: "P3W"},
+		{input: Duration{Months: 1, Time: 36*time.Hour + 500*time.Millisecond}, expected: "P1MT36H0.5S"},
+		{input: Duration{Time: 5 * time.Minute}, expected: "PT5M"},
+	}
+	for i, test := range tests {
+		if actual := test.input.String(); actual != test.expected {
+			t.Errorf("test %v: expected %q, got %q", i, test.expected, actual)
+		}
+	}
+}
+
+func TestInterval(t *testing.T) {
+	runParserTests(t, []parserTest{
+		{
+			input:         "2007-03-01T13:00:00Z/2008-05-11T15:30:00Z",
+			parser:        IntervalParser,
+			expected:      true,
+			expectedItem:  Interval{Start: time.Date(2007, 3, 1, 13, 0, 0, 0, time.UTC), End: time.Date(2008, 5, 11, 15, 30, 0, 0, time.UTC)},
+			expectedIndex: 41,
+		},
+		{
+			input:         "2007-03-01T13:00:00Z/P1Y2M10DT2H30M",
+			parser:        IntervalParser,
+			expected:      true,
+			expectedItem:  Interval{Start: time.Date(2007, 3, 1, 13, 0, 0, 0, time.UTC), End: time.Date(2008, 5, 11, 15, 30, 0, 0, time.UTC), Duration: Duration{Years: 1, Months: 2, Days: 10, Time: 150 * time.Minute}},
+			expectedIndex: 35,
+		},
+		{
+			input:         "P1D/2007-03-01",
+			parser:        IntervalParser,
+			expected:      true,
+			expectedItem:  Interval{Start: time.Date(2007, 2, 28, 0, 0, 0, 0, time.UTC), End: time.Date(2007, 3, 1, 0, 0, 0, 0, time.UTC), Duration: Duration{Days: 1}},
+			expectedIndex: 14,
+		},
+		{
+			input:         "2007-03-01/2007-04-01",
+			parser:        IntervalParser,
+			expected:      true,
+			expectedItem:  Interval{Start: time.Date(2007, 3, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2007, 4, 1, 0, 0, 0, 0, time.UTC)},
+			expectedIndex: 21,
+		},
+		{input: "2008-01-01/2007-01-01", parser: IntervalParser, expected: false, expectedError: "line 1, column 0: interval ends before it starts"},
+		{input: "P1D/P2D", parser: IntervalParser, expected: false},
+		{input: "2007-03-01", parser: IntervalParser, expected: false},
+	})
+}
@@ -0,0 +1,372 @@
+// Package iso8601 contains parsers for ISO 8601 dates, times, durations and intervals, and
+// for the RFC 3339 and RFC 1123 formats which are based on them.
+//
+// Each parser is a parse.Function, so it can be used within other grammars, e.g. to parse
+// the timestamp at the start of a log line. Values which match the syntax, but are invalid,
+// e.g. a month of 13, result in a failure with a *parse.Error that contains the position of
+// the invalid value.
+package iso8601
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// field is a number within a date or time, and its position.
+type field struct {
+	pos   parse.Pos
+	value int
+	// fraction is the fractional part of the number, in billionths, if it has one.
+	fraction   int64
+	fractional bool
+}
+
+var digit = parse.ClassRange('0', '9')
+
+// number captures between min and max digits, and returns them as a field.
+func number(min, max int) parse.Function {
+	digits := parse.Span(digit, min, max)
+	return func(pi parse.Input) parse.Result {
+		pos := parse.PosOf(pi)
+		r := digits(pi)
+		if !r.Success {
+			return r
+		}
+		v, err := strconv.Atoi(r.Item.(string))
+		if err != nil {
+			parse.RewindTo(pi, pos.Index)
+			return parse.Failure("number", &parse.Error{Pos: pos, Err: err})
+		}
+		return parse.Success("number", field{pos: pos, value: v}, nil)
+	}
+}
+
+// decimal captures a number, followed by an optional fraction which starts with one of the
+// separators, e.g. "30.5".
+func decimal(min, max int, separators string) parse.Function {
+	n := number(min, max)
+	fraction := parse.Then(parse.WithStringConcatCombiner, parse.RuneIn(separators), parse.Span(digit, 1, 0))
+	return parse.Then(func(items []interface{}) (interface{}, bool) {
+		f := items[0].(field)
+		if s := items[1].(string); s != "" {
+			// Keep nanosecond precision, and discard the rest.
+			digits := (s[1:] + "000000000")[:9]
+			f.fraction, _ = strconv.ParseInt(digits, 10, 64)
+			f.fractional = true
+		}
+		return f, true
+	}, n, parse.Optional(parse.WithStringConcatCombiner, fraction))
+}
+
+// fields captures all of the functions, and returns the fields within their items.
+func fields(functions ...parse.Function) parse.Function {
+	return parse.All(func(items []interface{}) (interface{}, bool) {
+		var fs []field
+		for _, item := range items {
+			if f, ok := item.(field); ok {
+				fs = append(fs, f)
+			}
+		}
+		return fs, true
+	}, functions...)
+}
+
+// convert captures f, which returns fields, and converts them into a value. If the
+// conversion fails, the input is rolled back, and the error is returned.
+func convert(name string, f parse.Function, fn func(fs []field) (interface{}, error)) parse.Function {
+	return func(pi parse.Input) parse.Result {
+		start := pi.Index()
+		r := f(pi)
+		if !r.Success {
+			return r
+		}
+		v, err := fn(r.Item.([]field))
+		if err != nil {
+			parse.RewindTo(pi, start)
+			return parse.Failure(name, err)
+		}
+		return parse.Success(name, v, nil)
+	}
+}
+
+var hyphen = parse.Rune('-')
+var colon = parse.Rune(':')
+
+// Date captures a calendar date (2006-01-02 or 20060102), a week date (2006-W01-1 or
+// 2006W011), an ordinal date (2006-002 or 2006002), or a year and month (2006-01), and
+// returns a time.Time at midnight UTC. Week dates without a day refer to the Monday.
+var Date parse.Function = parse.Any(extendedDate, basicDate, yearMonth)
+
+// extendedDate and basicDate capture complete dates, i.e. dates with a day, in the extended
+// format, which separates the fields with hyphens, and in the basic format, which doesn't.
+var extendedDate = parse.Any(
+	convert("date", fields(number(4, 4), hyphen, parse.Rune('W'), number(2, 2), hyphen, number(1, 1)), weekDate),
+	convert("date", fields(number(4, 4), hyphen, number(2, 2), hyphen, number(2, 2)), calendarDate),
+	convert("date", fields(number(4, 4), hyphen, number(3, 3)), ordinalDate),
+)
+
+var basicDate = parse.Any(
+	convert("date", fields(number(4, 4), parse.Rune('W'), number(2, 2), number(1, 1)), weekDate),
+	convert("date", fields(number(4, 4), number(2, 2), number(2, 2)), calendarDate),
+	convert("date", fields(number(4, 4), number(3, 3)), ordinalDate),
+)
+
+// yearMonth captures the dates which don't have a day: a year and month, or a year and week.
+var yearMonth = parse.Any(
+	convert("date", fields(number(4, 4), hyphen, parse.Rune('W'), number(2, 2)), weekDate),
+	convert("date", fields(number(4, 4), hyphen, number(2, 2)), calendarDate),
+	convert("date", fields(number(4, 4), parse.Rune('W'), number(2, 2)), weekDate),
+)
+
+func firstOrNil(items []interface{}) (interface{}, bool) {
+	if len(items) == 0 {
+		return nil, true
+	}
+	return items[0], true
+}
+
+func second(items []interface{}) (interface{}, bool) {
+	return items[1], true
+}
+
+func daysIn(month time.Month, year int) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// calendarDate converts a year, month and optional day into a date.
+func calendarDate(fs []field) (interface{}, error) {
+	y, m := fs[0], fs[1]
+	if m.value < 1 || m.value > 12 {
+		return nil, parse.Errorf(m.pos, "month %d out of range", m.value)
+	}
+	day := 1
+	if len(fs) > 2 {
+		d := fs[2]
+		if days := daysIn(time.Month(m.value), y.value); d.value < 1 || d.value > days {
+			return nil, parse.Errorf(d.pos, "day %d out of range for %v %d", d.value, time.Month(m.value), y.value)
+		}
+		day = d.value
+	}
+	return time.Date(y.value, time.Month(m.value), day, 0, 0, 0, 0, time.UTC), nil
+}
+
+// weekDate converts a year, ISO week and optional weekday into a date.
+func weekDate(fs []field) (interface{}, error) {
+	y, w := fs[0], fs[1]
+	_, weeks := time.Date(y.value, 12, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+	if w.value < 1 || w.value > weeks {
+		return nil, parse.Errorf(w.pos, "week %d out of range for %d", w.value, y.value)
+	}
+	day := 1
+	if len(fs) > 2 {
+		d := fs[2]
+		if d.value < 1 || d.value > 7 {
+			return nil, parse.Errorf(d.pos, "weekday %d out of range", d.value)
+		}
+		day = d.value
+	}
+	// Week 1 is the week which contains the 4th of January.
+	jan4 := time.Date(y.value, 1, 4, 0, 0, 0, 0, time.UTC)
+	monday := (int(jan4.Weekday()) + 6) % 7
+	return jan4.AddDate(0, 0, (w.value-1)*7+day-1-monday), nil
+}
+
+// ordinalDate converts a year and day of the year into a date.
+func ordinalDate(fs []field) (interface{}, error) {
+	y, d := fs[0], fs[1]
+	days := time.Date(y.value, 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	if d.value < 1 || d.value > days {
+		return nil, parse.Errorf(d.pos, "day %d out of range for %d", d.value, y.value)
+	}
+	return time.Date(y.value, 1, d.value, 0, 0, 0, 0, time.UTC), nil
+}
+
+// extendedClock and basicClock capture a time of day in the extended format, e.g. 15:04:05.999,
+// which separates the fields with colons, and in the basic format, e.g. 150405, which doesn't,
+// and return the time.Duration since midnight. The last component may have a fraction. A time
+// which is followed by a digit or a colon mixes the formats, e.g. 15:0405, so it doesn't match.
+var extendedClock = complete(parse.Any(
+	convert("time", fields(number(2, 2), colon, number(2, 2), colon, decimal(2, 2, ".,")), timeOfDay(true, false)),
+	convert("time", fields(number(2, 2), colon, decimal(2, 2, ".,")), timeOfDay(true, false)),
+	convert("time", fields(decimal(2, 2, ".,")), timeOfDay(true, false)),
+))
+
+var basicClock = complete(parse.Any(
+	convert("time", fields(number(2, 2), number(2, 2), decimal(2, 2, ".,")), timeOfDay(true, false)),
+	convert("time", fields(number(2, 2), decimal(2, 2, ".,")), timeOfDay(true, false)),
+	convert("time", fields(decimal(2, 2, ".,")), timeOfDay(true, false)),
+))
+
+var timeRunes = parse.ClassOf("0123456789:")
+
+// complete captures f, unless it's followed by a digit or a colon, which would mean that f
+// only matched part of a time or zone.
+func complete(f parse.Function) parse.Function {
+	return func(pi parse.Input) parse.Result {
+		start := pi.Index()
+		r := f(pi)
+		if !r.Success {
+			return r
+		}
+		end := pi.Index()
+		next := parse.RuneInClass(timeRunes)(pi)
+		parse.RewindTo(pi, end)
+		if next.Success {
+			parse.RewindTo(pi, start)
+			return parse.Failure(r.Name, nil)
+		}
+		return r
+	}
+}
+
+var clockUnits = []time.Duration{time.Hour, time.Minute, time.Second}
+
+// timeOfDay converts hours, and optional minutes and seconds, into a duration. If allow24 is
+// true, 24:00:00 may be used for the end of the day. If allowLeap is true, the second may be
+// 60, for a leap second, which results in the first second of the next minute, because a
+// time.Time can't hold a leap second.
+func timeOfDay(allow24, allowLeap bool) func(fs []field) (interface{}, error) {
+	return func(fs []field) (interface{}, error) {
+		limits := []int{23, 59, 59}
+		if allowLeap {
+			limits[2] = 60
+		}
+		names := []string{"hour", "minute", "second"}
+		var d time.Duration
+		for i, f := range fs {
+			if f.value > limits[i] && !(i == 0 && allow24 && f.value == 24) {
+				return nil, parse.Errorf(f.pos, "%s %d out of range", names[i], f.value)
+			}
+			d += time.Duration(f.value) * clockUnits[i]
+			if f.fractional {
+				d += time.Duration(f.fraction) * (clockUnits[i] / time.Second)
+			}
+		}
+		if d > 24*time.Hour {
+			return nil, parse.Errorf(fs[0].pos, "hour 24 must be followed by zero minutes and seconds")
+		}
+		return d, nil
+	}
+}
+
+// Zone captures a time zone designator, e.g. Z, +01:00, -0130 or +01, and returns a
+// *time.Location. Zero offsets return time.UTC, and other offsets return a fixed zone.
+var Zone parse.Function = parse.Any(extendedZone, basicZone)
+
+// extendedZone and basicZone capture zone designators in the extended and basic formats.
+var extendedZone = parse.Any(utc, complete(parse.Any(
+	convert("zone", fields(sign, number(2, 2), colon, number(2, 2)), zoneOffset),
+	convert("zone", fields(sign, number(2, 2)), zoneOffset),
+)))
+
+var basicZone = parse.Any(utc, complete(parse.Any(
+	convert("zone", fields(sign, number(2, 2), number(2, 2)), zoneOffset),
+	convert("zone", fields(sign, number(2, 2)), zoneOffset),
+)))
+
+var utc = parse.All(func([]interface{}) (interface{}, bool) { return time.UTC, true }, parse.Rune('Z'))
+
+// optionalZone captures the zone if there is one, and returns nil if there isn't. A sign which
+// doesn't start a zone, e.g. the +0100 of the extended format 15:04:05+0100, doesn't match.
+func optionalZone(zone parse.Function) parse.Function {
+	return func(pi parse.Input) parse.Result {
+		r := zone(pi)
+		if r.Success || r.Error != nil {
+			return r
+		}
+		start := pi.Index()
+		next := sign(pi)
+		parse.RewindTo(pi, start)
+		if next.Success {
+			return parse.Failure("zone", nil)
+		}
+		return parse.Success("zone", nil, nil)
+	}
+}
+
+// sign captures a + or - and returns a field with the value 1 or -1.
+func sign(pi parse.Input) parse.Result {
+	pos := parse.PosOf(pi)
+	r := parse.RuneIn("+-")(pi)
+	if !r.Success {
+		return r
+	}
+	if r.Item == '-' {
+		return parse.Success("sign", field{pos: pos, value: -1}, nil)
+	}
+	return parse.Success("sign", field{pos: pos, value: 1}, nil)
+}
+
+// zoneOffset converts a sign, hours and optional minutes into a location.
+func zoneOffset(fs []field) (interface{}, error) {
+	h := fs[1]
+	if h.value > 23 {
+		return nil, parse.Errorf(h.pos, "zone offset hour %d out of range", h.value)
+	}
+	offset := h.value * 60 * 60
+	if len(fs) > 2 {
+		m := fs[2]
+		if m.value > 59 {
+			return nil, parse.Errorf(m.pos, "zone offset minute %d out of range", m.value)
+		}
+		offset += m.value * 60
+	}
+	if offset == 0 {
+		return time.UTC, nil
+	}
+	return time.FixedZone("", fs[0].value*offset), nil
+}
+
+// Time captures a time of day, e.g. 15:04:05.999, 15:04, 150405 or 15, with an optional
+// zone in the same format, and returns a time.Time on January 1 of year 0, in the same way as
+// time.Parse. If there's no zone, the time is in UTC. The end of the day can be written as
+// 24:00:00.
+var Time parse.Function = parse.Any(timeWithZone(extendedClock, extendedZone), timeWithZone(basicClock, basicZone))
+
+func timeWithZone(clock, zone parse.Function) parse.Function {
+	return parse.All(func(items []interface{}) (interface{}, bool) {
+		return combine(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC), items[0].(time.Duration), items[1]), true
+	}, clock, optionalZone(zone))
+}
+
+// combine returns the time of day on the date, in the zone if it's not nil.
+func combine(date time.Time, tod time.Duration, zone interface{}) time.Time {
+	loc := time.UTC
+	if zone != nil {
+		loc = zone.(*time.Location)
+	}
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc).Add(tod)
+}
+
+// DateTime captures a complete date and a time, separated by 'T', with an optional zone, e.g.
+// 2006-01-02T15:04:05Z or 20060102T150405+0100, and returns a time.Time. The date, time and
+// zone must all be in the extended format, or all in the basic format. If there's no zone, the
+// time is in UTC.
+var DateTime parse.Function = parse.Any(
+	dateTime(extendedDate, extendedClock, extendedZone),
+	dateTime(basicDate, basicClock, basicZone),
+)
+
+func dateTime(date, clock, zone parse.Function) parse.Function {
+	return parse.All(func(items []interface{}) (interface{}, bool) {
+		return combine(items[0].(time.Time), items[2].(time.Duration), items[3]), true
+	}, date, parse.Rune('T'), clock, optionalZone(zone))
+}
+
+// RFC3339 captures a timestamp in the format of RFC 3339, e.g. 2006-01-02T15:04:05.999Z or
+// 2006-01-02 15:04:05+07:00, and returns a time.Time. A leap second, e.g. 23:59:60Z, is
+// returned as the first second of the next minute.
+var RFC3339 parse.Function = parse.All(func(items []interface{}) (interface{}, bool) {
+	return combine(items[0].(time.Time), items[2].(time.Duration), items[3]), true
+},
+	convert("date", fields(number(4, 4), hyphen, number(2, 2), hyphen, number(2, 2)), calendarDate),
+	parse.RuneIn("Tt "),
+	convert("time", fields(number(2, 2), colon, number(2, 2), colon, decimal(2, 2, ".")), timeOfDay(false, true)),
+	parse.Any(
+		parse.All(func([]interface{}) (interface{}, bool) { return time.UTC, true }, parse.RuneIn("Zz")),
+		convert("zone", fields(sign, number(2, 2), colon, number(2, 2)), zoneOffset),
+	),
+)
@@ -0,0 +1,200 @@
+package iso8601
+
+import (
+	"testing"
+	"time"
+
+	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/parse"
+)
+
+type parserTest struct {
+	input         string
+	parser        parse.Function
+	expected      bool
+	expectedItem  interface{}
+	expectedError string
+	expectedIndex int64
+}
+
+func runParserTests(t *testing.T, tests []parserTest) {
+	t.Helper()
+	for i, test := range tests {
+		pi := input.NewFromString(test.input)
+		result := test.parser(pi)
+		if result.Success != test.expected {
+			t.Errorf("test %v: for input '%v' expected %v but got %v", i, test.input, test.expected, result)
+		}
+		if test.expected && !equal(result.Item, test.expectedItem) {
+			t.Errorf("test %v: for input '%v' expected item %v but got %v", i, test.input, test.expectedItem, result.Item)
+		}
+		var actualError string
+		if result.Error != nil {
+			actualError = result.Error.Error()
+		}
+		if actualError != test.expectedError {
+			t.Errorf("test %v: for input '%v' expected error '%v' but got '%v'", i, test.input, test.expectedError, actualError)
+		}
+		if test.expectedIndex != pi.Index() {
+			t.Errorf("test %v: for input '%v' expected index %d, got %d", i, test.input, test.expectedIndex, pi.Index())
+		}
+	}
+}
+
+// equal compares times by instant and offset, and other values by equality.
+func equal(actual, expected interface{}) bool {
+	at, ok := actual.(time.Time)
+	et, ok2 := expected.(time.Time)
+	if ok && ok2 {
+		_, ao := at.Zone()
+		_, eo := et.Zone()
+		return at.Equal(et) && ao == eo
+	}
+	ai, ok := actual.(Interval)
+	ei, ok2 := expected.(Interval)
+	if ok && ok2 {
+		return equal(ai.Start, ei.Start) && equal(ai.End, ei.End) && ai.Duration == ei.Duration
+	}
+	return actual == expected
+}
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDate(t *testing.T) {
+	runParserTests(t, []parserTest{
+		{input: "2006-01-02", parser: Date, expected: true, expectedItem: date(2006, 1, 2), expectedIndex: 10},
+		{input: "20060102", parser: Date, expected: true, expectedItem: date(2006, 1, 2), expectedIndex: 8},
+		{input: "2006-01", parser: Date, expected: true, expectedItem: date(2006, 1, 1), expectedIndex: 7},
+		{input: "2020-366", parser: Date, expected: true, expectedItem: date(2020, 12, 31), expectedIndex: 8},
+		{input: "2021032", parser: Date, expected: true, expectedItem: date(2021, 2, 1), expectedIndex: 7},
+		{input: "2009-W01-1", parser: Date, expected: true, expectedItem: date(2008, 12, 29), expectedIndex: 10},
+		{input: "2009-W53-7", parser: Date, expected: true, expectedItem: date(2010, 1, 3), expectedIndex: 10},
+		{input: "2004W537", parser: Date, expected: true, expectedItem: date(2005, 1, 2), expectedIndex: 8},
+		{input: "2020-W10", parser: Date, expected: true, expectedItem: date(2020, 3, 2), expectedIndex: 8},
+		{input: "2024-02-29", parser: Date, expected: true, expectedItem: date(2024, 2, 29), expectedIndex: 10},
+		{input: "2006-13-02", parser: Date, expected: false, expectedError: "line 1, column 5: month 13 out of range"},
+		{input: "2006-00", parser: Date, expected: false, expectedError: "line 1, column 5: month 0 out of range"},
+		{input: "2023-02-29", parser: Date, expected: false, expectedError: "line 1, column 8: day 29 out of range for February 2023"},
+		{input: "2021-366", parser: Date, expected: false, expectedError: "line 1, column 5: day 366 out of range for 2021"},
+		{input: "2021-W53-1", parser: Date, expected: false, expectedError: "line 1, column 6: week 53 out of range for 2021"},
+		{input: "2021-W01-8", parser: Date, expected: false, expectedError: "line 1, column 9: weekday 8 out of range"},
+		{input: "201-01-01", parser: Date, expected: false},
+		{input: "not a date", parser: Date, expected: false},
+	})
+}
+
+func TestTime(t *testing.T) {
+	tod := func(h, m, s, ns int, loc *time.Location) time.Time {
+		return time.Date(0, 1, 1, h, m, s, ns, loc)
+	}
+	plusOne := time.FixedZone("", 60*60)
+	minusOneThirty := time.FixedZone("", -90*60)
+	runParserTests(t, []parserTest{
+		{input: "15:04:05", parser: Time, expected: true, expectedItem: tod(15, 4, 5, 0, time.UTC), expectedIndex: 8},
+		{input: "150405", parser: Time, expected: true, expectedItem: tod(15, 4, 5, 0, time.UTC), expectedIndex: 6},
+		{input: "15:04", parser: Time, expected: true, expectedItem: tod(15, 4, 0, 0, time.UTC), expectedIndex: 5},
+		{input: "15", parser: Time, expected: true, expectedItem: tod(15, 0, 0, 0, time.UTC), expectedIndex: 2},
+		{input: "15:04:05.123456789", parser: Time, expected: true, expectedItem: tod(15, 4, 5, 123456789, time.UTC), expectedIndex: 18},
+		{input: "15:04:05,5", parser: Time, expected: true, expectedItem: tod(15, 4, 5, 500000000, time.UTC), expectedIndex: 10},
+		{input: "15:04.5", parser: Time, expected: true, expectedItem: tod(15, 4, 30, 0, time.UTC), expectedIndex: 7},
+		{input: "15.25", parser: Time, expected: true, expectedItem: tod(15, 15, 0, 0, time.UTC), expectedIndex: 5},
+		{input: "15:04:05Z", parser: Time, expected: true, expectedItem: tod(15, 4, 5, 0, time.UTC), expectedIndex: 9},
+		{input: "15:04:05+01:00", parser: Time, expected: true, expectedItem: tod(15, 4, 5, 0, plusOne), expectedIndex: 14},
+		{input: "150405-0130", parser: Time, expected: true, expectedItem: tod(15, 4, 5, 0, minusOneThirty), expectedIndex: 11},
+		{input: "15:04:05+01", parser: Time, expected: true, expectedItem: tod(15, 4, 5, 0, plusOne), expectedIndex: 11},
+		{input: "24:00:00", parser: Time, expected: true, expectedItem: tod(24, 0, 0, 0, time.UTC), expectedIndex: 8},
+		{input: "24:00:01", parser: Time, expected: false, expectedError: "line 1, column 0: hour 24 must be followed by zero minutes and seconds"},
+		{input: "25:00", parser: Time, expected: false, expectedError: "line 1, column 0: hour 25 out of range"},
+		{input: "12:60", parser: Time, expected: false, expectedError: "line 1, column 3: minute 60 out of range"},
+		{input: "12:59:60", parser: Time, expected: false, expectedError: "line 1, column 6: second 60 out of range"},
+		{input: "12:00+24:00", parser: Time, expected: false, expectedError: "line 1, column 6: zone offset hour 24 out of range"},
+		{input: "15:0405", parser: Time, expected: false},
+		{input: "1504:05", parser: Time, expected: false},
+		{input: "15:04:05+0100", parser: Time, expected: false},
+		{input: "1", parser: Time, expected: false},
+	})
+}
+
+func TestDateTime(t *testing.T) {
+	plusSeven := time.FixedZone("", 7*60*60)
+	runParserTests(t, []parserTest{
+		{input: "2006-01-02T15:04:05Z", parser: DateTime, expected: true, expectedItem: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), expectedIndex: 20},
+		{input: "2006-01-02T15:04:05", parser: DateTime, expected: true, expectedItem: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), expectedIndex: 19},
+		{input: "20060102T150405+0700", parser: DateTime, expected: true, expectedItem: time.Date(2006, 1, 2, 15, 4, 5, 0, plusSeven), expectedIndex: 20},
+		{input: "2006-W01-1T00:00Z", parser: DateTime, expected: true, expectedItem: time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC), expectedIndex: 17},
+		{input: "2006-12-31T24:00:00Z", parser: DateTime, expected: true, expectedItem: time.Date(2007, 1, 1, 0, 0, 0, 0, time.UTC), expectedIndex: 20},
+		{input: "2006-01-02 15:04:05", parser: DateTime, expected: false},
+		{input: "2006-02-30T15:04:05", parser: DateTime, expected: false, expectedError: "line 1, column 8: day 30 out of range for February 2006"},
+		{input: "2006032T1504Z", parser: DateTime, expected: true, expectedItem: time.Date(2006, 2, 1, 15, 4, 0, 0, time.UTC), expectedIndex: 13},
+		{input: "2006-01-02T15Z", parser: DateTime, expected: true, expectedItem: time.Date(2006, 1, 2, 15, 0, 0, 0, time.UTC), expectedIndex: 14},
+		{input: "20060102T15+07", parser: DateTime, expected: true, expectedItem: time.Date(2006, 1, 2, 15, 0, 0, 0, plusSeven), expectedIndex: 14},
+		{input: "2024-01-01T1000Z", parser: DateTime, expected: false},
+		{input: "20240101T10:00Z", parser: DateTime, expected: false},
+		{input: "2024-01-01T10:0000", parser: DateTime, expected: false},
+		{input: "2024-01-01T10:00+0100", parser: DateTime, expected: false},
+		{input: "20240101T1000+01:00", parser: DateTime, expected: false},
+		{input: "2024-01T10:00", parser: DateTime, expected: false},
+		{input: "2024-W01T10:00", parser: DateTime, expected: false},
+	})
+}
+
+func TestRFC3339(t *testing.T) {
+	tests := []string{
+		"2006-01-02T15:04:05Z",
+		"2006-01-02T15:04:05.999999999Z",
+		"2006-01-02T15:04:05+07:00",
+		"2006-01-02T15:04:05.5-01:30",
+		"1985-04-12T23:20:50.52Z",
+	}
+	for _, test := range tests {
+		expected, err := time.Parse(time.RFC3339Nano, test)
+		if err != nil {
+			t.Fatalf("time.Parse failed for %q: %v", test, err)
+		}
+		runParserTests(t, []parserTest{
+			{input: test, parser: RFC3339, expected: true, expectedItem: expected, expectedIndex: int64(len(test))},
+		})
+	}
+	runParserTests(t, []parserTest{
+		{input: "2006-01-02t15:04:05z", parser: RFC3339, expected: true, expectedItem: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), expectedIndex: 20},
+		{input: "2006-01-02 15:04:05Z", parser: RFC3339, expected: true, expectedItem: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), expectedIndex: 20},
+		{input: "2006-01-02T15:04:05", parser: RFC3339, expected: false},
+		{input: "2006-01-02T15:04Z", parser: RFC3339, expected: false},
+		{input: "20060102T150405Z", parser: RFC3339, expected: false},
+		{input: "2006-01-02T15:04:05,5Z", parser: RFC3339, expected: false},
+		{input: "2006-01-02T24:00:00Z", parser: RFC3339, expected: false, expectedError: "line 1, column 11: hour 24 out of range"},
+		{input: "2006-01-32T15:04:05Z", parser: RFC3339, expected: false, expectedError: "line 1, column 8: day 32 out of range for January 2006"},
+		{input: "2016-12-31T23:59:60Z", parser: RFC3339, expected: true, expectedItem: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), expectedIndex: 20},
+		{input: "1990-12-31T15:59:60.5-08:00", parser: RFC3339, expected: true, expectedItem: time.Date(1990, 12, 31, 16, 0, 0, 500000000, time.FixedZone("", -8*60*60)), expectedIndex: 27},
+		{input: "2016-12-31T23:59:61Z", parser: RFC3339, expected: false, expectedError: "line 1, column 17: second 61 out of range"},
+	})
+}
+
+func TestDateWithinGrammar(t *testing.T) {
+	logLine := parse.All(func(items []interface{}) (interface{}, bool) {
+		return items[0], true
+	}, RFC3339, parse.String(" INFO started"))
+	result := logLine(input.NewFromString("2006-01-02T15:04:05Z INFO started"))
+	if !result.Success {
+		t.Fatalf("expected success, got %v", result)
+	}
+	if expected := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC); !result.Item.(time.Time).Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, result.Item)
+	}
+}
+
+func BenchmarkRFC3339(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		RFC3339(input.NewFromString("2006-01-02T15:04:05.999999999+07:00"))
+	}
+}
+
+func BenchmarkTimeParse(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		time.Parse(time.RFC3339Nano, "2006-01-02T15:04:05.999999999+07:00")
+	}
+}
@@ -0,0 +1,111 @@
+package iso8601
+
+import (
+	"time"
+
+	"github.com/a-h/lexical/parse"
+)
+
+var weekdays = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+var months = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+// zoneNames are the zones defined by RFC 822, and their offsets in hours.
+var zoneNames = map[string]int{
+	"UT":  0,
+	"UTC": 0,
+	"GMT": 0,
+	"Z":   0,
+	"EST": -5,
+	"EDT": -4,
+	"CST": -6,
+	"CDT": -5,
+	"MST": -7,
+	"MDT": -6,
+	"PST": -8,
+	"PDT": -7,
+}
+
+// nameField captures one of the names, and returns a field with its index as the value.
+func nameField(names []string) parse.Function {
+	f := parse.OneOfStrings(names...)
+	return func(pi parse.Input) parse.Result {
+		pos := parse.PosOf(pi)
+		r := f(pi)
+		if !r.Success {
+			return r
+		}
+		for i, name := range names {
+			if name == r.Item {
+				return parse.Success("name", field{pos: pos, value: i}, nil)
+			}
+		}
+		return parse.Failure("name", nil)
+	}
+}
+
+var space = parse.Rune(' ')
+
+// RFC1123 captures a timestamp in the format of RFC 1123, as used by HTTP and email, e.g.
+// "Mon, 02 Jan 2006 15:04:05 GMT" or "Mon, 02 Jan 2006 15:04:05 -0700", and returns a
+// time.Time. The day of the week is optional, but must match the date if it's present.
+// Zone names other than those defined by RFC 822, e.g. GMT or PST, result in an error.
+var RFC1123 parse.Function = rfc1123
+
+var rfc1123Parts = parse.All(func(items []interface{}) (interface{}, bool) {
+	return items, true
+},
+	parse.Optional(firstOrNil, parse.Then(func(items []interface{}) (interface{}, bool) {
+		return items[0], true
+	}, nameField(weekdays), parse.String(", "))),
+	convert("date", fields(number(1, 2), space, nameField(months), space, number(4, 4)), func(fs []field) (interface{}, error) {
+		// The fields are day, month, year, and the month's index starts at zero.
+		fs[0], fs[2] = fs[2], fs[0]
+		fs[1].value++
+		return calendarDate(fs)
+	}),
+	space,
+	convert("time", fields(number(2, 2), colon, number(2, 2), colon, number(2, 2)), timeOfDay(false, false)),
+	space,
+	parse.Any(
+		convert("zone", fields(sign, number(2, 2), number(2, 2)), zoneOffset),
+		namedZone,
+	),
+)
+
+func rfc1123(pi parse.Input) parse.Result {
+	name := "RFC 1123"
+	start := pi.Index()
+	r := rfc1123Parts(pi)
+	if !r.Success {
+		return r
+	}
+	items := r.Item.([]interface{})
+	date := items[1].(time.Time)
+	if weekday, ok := items[0].(field); ok && time.Weekday(weekday.value) != date.Weekday() {
+		parse.RewindTo(pi, start)
+		return parse.Failure(name, parse.Errorf(weekday.pos, "%s doesn't match the date, which is a %v", weekdays[weekday.value], date.Weekday()))
+	}
+	return parse.Success(name, combine(date, items[3].(time.Duration), items[5]), nil)
+}
+
+var zoneName = parse.Span(parse.ClassRange('A', 'Z'), 1, 5)
+
+// namedZone captures a zone name, e.g. GMT, and returns its *time.Location.
+func namedZone(pi parse.Input) parse.Result {
+	pos := parse.PosOf(pi)
+	r := zoneName(pi)
+	if !r.Success {
+		return r
+	}
+	name := r.Item.(string)
+	offset, ok := zoneNames[name]
+	if !ok {
+		parse.RewindTo(pi, pos.Index)
+		return parse.Failure("zone", parse.Errorf(pos, "unknown time zone %q", name))
+	}
+	if offset == 0 {
+		return parse.Success("zone", time.UTC, nil)
+	}
+	return parse.Success("zone", time.FixedZone(name, offset*60*60), nil)
+}
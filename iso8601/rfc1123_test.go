@@ -0,0 +1,42 @@
+package iso8601
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRFC1123(t *testing.T) {
+	for _, test := range []string{
+		"Mon, 02 Jan 2006 15:04:05 GMT",
+		"Tue, 10 Nov 2009 23:00:00 UTC",
+		"Sun, 06 Nov 1994 08:49:37 GMT",
+	} {
+		expected, err := time.Parse(time.RFC1123, test)
+		if err != nil {
+			t.Fatalf("time.Parse failed for %q: %v", test, err)
+		}
+		runParserTests(t, []parserTest{
+			{input: test, parser: RFC1123, expected: true, expectedItem: expected, expectedIndex: int64(len(test))},
+		})
+	}
+	for _, test := range []string{
+		"Mon, 02 Jan 2006 15:04:05 -0700",
+		"Sat, 01 Jul 2023 00:00:00 +0530",
+	} {
+		expected, err := time.Parse(time.RFC1123Z, test)
+		if err != nil {
+			t.Fatalf("time.Parse failed for %q: %v", test, err)
+		}
+		runParserTests(t, []parserTest{
+			{input: test, parser: RFC1123, expected: true, expectedItem: expected, expectedIndex: int64(len(test))},
+		})
+	}
+	runParserTests(t, []parserTest{
+		{input: "2 Jan 2006 15:04:05 PST", parser: RFC1123, expected: true, expectedItem: time.Date(2006, 1, 2, 15, 4, 5, 0, time.FixedZone("PST", -8*60*60)), expectedIndex: 23},
+		{input: "Tue, 02 Jan 2006 15:04:05 GMT", parser: RFC1123, expected: false, expectedError: "line 1, column 0: Tue doesn't match the date, which is a Monday"},
+		{input: "Mon, 02 Jan 2006 15:04:05 CEST", parser: RFC1123, expected: false, expectedError: `line 1, column 26: unknown time zone "CEST"`},
+		{input: "Mon, 31 Feb 2006 15:04:05 GMT", parser: RFC1123, expected: false, expectedError: "line 1, column 5: day 31 out of range for February 2006"},
+		{input: "Mon, 02 Foo 2006 15:04:05 GMT", parser: RFC1123, expected: false},
+		{input: "Mon, 02 Jan 2006 15:04 GMT", parser: RFC1123, expected: false},
+	})
+}
@@ -0,0 +1,249 @@
+// Package json is a JSON (RFC 8259) parser built from the parse package's combinators.
+//
+// Documents can be decoded into the same interface{} values as encoding/json, or parsed into
+// a tree of Nodes, which records the range of the input that each value was parsed from.
+package json
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// Kind is the type of a JSON value.
+type Kind int
+
+// The kinds of JSON value.
+const (
+	Null Kind = iota
+	Bool
+	Number
+	String
+	Array
+	Object
+)
+
+var kindNames = []string{"null", "bool", "number", "string", "array", "object"}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "Kind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return kindNames[k]
+}
+
+// Node is a JSON value, and the range of the input it was parsed from.
+type Node struct {
+	Kind  Kind
+	Range parse.Range
+	// Value is the value of a Bool (bool), Number (float64) or String (string).
+	Value interface{}
+	// Elements are the values within an Array.
+	Elements []*Node
+	// Members are the members of an Object, in the order that they appeared.
+	Members []*Member
+}
+
+// Member is a name/value pair within an Object.
+type Member struct {
+	Name      string
+	NameRange parse.Range
+	Value     *Node
+}
+
+// Interface returns the value of the node in the same form as encoding/json, i.e. nil, bool,
+// float64, string, []interface{} or map[string]interface{}. If an object has duplicate
+// member names, the last value is used.
+func (n *Node) Interface() interface{} {
+	switch n.Kind {
+	case Array:
+		values := make([]interface{}, len(n.Elements))
+		for i, e := range n.Elements {
+			values[i] = e.Interface()
+		}
+		return values
+	case Object:
+		values := make(map[string]interface{}, len(n.Members))
+		for _, m := range n.Members {
+			values[m.Name] = m.Value.Interface()
+		}
+		return values
+	}
+	return n.Value
+}
+
+// Parse parses a complete JSON document from the input. If the document is invalid, the
+// error is a *parse.Error which contains the position of the problem.
+func Parse(pi parse.Input) (*Node, error) {
+	fi := parse.NewFurthestInput(pi)
+	r := document(fi)
+	if !r.Success {
+		if r.Error != nil && r.Error != io.EOF {
+			return nil, r.Error
+		}
+		return nil, fi.Unexpected()
+	}
+	return r.Item.(*Node), nil
+}
+
+// Decode parses a complete JSON document from the input, and returns its value in the same
+// form as encoding/json.
+func Decode(pi parse.Input) (interface{}, error) {
+	n, err := Parse(pi)
+	if err != nil {
+		return nil, err
+	}
+	return n.Interface(), nil
+}
+
+// Value captures a JSON value, and any whitespace which follows it, and returns a *Node.
+// It can be used to parse JSON embedded within another grammar.
+var Value parse.Function = value
+
+// value breaks the initialization cycle between values, arrays and objects.
+func value(pi parse.Input) parse.Result {
+	return anyValue(pi)
+}
+
+var anyValue parse.Function
+
+func init() {
+	anyValue = lexeme(parse.Any(object, array, stringValue, number, trueValue, falseValue, nullValue))
+}
+
+var document = parse.All(func(items []interface{}) (interface{}, bool) {
+	return items[1], true
+}, whitespace, Value, parse.EOF)
+
+var whitespace = parse.Span(parse.ClassOf(" \t\n\r"), 0, 0)
+
+// lexeme captures f followed by optional whitespace, and returns the item from f.
+func lexeme(f parse.Function) parse.Function {
+	return parse.All(first, f, whitespace)
+}
+
+func first(items []interface{}) (interface{}, bool) {
+	return items[0], true
+}
+
+// asNode creates a Node of the kind from a parse.Ranged item.
+func asNode(kind Kind, f parse.Function) parse.Function {
+	return parse.All(func(items []interface{}) (interface{}, bool) {
+		ranged := items[0].(parse.Ranged)
+		n := &Node{
+			Kind:  kind,
+			Range: ranged.Range,
+		}
+		switch v := ranged.Item.(type) {
+		case []*Node:
+			n.Elements = v
+		case []*Member:
+			n.Members = v
+		default:
+			n.Value = v
+		}
+		return n, true
+	}, parse.WithRange(f))
+}
+
+func literal(s string, kind Kind, v interface{}) parse.Function {
+	return asNode(kind, parse.All(func([]interface{}) (interface{}, bool) {
+		return v, true
+	}, parse.String(s)))
+}
+
+var trueValue = literal("true", Bool, true)
+var falseValue = literal("false", Bool, false)
+var nullValue = literal("null", Null, nil)
+
+var stringLiteral = parse.StringLiteral(parse.StringLiteralOptions{
+	Quotes:               `"`,
+	Escape:               '\\',
+	Escapes:              `"\/bfnrtu`,
+	DisallowControlRunes: true,
+})
+
+var stringValue = asNode(String, stringLiteral)
+
+var digit = parse.ClassRange('0', '9')
+var digits = parse.Span(digit, 1, 0)
+
+var numberText = parse.All(parse.WithStringConcatCombiner,
+	parse.Optional(parse.WithStringConcatCombiner, parse.Rune('-')),
+	parse.Or(
+		parse.Rune('0'),
+		parse.Then(parse.WithStringConcatCombiner, parse.RuneInClass(parse.ClassRange('1', '9')), parse.Span(digit, 0, 0)),
+	),
+	parse.Optional(parse.WithStringConcatCombiner, parse.Then(parse.WithStringConcatCombiner, parse.Rune('.'), digits)),
+	parse.Optional(parse.WithStringConcatCombiner, parse.All(parse.WithStringConcatCombiner,
+		parse.RuneIn("eE"),
+		parse.Optional(parse.WithStringConcatCombiner, parse.RuneIn("+-")),
+		digits,
+	)),
+)
+
+var number = asNode(Number, numberValue)
+
+// numberValue converts the number to a float64, returning a positioned error if it's out of
+// range.
+func numberValue(pi parse.Input) parse.Result {
+	pos := parse.PosOf(pi)
+	r := numberText(pi)
+	if !r.Success {
+		return r
+	}
+	f, err := strconv.ParseFloat(r.Item.(string), 64)
+	if err != nil {
+		return parse.Failure("number", &parse.Error{Pos: pos, Err: err})
+	}
+	return parse.Success("number", f, nil)
+}
+
+var comma = lexeme(parse.Rune(','))
+
+// separated captures zero or more of f, separated by commas, and returns them as a slice.
+func separated(f parse.Function, asSlice parse.MultipleResultCombiner) parse.Function {
+	return parse.Optional(func(items []interface{}) (interface{}, bool) {
+		if len(items) == 0 {
+			return asSlice(nil)
+		}
+		return items[0], true
+	}, parse.Then(func(items []interface{}) (interface{}, bool) {
+		rest := items[1].([]interface{})
+		return asSlice(append([]interface{}{items[0]}, rest...))
+	}, f, parse.Many(func(items []interface{}) (interface{}, bool) {
+		return items, true
+	}, 0, 0, parse.Then(func(items []interface{}) (interface{}, bool) {
+		return items[1], true
+	}, comma, f))))
+}
+
+var array = asNode(Array, parse.All(func(items []interface{}) (interface{}, bool) {
+	return items[1], true
+}, lexeme(parse.Rune('[')), separated(value, func(items []interface{}) (interface{}, bool) {
+	elements := make([]*Node, len(items))
+	for i, item := range items {
+		elements[i] = item.(*Node)
+	}
+	return elements, true
+}), parse.Rune(']')))
+
+var member = parse.All(func(items []interface{}) (interface{}, bool) {
+	name := items[0].(parse.Ranged)
+	return &Member{
+		Name:      name.Item.(string),
+		NameRange: name.Range,
+		Value:     items[2].(*Node),
+	}, true
+}, lexeme(parse.WithRange(stringLiteral)), lexeme(parse.Rune(':')), value)
+
+var object = asNode(Object, parse.All(func(items []interface{}) (interface{}, bool) {
+	return items[1], true
+}, lexeme(parse.Rune('{')), separated(member, func(items []interface{}) (interface{}, bool) {
+	members := make([]*Member, len(items))
+	for i, item := range items {
+		members[i] = item.(*Member)
+	}
+	return members, true
+}), parse.Rune('}')))
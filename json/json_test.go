@@ -0,0 +1,252 @@
+package json
+
+import (
+	stdjson "encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/parse"
+)
+
+// conformance contains documents from each part of RFC 8259. Each one is checked against
+// encoding/json, which must agree on whether it's valid, and on its value.
+var conformance = []string{
+	// Literals.
+	`true`,
+	`false`,
+	`null`,
+	` null `,
+	"\t\r\n null \n",
+	`nul`,
+	`True`,
+	`nulll`,
+	// Numbers.
+	`0`,
+	`-0`,
+	`123`,
+	`-123`,
+	`1.5`,
+	`-1.5e10`,
+	`1E-2`,
+	`1e+2`,
+	`0.000001`,
+	`1.7976931348623157e308`,
+	`01`,
+	`+1`,
+	`.5`,
+	`1.`,
+	`1e`,
+	`-`,
+	`0x10`,
+	`1_000`,
+	`Infinity`,
+	`NaN`,
+	// Strings.
+	`""`,
+	`"hello"`,
+	`"\"\\\/\b\f\n\r\t"`,
+	`"Aé你"`,
+	`"😀"`,
+	`"\uD83D"`,
+	`"\uDE00\uD83D"`,
+	`"你好"`,
+	`"\a"`,
+	`"\x41"`,
+	`"\u004"`,
+	`'single'`,
+	`"unterminated`,
+	"\"tab\tinside\"",
+	"\"new\nline\"",
+	"\"\u007f\"",
+	// Arrays.
+	`[]`,
+	`[ ]`,
+	`[1]`,
+	`[1,2,3]`,
+	` [ 1 , "two" , [ 3 ] , { "four" : 4 } ] `,
+	`[[[[[[[[[[]]]]]]]]]]`,
+	`[1,]`,
+	`[,1]`,
+	`[1 2]`,
+	`[1,,2]`,
+	`[`,
+	`]`,
+	`[1`,
+	// Objects.
+	`{}`,
+	`{ }`,
+	`{"a":1}`,
+	`{"a":1,"b":[true,false,null],"c":{"d":"e"}}`,
+	`{"a":1,"a":2}`,
+	`{"":0}`,
+	`{"a":1,}`,
+	`{a:1}`,
+	`{"a" 1}`,
+	`{"a":}`,
+	`{1:1}`,
+	`{"a":1 "b":2}`,
+	`{`,
+	// Documents.
+	``,
+	` `,
+	`1 2`,
+	`{} {}`,
+	`[] x`,
+}
+
+func TestConformance(t *testing.T) {
+	for _, doc := range conformance {
+		var expected interface{}
+		expectedErr := stdjson.Unmarshal([]byte(doc), &expected)
+		actual, actualErr := Decode(input.NewFromString(doc))
+		if (expectedErr == nil) != (actualErr == nil) {
+			t.Errorf("for %q, encoding/json returned error %v, but got %v", doc, expectedErr, actualErr)
+			continue
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("for %q, expected %#v, got %#v", doc, expected, actual)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    `[1, 2`,
+			expected: "line 1, column 5: unexpected end of input",
+		},
+		{
+			input:    "{\n  \"a\": tru\n}",
+			expected: "line 2, column 10: unexpected '\\n'",
+		},
+		{
+			input:    `[1, 2,]`,
+			expected: "line 1, column 6: unexpected ']'",
+		},
+		{
+			input:    "[\n  \"a\\qb\"\n]",
+			expected: `line 2, column 4: invalid escape sequence "\\q"`,
+		},
+		{
+			input:    `{"a": 1e999}`,
+			expected: `line 1, column 6: strconv.ParseFloat: parsing "1e999": value out of range`,
+		},
+		{
+			input:    `{"a": 1} x`,
+			expected: "line 1, column 9: unexpected 'x'",
+		},
+		{
+			input:    "",
+			expected: "line 1, column 0: unexpected end of input",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(input.NewFromString(test.input))
+		if err == nil {
+			t.Errorf("for %q, expected error %q, got nil", test.input, test.expected)
+			continue
+		}
+		if _, ok := err.(*parse.Error); !ok {
+			t.Errorf("for %q, expected *parse.Error, got %T", test.input, err)
+		}
+		if err.Error() != test.expected {
+			t.Errorf("for %q, expected error %q, got %q", test.input, test.expected, err.Error())
+		}
+	}
+}
+
+func TestParseRanges(t *testing.T) {
+	doc := "{\n  \"name\": \"lexical\",\n  \"tags\": [1, true]\n}"
+	n, err := Parse(input.NewFromString(doc))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n.Kind != Object || len(n.Members) != 2 {
+		t.Fatalf("expected an object with 2 members, got %v with %d", n.Kind, len(n.Members))
+	}
+	expectRange(t, "object", n.Range, 0, 1, 0, 44, 4, 1)
+
+	name := n.Members[0]
+	if name.Name != "name" || name.Value.Kind != String || name.Value.Value != "lexical" {
+		t.Errorf("unexpected member: %+v", name)
+	}
+	expectRange(t, "name", name.NameRange, 4, 2, 2, 10, 2, 8)
+	expectRange(t, "name value", name.Value.Range, 12, 2, 10, 21, 2, 19)
+
+	tags := n.Members[1].Value
+	if tags.Kind != Array || len(tags.Elements) != 2 {
+		t.Fatalf("expected an array with 2 elements, got %v with %d", tags.Kind, len(tags.Elements))
+	}
+	expectRange(t, "tags", tags.Range, 33, 3, 10, 42, 3, 19)
+	if tags.Elements[0].Kind != Number || tags.Elements[0].Value != 1.0 {
+		t.Errorf("unexpected element: %+v", tags.Elements[0])
+	}
+	expectRange(t, "tags[0]", tags.Elements[0].Range, 34, 3, 11, 35, 3, 12)
+	if tags.Elements[1].Kind != Bool || tags.Elements[1].Value != true {
+		t.Errorf("unexpected element: %+v", tags.Elements[1])
+	}
+	expectRange(t, "tags[1]", tags.Elements[1].Range, 37, 3, 14, 41, 3, 18)
+}
+
+func expectRange(t *testing.T, name string, r parse.Range, startIndex int64, startLine, startCol int, endIndex int64, endLine, endCol int) {
+	t.Helper()
+	expected := parse.Range{
+		Start: parse.Pos{Index: startIndex, Line: startLine, Column: startCol},
+		End:   parse.Pos{Index: endIndex, Line: endLine, Column: endCol},
+	}
+	if r != expected {
+		t.Errorf("%s: expected range %+v, got %+v", name, expected, r)
+	}
+}
+
+func TestValueWithinGrammar(t *testing.T) {
+	assignment := parse.All(func(items []interface{}) (interface{}, bool) {
+		return items[2], true
+	}, parse.String("config"), parse.String(" = "), Value, parse.Rune(';'))
+	result := assignment(input.NewFromString(`config = {"debug": true} ;`))
+	if !result.Success {
+		t.Fatalf("expected success, got %v", result)
+	}
+	expected := map[string]interface{}{"debug": true}
+	if actual := result.Item.(*Node).Interface(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestKindString(t *testing.T) {
+	if Object.String() != "object" {
+		t.Errorf("expected 'object', got %q", Object.String())
+	}
+	if Kind(99).String() != "Kind(99)" {
+		t.Errorf("expected 'Kind(99)', got %q", Kind(99).String())
+	}
+}
+
+var benchmarkDocument = `{"id":1234,"name":"lexical","tags":["parser","combinator","go"],` +
+	`"owner":{"login":"a-h","site_admin":false},"score":99.5,"archived":null,` +
+	`"releases":[{"tag":"v0.0.1","assets":[]},{"tag":"v0.0.2","assets":[1,2,3]}]}`
+
+func BenchmarkDecode(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		if _, err := Decode(input.NewFromString(benchmarkDocument)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodingJSON(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		var v interface{}
+		if err := stdjson.NewDecoder(strings.NewReader(benchmarkDocument)).Decode(&v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package parse
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Class is a precompiled set of runes. Membership of ASCII runes is tested with a bitmap,
+// other runes are found by binary search of a sorted table of ranges, so a Class is much
+// faster to test than strings.ContainsRune or unicode.IsOneOf.
+//
+// A Class is immutable; the methods which combine classes return a new Class.
+type Class struct {
+	ascii  [2]uint64
+	ranges []runeRange
+}
+
+// runeRange is an inclusive range of runes.
+type runeRange struct {
+	lo, hi rune
+}
+
+// ClassOf creates a Class containing each of the runes in the set.
+func ClassOf(set string) Class {
+	var ranges []runeRange
+	for _, r := range set {
+		ranges = append(ranges, runeRange{lo: r, hi: r})
+	}
+	return newClass(ranges)
+}
+
+// ClassRange creates a Class containing all of the runes from lo to hi inclusive.
+func ClassRange(lo, hi rune) Class {
+	if lo > hi {
+		return Class{}
+	}
+	return newClass([]runeRange{{lo: lo, hi: hi}})
+}
+
+// ClassTable creates a Class containing all of the runes in the Unicode range tables, e.g.
+// ClassTable(unicode.Letter, unicode.Number).
+func ClassTable(tables ...*unicode.RangeTable) Class {
+	var ranges []runeRange
+	add := func(lo, hi, stride rune) {
+		if stride == 1 {
+			ranges = append(ranges, runeRange{lo: lo, hi: hi})
+			return
+		}
+		for r := lo; r <= hi; r += stride {
+			ranges = append(ranges, runeRange{lo: r, hi: r})
+		}
+	}
+	for _, t := range tables {
+		for _, r := range t.R16 {
+			add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+		}
+		for _, r := range t.R32 {
+			add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+		}
+	}
+	return newClass(ranges)
+}
+
+// Union returns a Class containing the runes which are in c or any of the other classes.
+func (c Class) Union(others ...Class) Class {
+	ranges := append([]runeRange{}, c.ranges...)
+	for _, o := range others {
+		ranges = append(ranges, o.ranges...)
+	}
+	return newClass(ranges)
+}
+
+// Not returns a Class containing all of the runes which are not in c.
+func (c Class) Not() Class {
+	var ranges []runeRange
+	next := rune(0)
+	for _, r := range c.ranges {
+		if r.lo > next {
+			ranges = append(ranges, runeRange{lo: next, hi: r.lo - 1})
+		}
+		next = r.hi + 1
+	}
+	if next <= unicode.MaxRune {
+		ranges = append(ranges, runeRange{lo: next, hi: unicode.MaxRune})
+	}
+	return newClass(ranges)
+}
+
+// Intersect returns a Class containing the runes which are in both c and o.
+func (c Class) Intersect(o Class) Class {
+	return c.Not().Union(o.Not()).Not()
+}
+
+// Minus returns a Class containing the runes which are in c, but not in o.
+func (c Class) Minus(o Class) Class {
+	return c.Intersect(o.Not())
+}
+
+// Contains returns true if the rune is within the class.
+func (c Class) Contains(r rune) bool {
+	if 0 <= r && r < 0x80 {
+		return c.ascii[r>>6]&(1<<(uint(r)&63)) != 0
+	}
+	i := sort.Search(len(c.ranges), func(i int) bool { return c.ranges[i].hi >= r })
+	return i < len(c.ranges) && c.ranges[i].lo <= r
+}
+
+// Ranges returns the inclusive ranges of runes in the class, in order, e.g. {{'0', '9'},
+// {'a', 'z'}} for ClassRange('a', 'z').Union(ClassRange('0', '9')).
+func (c Class) Ranges() [][2]rune {
+	ranges := make([][2]rune, len(c.ranges))
+	for i, r := range c.ranges {
+		ranges[i] = [2]rune{r.lo, r.hi}
+	}
+	return ranges
+}
+
+// String returns the class in the style of a regular expression character class.
+func (c Class) String() string {
+	var sb strings.Builder
+	sb.WriteRune('[')
+	for _, r := range c.ranges {
+		sb.WriteString(classRune(r.lo))
+		if r.hi > r.lo {
+			sb.WriteRune('-')
+			sb.WriteString(classRune(r.hi))
+		}
+	}
+	sb.WriteRune(']')
+	return sb.String()
+}
+
+func classRune(r rune) string {
+	if strings.ContainsRune(`\]-^`, r) {
+		return `\` + string(r)
+	}
+	if unicode.IsPrint(r) {
+		return string(r)
+	}
+	if r <= 0xFFFF {
+		return fmt.Sprintf(`\u%04X`, r)
+	}
+	return fmt.Sprintf(`\U%08X`, r)
+}
+
+// newClass sorts and merges the ranges, and builds the ASCII bitmap.
+func newClass(ranges []runeRange) (c Class) {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].lo < ranges[j].lo })
+	for _, r := range ranges {
+		if n := len(c.ranges); n > 0 && r.lo <= c.ranges[n-1].hi+1 {
+			if r.hi > c.ranges[n-1].hi {
+				c.ranges[n-1].hi = r.hi
+			}
+			continue
+		}
+		c.ranges = append(c.ranges, r)
+	}
+	for _, r := range c.ranges {
+		for a := r.lo; a <= r.hi && a < 0x80; a++ {
+			c.ascii[a>>6] |= 1 << (uint(a) & 63)
+		}
+	}
+	return c
+}
+
+// RuneInClass captures a rune if it's within the class.
+func RuneInClass(c Class) Function {
+	name := "any rune in class"
+	if len(c.ranges) <= 8 {
+		name = "any rune in " + c.String()
+	}
+	return func(pi Input) Result {
+		return runeWhere(pi, name, c.Contains)
+	}
+}
+
+// Span captures a run of runes which are within the class, at least atLeast times, and at
+// most atMost times (or unlimited if atMost is less than one), and returns them as a string.
+// It's equivalent to Many(WithStringConcatCombiner, atLeast, atMost, RuneInClass(c)), but
+// doesn't create a Result for each rune.
+func Span(c Class, atLeast, atMost int) Function {
+	return func(pi Input) Result {
+		return span(pi, c, atLeast, atMost)
+	}
+}
+
+func span(pi Input, c Class, atLeast, atMost int) Result {
+	start := pi.Index()
+	var sb strings.Builder
+	var count int
+	var err error
+	for atMost < 1 || count < atMost {
+		var r rune
+		r, err = pi.Advance()
+		if err != nil {
+			break
+		}
+		if !c.Contains(r) {
+			pi.Retreat()
+			break
+		}
+		sb.WriteRune(r)
+		count++
+	}
+	// Step back from the end of the input if it was reached.
+	rewind(pi, int(pi.Index()-start)-count)
+	if count < atLeast {
+		rewind(pi, int(pi.Index()-start))
+		return Failure("span of class", err)
+	}
+	return Success("span of class", sb.String(), nil)
+}
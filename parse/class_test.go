@@ -0,0 +1,254 @@
+package parse
+
+import (
+	"io"
+	"reflect"
+	"testing"
+	"unicode"
+
+	"github.com/a-h/lexical/input"
+)
+
+func TestClassContains(t *testing.T) {
+	hexDigits := ClassRange('0', '9').Union(ClassRange('a', 'f'), ClassRange('A', 'F'))
+	tests := []struct {
+		name     string
+		class    Class
+		in       string
+		notIn    string
+		expected string
+	}{
+		{
+			name:     "set",
+			class:    ClassOf("cab"),
+			in:       "abc",
+			notIn:    "dABC\x00爱",
+			expected: "[a-c]",
+		},
+		{
+			name:     "range",
+			class:    ClassRange('0', '9'),
+			in:       "0123456789",
+			notIn:    "/:a٣",
+			expected: "[0-9]",
+		},
+		{
+			name:     "empty range",
+			class:    ClassRange('9', '0'),
+			notIn:    "0123456789",
+			expected: "[]",
+		},
+		{
+			name:     "union",
+			class:    hexDigits,
+			in:       "09afAF",
+			notIn:    "gG-",
+			expected: "[0-9A-Fa-f]",
+		},
+		{
+			name:     "negation",
+			class:    ClassOf("\"\\").Not(),
+			in:       "a '爱\U0010FFFF",
+			notIn:    "\"\\",
+			expected: `[\u0000-!#-[\]-\U0010FFFF]`,
+		},
+		{
+			name:  "double negation",
+			class: ClassOf("abc").Not().Not(),
+			in:    "abc",
+			notIn: "d",
+		},
+		{
+			name:  "unicode table",
+			class: ClassTable(unicode.Letter, unicode.Nd),
+			in:    "aZ爱ǅ9٣",
+			notIn: " _-Ⅰ",
+		},
+		{
+			name:  "intersection",
+			class: ClassTable(unicode.Letter).Intersect(ClassRange(0, 0x7F)),
+			in:    "azAZ",
+			notIn: "爱0",
+		},
+		{
+			name:     "minus",
+			class:    hexDigits.Minus(ClassRange('0', '9')),
+			in:       "abcdefABCDEF",
+			notIn:    "0123456789g",
+			expected: "[A-Fa-f]",
+		},
+	}
+
+	for _, test := range tests {
+		for _, r := range test.in {
+			if !test.class.Contains(r) {
+				t.Errorf("%v: expected %q to be in the class", test.name, r)
+			}
+		}
+		for _, r := range test.notIn {
+			if test.class.Contains(r) {
+				t.Errorf("%v: expected %q not to be in the class", test.name, r)
+			}
+		}
+		if test.expected != "" && test.class.String() != test.expected {
+			t.Errorf("%v: expected class %v, got %v", test.name, test.expected, test.class.String())
+		}
+	}
+}
+
+func TestClassRanges(t *testing.T) {
+	c := ClassRange('a', 'z').Union(ClassOf("_0123456789"))
+	expected := [][2]rune{{'0', '9'}, {'_', '_'}, {'a', 'z'}}
+	if actual := c.Ranges(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected ranges %q, got %q", expected, actual)
+	}
+}
+
+func TestClassTableMatchesUnicode(t *testing.T) {
+	tables := []*unicode.RangeTable{unicode.Letter, unicode.Number, unicode.White_Space, unicode.Lu}
+	for _, table := range tables {
+		c := ClassTable(table)
+		for r := rune(0); r <= unicode.MaxRune; r++ {
+			if c.Contains(r) != unicode.Is(table, r) {
+				t.Fatalf("class and unicode table disagree on %q", r)
+			}
+		}
+	}
+}
+
+func TestRuneInClass(t *testing.T) {
+	tests := []struct {
+		input         string
+		expected      bool
+		expectedError error
+		expectedIndex int64
+	}{
+		{
+			input:         "a",
+			expected:      true,
+			expectedIndex: 1,
+		},
+		{
+			input:         "_",
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "",
+			expected:      false,
+			expectedError: io.EOF,
+			expectedIndex: 1,
+		},
+	}
+
+	for i, test := range tests {
+		pi := input.NewFromString(test.input)
+		result := RuneInClass(ClassRange('a', 'z'))(pi)
+		if result.Success != test.expected {
+			t.Errorf("test %v: for input '%v' expected %v but got %v", i, test.input, test.expected, result.Success)
+		}
+		if result.Error != test.expectedError {
+			t.Errorf("test %v: for input '%v' expected error '%v' but got '%v'", i, test.input, test.expectedError, result.Error)
+		}
+		if test.expectedIndex != pi.Index() {
+			t.Errorf("test %v: for input '%v' expected index %d, got %d", i, test.input, test.expectedIndex, pi.Index())
+		}
+	}
+}
+
+func TestSpan(t *testing.T) {
+	digits := ClassRange('0', '9')
+	tests := []struct {
+		input         string
+		parser        Function
+		expected      bool
+		expectedItem  interface{}
+		expectedIndex int64
+	}{
+		{
+			input:         "123abc",
+			parser:        Span(digits, 1, 0),
+			expected:      true,
+			expectedItem:  "123",
+			expectedIndex: 3,
+		},
+		{
+			input:         "123",
+			parser:        Span(digits, 1, 0),
+			expected:      true,
+			expectedItem:  "123",
+			expectedIndex: 3,
+		},
+		{
+			input:         "12345",
+			parser:        Span(digits, 1, 3),
+			expected:      true,
+			expectedItem:  "123",
+			expectedIndex: 3,
+		},
+		{
+			input:         "abc",
+			parser:        Span(digits, 0, 0),
+			expected:      true,
+			expectedItem:  "",
+			expectedIndex: 0,
+		},
+		{
+			input:         "abc",
+			parser:        Span(digits, 1, 0),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "12",
+			parser:        Span(digits, 3, 3),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "12a",
+			parser:        Span(digits, 3, 3),
+			expected:      false,
+			expectedIndex: 0,
+		},
+	}
+
+	for i, test := range tests {
+		pi := input.NewFromString(test.input)
+		result := test.parser(pi)
+		if result.Success != test.expected {
+			t.Errorf("test %v: for input '%v' expected %v but got %v", i, test.input, test.expected, result.Success)
+		}
+		if test.expected && result.Item != test.expectedItem {
+			t.Errorf("test %v: for input '%v' expected item '%v' but got '%v'", i, test.input, test.expectedItem, result.Item)
+		}
+		if test.expectedIndex != pi.Index() {
+			t.Errorf("test %v: for input '%v' expected index %d, got %d", i, test.input, test.expectedIndex, pi.Index())
+		}
+	}
+}
+
+func BenchmarkClassContains(b *testing.B) {
+	b.ReportAllocs()
+	c := ClassTable(unicode.Letter)
+	for n := 0; n < b.N; n++ {
+		c.Contains('a')
+		c.Contains('爱')
+	}
+}
+
+func BenchmarkSpan(b *testing.B) {
+	b.ReportAllocs()
+	parser := Span(ClassTable(unicode.Letter), 1, 0)
+	for n := 0; n < b.N; n++ {
+		parser(input.NewFromString("ABCDEFGHIJKLMNOPQRSTUVWXYZ"))
+	}
+}
+
+func BenchmarkSpanMany(b *testing.B) {
+	b.ReportAllocs()
+	parser := Many(WithStringConcatCombiner, 1, 0, Letter)
+	for n := 0; n < b.N; n++ {
+		parser(input.NewFromString("ABCDEFGHIJKLMNOPQRSTUVWXYZ"))
+	}
+}
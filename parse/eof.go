@@ -0,0 +1,15 @@
+package parse
+
+import "io"
+
+// EOF captures the end of the input, without consuming anything. It's used to check that
+// there's nothing left over after a parser has finished, e.g. All(combiner, document, EOF).
+var EOF Function = eof
+
+func eof(pi Input) Result {
+	_, err := peek(pi)
+	if err == io.EOF {
+		return Success("eof", nil, nil)
+	}
+	return Failure("eof", nil)
+}
@@ -0,0 +1,38 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/a-h/lexical/input"
+)
+
+func TestEOF(t *testing.T) {
+	tests := []struct {
+		input         string
+		expected      bool
+		expectedIndex int64
+	}{
+		{
+			input:         "AB",
+			expected:      true,
+			expectedIndex: 2,
+		},
+		{
+			input:         "ABC",
+			expected:      false,
+			expectedIndex: 2,
+		},
+	}
+
+	for i, test := range tests {
+		pi := input.NewFromString(test.input)
+		String("AB")(pi)
+		result := EOF(pi)
+		if result.Success != test.expected {
+			t.Errorf("test %v: for input '%v' expected %v but got %v", i, test.input, test.expected, result.Success)
+		}
+		if test.expectedIndex != pi.Index() {
+			t.Errorf("test %v: for input '%v' expected index %d, got %d", i, test.input, test.expectedIndex, pi.Index())
+		}
+	}
+}
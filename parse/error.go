@@ -0,0 +1,52 @@
+package parse
+
+import "fmt"
+
+// Pos is a position within the input.
+type Pos struct {
+	// Index is the number of runes before the position.
+	Index int64
+	// Line and Column are the line and column numbers as returned by Input.Position.
+	Line   int
+	Column int
+}
+
+// PosOf returns the current position of the input.
+func PosOf(pi Input) Pos {
+	line, col := pi.Position()
+	return Pos{
+		Index:  pi.Index(),
+		Line:   line,
+		Column: col,
+	}
+}
+
+// String returns the line and column of the position.
+func (p Pos) String() string {
+	return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+}
+
+// Error is an error at a position within the input, e.g. an invalid escape sequence within
+// a string, or a number which is out of range.
+type Error struct {
+	Pos Pos
+	Err error
+}
+
+// Errorf creates an Error at the position, formatting the message in the same way as
+// fmt.Errorf.
+func Errorf(pos Pos, format string, args ...interface{}) *Error {
+	return &Error{
+		Pos: pos,
+		Err: fmt.Errorf(format, args...),
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Pos.String() + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,34 @@
+package parse
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+)
+
+func TestError(t *testing.T) {
+	pi := input.NewFromString("ab\ncd")
+	pi.Advance()
+	pi.Advance()
+	pi.Advance()
+	pi.Advance()
+	err := &Error{Pos: PosOf(pi), Err: io.ErrUnexpectedEOF}
+	if err.Pos.Index != 4 {
+		t.Errorf("expected index 4, got %d", err.Pos.Index)
+	}
+	if err.Error() != "line 2, column 1: unexpected EOF" {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected the underlying error to be unwrapped")
+	}
+}
+
+func TestErrorf(t *testing.T) {
+	err := Errorf(Pos{Line: 3, Column: 7}, "invalid escape %q", `\q`)
+	if err.Error() != `line 3, column 7: invalid escape "\\q"` {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+}
@@ -51,6 +51,22 @@ func Failure(name string, err error) Result {
 	}
 }
 
+// Named captures f, and sets the name of its result, e.g. so that a scanner can use it as the
+// type of a token. Named parsers are the rules of a grammar which are recorded when a Grammar
+// is Tracing or Profiling.
+func Named(name string, f Function) Function {
+	return func(pi Input) Result {
+		var r Result
+		if run := RunOf(pi); run != nil && run.observed() {
+			r = run.call(name, f)
+		} else {
+			r = f(pi)
+		}
+		r.Name = name
+		return r
+	}
+}
+
 // Eq compares two results for equality.
 func (result Result) Eq(cmp Result) bool {
 	if cmp.Name != result.Name {
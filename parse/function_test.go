@@ -1,7 +1,11 @@
 package parse
 
-import "testing"
-import "io"
+import (
+	"io"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+)
 
 func TestResultEq(t *testing.T) {
 	tests := []struct {
@@ -83,3 +87,15 @@ func BenchmarkResultString(b *testing.B) {
 		_ = f.String()
 	}
 }
+
+func TestNamed(t *testing.T) {
+	f := Named("digit", RuneIn("0123456789"))
+	r := f(input.NewFromString("1"))
+	if !r.Success || r.Name != "digit" || r.Item != '1' {
+		t.Errorf("expected a successful result named digit, got %v", r)
+	}
+	r = f(input.NewFromString("a"))
+	if r.Success || r.Name != "digit" {
+		t.Errorf("expected a failed result named digit, got %v", r)
+	}
+}
@@ -0,0 +1,58 @@
+package parse
+
+import "io"
+
+// FurthestInput wraps an Input, and records the furthest rune that's been read from it.
+//
+// Parsers roll back when they fail, so by the time a parser has failed, there's no record
+// of where the input stopped matching. However, the furthest rune that any parser read is
+// usually the one which caused the failure, so FurthestInput can be used to report it.
+type FurthestInput struct {
+	Input
+	pos  Pos
+	r    rune
+	err  error
+	read bool
+}
+
+// NewFurthestInput creates a FurthestInput which reads from the input.
+func NewFurthestInput(pi Input) *FurthestInput {
+	return &FurthestInput{
+		Input: pi,
+	}
+}
+
+// Advance advances the input by a single rune and consumes it.
+func (fi *FurthestInput) Advance() (rune, error) {
+	pos := PosOf(fi.Input)
+	r, err := fi.Input.Advance()
+	if !fi.read || pos.Index > fi.pos.Index {
+		fi.pos, fi.r, fi.err, fi.read = pos, r, err, true
+	}
+	return r, err
+}
+
+// Peek returns the next rune from the input without consuming it.
+func (fi *FurthestInput) Peek() (rune, error) {
+	r, err := fi.Advance()
+	if err != nil {
+		return r, err
+	}
+	fi.Retreat()
+	return r, nil
+}
+
+// Unexpected returns an error which describes the furthest rune that's been read, e.g.
+// "line 1, column 5: unexpected 'x'".
+func (fi *FurthestInput) Unexpected() *Error {
+	if !fi.read {
+		return Errorf(PosOf(fi.Input), "unexpected end of input")
+	}
+	if fi.err == io.EOF {
+		return Errorf(fi.pos, "unexpected end of input")
+	}
+	if fi.err != nil {
+		return &Error{Pos: fi.pos, Err: fi.err}
+	}
+	return Errorf(fi.pos, "unexpected %q", fi.r)
+}
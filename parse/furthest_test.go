@@ -0,0 +1,51 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/a-h/lexical/input"
+)
+
+func TestFurthestInput(t *testing.T) {
+	list := All(WithStringConcatCombiner,
+		Rune('['),
+		Many(WithStringConcatCombiner, 0, 0, Any(RuneIn("0123456789"), RuneIn(", \n"))),
+		Rune(']'),
+	)
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    "[1,2,x]",
+			expected: "line 1, column 5: unexpected 'x'",
+		},
+		{
+			input:    "[1,2",
+			expected: "line 1, column 4: unexpected end of input",
+		},
+		{
+			input:    "",
+			expected: "line 1, column 0: unexpected end of input",
+		},
+		{
+			input:    "[1,\n2\n x]",
+			expected: "line 3, column 1: unexpected 'x'",
+		},
+	}
+
+	for i, test := range tests {
+		fi := NewFurthestInput(input.NewFromString(test.input))
+		result := list(fi)
+		if result.Success {
+			t.Errorf("test %v: for input '%v' expected failure", i, test.input)
+			continue
+		}
+		if fi.Index() != 0 {
+			t.Errorf("test %v: for input '%v' expected to be rolled back, but got index %d", i, test.input, fi.Index())
+		}
+		if actual := fi.Unexpected().Error(); actual != test.expected {
+			t.Errorf("test %v: for input '%v' expected '%v', got '%v'", i, test.input, test.expected, actual)
+		}
+	}
+}
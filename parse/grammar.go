@@ -0,0 +1,297 @@
+package parse
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Grammar is a compiled grammar, which can be used to parse many inputs at the same time, e.g. in
+// an HTTP server. Parser functions don't hold any state between calls, so they're safe to share
+// between goroutines, and any state that's needed during a parse, such as the results of Memo, is
+// held by the Run that each call to Parse creates.
+//
+// The fields of a Grammar mustn't be changed once it's in use.
+type Grammar struct {
+	// Start is the parser of the whole input.
+	Start Function
+	// MaxSteps limits the number of times that a run can advance the input, including over runes
+	// that are read again after backtracking, so that pathological input can't use an unbounded amount of CPU.
+	// When the limit is reached, the run fails with ErrStepLimit. Zero is unlimited.
+	MaxSteps int64
+	// NewState creates the user State of each run, e.g. a symbol table.
+	NewState func() interface{}
+	// Tracing records the Named parsers that each run tries in the run's Trace, e.g. to find out
+	// why a grammar doesn't match an input.
+	Tracing bool
+	// Profiling records the calls, input and time of each Named parser of each run in the run's
+	// Profile, e.g. to find out which alternatives of Any are tried the most.
+	Profiling bool
+}
+
+// Compile creates a Grammar which parses inputs with the start parser.
+func Compile(start Function) *Grammar {
+	return &Grammar{
+		Start: start,
+	}
+}
+
+// ErrStepLimit is the error of a run which has advanced more than the MaxSteps of its Grammar.
+var ErrStepLimit = errors.New("parse: the step limit of the grammar was reached")
+
+// Parse creates a Run over the input, and parses it with the Start parser. The run is returned so
+// that its State and Diagnostics can be read. If the context is cancelled, the run fails with the
+// context's error, and if the step limit is reached, it fails with ErrStepLimit, whatever the Start
+// parser returned.
+func (g *Grammar) Parse(ctx context.Context, pi Input) (Result, *Run) {
+	r := &Run{
+		Input:    pi,
+		grammar:  g,
+		ctx:      ctx,
+		start:    pi.Index(),
+		furthest: pi.Index(),
+		reach:    pi.Index(),
+	}
+	if g.NewState != nil {
+		r.State = g.NewState()
+	}
+	if g.Tracing {
+		r.Trace = &Trace{}
+	}
+	if g.Profiling {
+		r.Profile = newProfile()
+	}
+	result := g.Start(r)
+	if r.err != nil {
+		// A parser may have treated the error as a mismatch, and succeeded with part of the input.
+		return Failure(result.Name, r.err), r
+	}
+	return result, r
+}
+
+// Run is the state of one parse of a Grammar. It's the Input that the grammar's parsers receive,
+// so that they can use RunOf to reach the state.
+type Run struct {
+	Input
+	grammar *Grammar
+	ctx     context.Context
+	steps   int64
+	// err is the error of the step limit or the context, which is returned by every read of the
+	// input once it has happened, so that parsers which ignore it can't carry on.
+	err  error
+	memo map[memoKey]memoEntry
+	// start is the index that the run started at, furthest is the furthest index reached by the
+	// current Named parser, and reach is the furthest index reached by the run.
+	start, furthest, reach int64
+	// runes are the runes of the input from the start to the reach, which are kept while tracing.
+	runes []rune
+	// State is the user state of the run, created by the grammar's NewState.
+	State interface{}
+	// Diagnostics are the problems reported with Report, e.g. warnings which don't stop the parse.
+	Diagnostics []*Error
+	// Trace holds the events of the run if the grammar is Tracing.
+	Trace *Trace
+	// Profile holds the statistics of the run if the grammar is Profiling.
+	Profile *Profile
+}
+
+// RunOf returns the Run of the input, or nil if the input isn't being parsed by a Grammar.
+func RunOf(pi Input) *Run {
+	r, _ := pi.(*Run)
+	return r
+}
+
+// Report adds a diagnostic at the position. It does nothing if the run is nil, so parsers can call
+// RunOf(pi).Report without checking whether they're part of a Grammar.
+func (r *Run) Report(pos Pos, format string, args ...interface{}) {
+	if r == nil {
+		return
+	}
+	r.Diagnostics = append(r.Diagnostics, Errorf(pos, format, args...))
+}
+
+// Steps returns the number of times that the run has advanced the input.
+func (r *Run) Steps() int64 {
+	return r.steps
+}
+
+// checkEvery is the number of steps between checks of whether the context has been cancelled.
+const checkEvery = 256
+
+func (r *Run) step() error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.grammar.MaxSteps > 0 && r.steps >= r.grammar.MaxSteps {
+		r.err = ErrStepLimit
+		return r.err
+	}
+	if r.steps%checkEvery == 0 && r.ctx != nil {
+		if err := r.ctx.Err(); err != nil {
+			r.err = err
+			return r.err
+		}
+	}
+	r.steps++
+	return nil
+}
+
+// Peek returns the next rune of the input, unless the run's limit has been reached, or its
+// context has been cancelled.
+func (r *Run) Peek() (rune, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return r.Input.Peek()
+}
+
+// Advance advances the input by a single rune, unless the run's limit has been reached, or its
+// context has been cancelled.
+func (r *Run) Advance() (rune, error) {
+	if err := r.step(); err != nil {
+		return 0, err
+	}
+	c, err := r.Input.Advance()
+	if err == nil {
+		r.reached(c)
+	}
+	return c, err
+}
+
+// reached records that the run has advanced over the rune to the current index.
+func (r *Run) reached(c rune) {
+	i := r.Input.Index()
+	if i > r.furthest {
+		r.furthest = i
+	}
+	if i > r.reach {
+		r.reach = i
+		if r.Trace != nil {
+			r.runes = append(r.runes, c)
+		}
+	}
+}
+
+// PeekToken returns the next token of the input, which must be a TokenInput.
+func (r *Run) PeekToken() (Token, error) {
+	ti, ok := r.Input.(TokenInput)
+	if !ok {
+		return Token{}, ErrNotTokenInput
+	}
+	if r.err != nil {
+		return Token{}, r.err
+	}
+	return ti.PeekToken()
+}
+
+// AdvanceToken advances the input, which must be a TokenInput, by a single token.
+func (r *Run) AdvanceToken() (Token, error) {
+	ti, ok := r.Input.(TokenInput)
+	if !ok {
+		return Token{}, ErrNotTokenInput
+	}
+	if err := r.step(); err != nil {
+		return Token{}, err
+	}
+	t, err := ti.AdvanceToken()
+	if err == nil {
+		r.reached(firstRune(t))
+	}
+	return t, err
+}
+
+// observed returns true if the Named parsers of the run are traced or profiled.
+func (r *Run) observed() bool {
+	return r.Trace != nil || r.Profile != nil
+}
+
+// call runs the Named parser f, and records it in the run's Trace and Profile.
+func (r *Run) call(name string, f Function) Result {
+	start := PosOf(r)
+	furthest := r.furthest
+	r.furthest = start.Index
+	if r.Trace != nil {
+		r.Trace.enter(name, start)
+	}
+	var began time.Time
+	if r.Profile != nil {
+		began = r.Profile.enter(name)
+	}
+	result := f(r)
+	end := PosOf(r)
+	// A result returned by Memo advances the input without reading it again.
+	if end.Index > r.furthest {
+		r.furthest = end.Index
+	}
+	backtracked := r.furthest - end.Index
+	if r.Profile != nil {
+		r.Profile.exit(name, began, result.Success, end.Index-start.Index, backtracked)
+	}
+	if r.Trace != nil {
+		e := Event{
+			Name:        name,
+			Pos:         end,
+			Success:     result.Success,
+			Text:        r.text(start.Index, end.Index),
+			Backtracked: backtracked,
+		}
+		if result.Error != nil {
+			e.Error = result.Error.Error()
+		}
+		r.Trace.exit(e)
+	}
+	if furthest > r.furthest {
+		r.furthest = furthest
+	}
+	return result
+}
+
+// memo identifies the parser of a call to Memo. It isn't empty, so that each one has a distinct
+// address.
+type memo struct {
+	_ byte
+}
+
+type memoKey struct {
+	memo  *memo
+	index int64
+}
+
+type memoEntry struct {
+	result   Result
+	consumed int64
+}
+
+// Memo captures f, and remembers its result at each position of the input for the rest of the
+// Run, so that when backtracking results in f being tried again at the same position, the result
+// is returned without parsing the input again. This can turn an exponential grammar into a linear
+// one. Returning a remembered result advances over the input that it consumed, which counts
+// towards the MaxSteps of the Grammar in the same way as parsing it. Outside of a Grammar, f is
+// called every time.
+func Memo(f Function) Function {
+	m := &memo{}
+	return func(pi Input) Result {
+		r := RunOf(pi)
+		if r == nil {
+			return f(pi)
+		}
+		key := memoKey{memo: m, index: pi.Index()}
+		if e, ok := r.memo[key]; ok {
+			// Replaying the result advances over the runes that it consumed, so they count towards
+			// the run's MaxSteps.
+			for i := int64(0); i < e.consumed; i++ {
+				if _, err := r.Advance(); err != nil {
+					RewindTo(pi, key.index)
+					return Failure(e.result.Name, err)
+				}
+			}
+			return e.result
+		}
+		result := f(pi)
+		if r.memo == nil {
+			r.memo = make(map[memoKey]memoEntry)
+		}
+		r.memo[key] = memoEntry{result: result, consumed: pi.Index() - key.index}
+		return result
+	}
+}
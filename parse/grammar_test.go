@@ -0,0 +1,274 @@
+package parse
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a-h/lexical/input"
+)
+
+// sum parses sums of integers, e.g. "1+2+3", and records the integers in the run's state. Both
+// alternatives of the expression start with a term, so the term is tried twice at each position.
+func sum() (expr Function, calls *int64) {
+	calls = new(int64)
+	counted := func(pi Input) Result {
+		if RunOf(pi) == nil {
+			*calls++
+		}
+		return Int(10, 64)(pi)
+	}
+	term := Memo(func(pi Input) Result {
+		pos := PosOf(pi)
+		r := counted(pi)
+		if r.Success {
+			if v := r.Item.(int64); v > 100 {
+				RunOf(pi).Report(pos, "%d is greater than 100", v)
+			}
+			if run := RunOf(pi); run != nil {
+				s := run.State.(*[]int64)
+				*s = append(*s, r.Item.(int64))
+			}
+		}
+		return r
+	})
+	add := func(items []interface{}) (interface{}, bool) {
+		return items[0].(int64) + items[2].(int64), true
+	}
+	expr = Any(
+		All(add, term, Rune('+'), func(pi Input) Result { return expr(pi) }),
+		term,
+	)
+	return expr, calls
+}
+
+func TestGrammar(t *testing.T) {
+	expr, _ := sum()
+	g := Compile(All(func(items []interface{}) (interface{}, bool) { return items[0], true }, expr, EOF))
+	g.NewState = func() interface{} { return new([]int64) }
+	tests := []struct {
+		input       string
+		expected    int64
+		terms       []int64
+		diagnostics []string
+	}{
+		{
+			input:    "1",
+			expected: 1,
+			terms:    []int64{1},
+		},
+		{
+			input:    "1+2+3",
+			expected: 6,
+			terms:    []int64{1, 2, 3},
+		},
+		{
+			input:       "1+200",
+			expected:    201,
+			terms:       []int64{1, 200},
+			diagnostics: []string{"line 1, column 2: 200 is greater than 100"},
+		},
+	}
+	for i, test := range tests {
+		r, run := g.Parse(context.Background(), input.NewFromString(test.input))
+		if !r.Success || r.Item != test.expected {
+			t.Errorf("test %v: for input '%v' expected %v, got %v", i, test.input, test.expected, r)
+		}
+		// The memoized term is only parsed once at each position, so each term is only recorded once.
+		if terms := *run.State.(*[]int64); fmt.Sprint(terms) != fmt.Sprint(test.terms) {
+			t.Errorf("test %v: for input '%v' expected terms %v, got %v", i, test.input, test.terms, terms)
+		}
+		var diagnostics []string
+		for _, d := range run.Diagnostics {
+			diagnostics = append(diagnostics, d.Error())
+		}
+		if fmt.Sprint(diagnostics) != fmt.Sprint(test.diagnostics) {
+			t.Errorf("test %v: for input '%v' expected diagnostics %v, got %v", i, test.input, test.diagnostics, diagnostics)
+		}
+	}
+}
+
+func TestMemo(t *testing.T) {
+	expr, calls := sum()
+	// Outside of a grammar, the term is parsed by both alternatives.
+	if r := expr(input.NewFromString("1+2")); !r.Success || r.Item != int64(3) {
+		t.Fatalf("expected 3, got %v", r)
+	}
+	if *calls != 3 {
+		t.Errorf("expected the term to be parsed 3 times without a grammar, got %d", *calls)
+	}
+}
+
+func TestMemoCountsSteps(t *testing.T) {
+	// The memoized string is parsed by the first alternative, which then fails at the end of the
+	// input, and is replayed by the second.
+	m := Memo(String("aaaa"))
+	p := Any(All(WithStringConcatCombiner, m, Rune('b')), m)
+	g := Compile(p)
+	r, run := g.Parse(context.Background(), input.NewFromString("aaaa"))
+	if !r.Success || r.Item != "aaaa" {
+		t.Fatalf("expected success, got %v", r)
+	}
+	if run.Steps() != 8 {
+		t.Errorf("expected the replayed runes to be counted, for 8 steps, got %d", run.Steps())
+	}
+	g.MaxSteps = 7
+	r, run = g.Parse(context.Background(), input.NewFromString("aaaa"))
+	if r.Success || r.Error != ErrStepLimit {
+		t.Errorf("expected ErrStepLimit, got %v", r)
+	}
+	if run.Steps() != 7 {
+		t.Errorf("expected 7 steps, got %d", run.Steps())
+	}
+}
+
+func TestGrammarLimits(t *testing.T) {
+	g := Compile(Times(WithStringConcatCombiner, 10, AnyRune()))
+	g.MaxSteps = 10
+	r, run := g.Parse(context.Background(), input.NewFromString(strings.Repeat("a", 10)))
+	if !r.Success || r.Item != strings.Repeat("a", 10) {
+		t.Errorf("expected the input within the limit to be parsed, got %v", r)
+	}
+	if run.Steps() != 10 {
+		t.Errorf("expected 10 steps, got %d", run.Steps())
+	}
+	g.MaxSteps = 9
+	r, _ = g.Parse(context.Background(), input.NewFromString(strings.Repeat("a", 10)))
+	if r.Success || r.Error != ErrStepLimit {
+		t.Errorf("expected ErrStepLimit, got %v", r)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r, _ = Compile(g.Start).Parse(ctx, input.NewFromString("abc"))
+	if r.Success || r.Error != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", r)
+	}
+	r, _ = Compile(Many(WithStringConcatCombiner, 0, 0, RuneIn("a"))).Parse(ctx, input.NewFromString("aaa"))
+	if r.Success || r.Error != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", r)
+	}
+
+	tests := []struct {
+		parser   Function
+		input    string
+		maxSteps int64
+		expected Result
+	}{
+		{
+			parser:   Many(WithStringConcatCombiner, 0, 0, RuneIn("a")),
+			input:    strings.Repeat("a", 10),
+			maxSteps: 5,
+			expected: Failure("any rune in 'a'", ErrStepLimit),
+		},
+		{
+			parser:   Many(WithStringConcatCombiner, 0, 0, RuneIn("a")),
+			input:    strings.Repeat("a", 5),
+			maxSteps: 5,
+			expected: Success("many", "aaaaa", nil),
+		},
+		{
+			parser:   Many(WithStringConcatCombiner, 0, 0, String("ab")),
+			input:    strings.Repeat("ab", 5),
+			maxSteps: 7,
+			expected: Failure("string: 'ab'", ErrStepLimit),
+		},
+		{
+			parser:   Many(WithStringConcatCombiner, 0, 0, String("ab")),
+			input:    strings.Repeat("ab", 5),
+			maxSteps: 10,
+			expected: Success("many", "ababababab", nil),
+		},
+		{
+			parser:   Many(WithStringConcatCombiner, 0, 0, Rune('a')),
+			input:    strings.Repeat("a", 10),
+			maxSteps: 3,
+			expected: Failure("rune 'a'", ErrStepLimit),
+		},
+	}
+	for i, test := range tests {
+		g := Compile(test.parser)
+		g.MaxSteps = test.maxSteps
+		done := make(chan Result)
+		go func() {
+			r, _ := g.Parse(context.Background(), input.NewFromString(test.input))
+			done <- r
+		}()
+		select {
+		case r := <-done:
+			if !reflect.DeepEqual(r, test.expected) {
+				t.Errorf("test %v: for input '%v' expected %v, but got %v", i, test.input, test.expected, r)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("test %v: for input '%v' the parse didn't stop at the step limit", i, test.input)
+		}
+	}
+}
+
+func TestGrammarTokenInput(t *testing.T) {
+	g := Compile(Many(WithStringConcatCombiner, 1, 0, TokenOfType("word")))
+	r, run := g.Parse(context.Background(), NewTokenStreamFromSlice([]Token{
+		{Type: "word", Text: "a"},
+		{Type: "word", Text: "b"},
+	}))
+	if !r.Success || run.Steps() != 2 {
+		t.Errorf("expected 2 tokens to be parsed in 2 steps, got %v in %d steps", r, run.Steps())
+	}
+	r, _ = g.Parse(context.Background(), input.NewFromString("a"))
+	if r.Success || r.Error != ErrNotTokenInput {
+		t.Errorf("expected ErrNotTokenInput, got %v", r)
+	}
+}
+
+func TestGrammarConcurrentRuns(t *testing.T) {
+	expr, _ := sum()
+	g := Compile(All(func(items []interface{}) (interface{}, bool) { return items[0], true }, expr, EOF))
+	g.NewState = func() interface{} { return new([]int64) }
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for w := 0; w < 16; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for n := 1; n < 50; n++ {
+				terms := make([]string, n)
+				for i := range terms {
+					terms[i] = fmt.Sprint(w + i)
+				}
+				var expected int64
+				for i := 0; i < n; i++ {
+					expected += int64(w + i)
+				}
+				r, run := g.Parse(context.Background(), input.NewFromString(strings.Join(terms, "+")))
+				if !r.Success || r.Item != expected || len(*run.State.(*[]int64)) != n {
+					errs <- fmt.Errorf("worker %d: for %d terms expected %d, got %v with state %v", w, n, expected, r, *run.State.(*[]int64))
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func BenchmarkGrammar(b *testing.B) {
+	b.ReportAllocs()
+	expr, _ := sum()
+	g := Compile(expr)
+	g.NewState = func() interface{} { return new([]int64) }
+	src := strings.Repeat("12+", 50) + "1"
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if r, _ := g.Parse(context.Background(), input.NewFromString(src)); !r.Success {
+				b.Fatal(r)
+			}
+		}
+	})
+}
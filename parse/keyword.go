@@ -0,0 +1,143 @@
+package parse
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Keyword captures a specific string, as long as it isn't immediately followed by a rune
+// which could continue an identifier, e.g. Keyword("if") matches "if (" but not "iffy".
+func Keyword(s string) Function {
+	return KeywordWithBoundary(s, IsIdentifierContinue)
+}
+
+// KeywordWithBoundary captures a specific string, as long as the following rune doesn't
+// match the isIdentifierRune predicate. The end of the input is always a valid boundary.
+func KeywordWithBoundary(s string, isIdentifierRune func(r rune) bool) Function {
+	name := "keyword: '" + s + "'"
+	f := String(s)
+	return func(pi Input) Result {
+		return keyword(pi, name, f, isIdentifierRune)
+	}
+}
+
+// KeywordInsensitive captures a specific string, ignoring string casing, as long as it isn't
+// immediately followed by a rune which could continue an identifier.
+func KeywordInsensitive(s string) Function {
+	return KeywordInsensitiveWithBoundary(s, IsIdentifierContinue)
+}
+
+// KeywordInsensitiveWithBoundary captures a specific string, ignoring string casing, as
+// long as the following rune doesn't match the isIdentifierRune predicate.
+func KeywordInsensitiveWithBoundary(s string, isIdentifierRune func(r rune) bool) Function {
+	name := "keywordinsensitive: '" + s + "'"
+	f := StringInsensitive(s)
+	return func(pi Input) Result {
+		return keyword(pi, name, f, isIdentifierRune)
+	}
+}
+
+func keyword(pi Input, name string, f Function, isIdentifierRune func(r rune) bool) Result {
+	start := pi.Index()
+	r := f(pi)
+	if !r.Success {
+		rewind(pi, int(pi.Index()-start))
+		return Failure(name, r.Error)
+	}
+	if followedBy(pi, isIdentifierRune) {
+		rewind(pi, int(pi.Index()-start))
+		return Failure(name, nil)
+	}
+	return Success(name, r.Item, nil)
+}
+
+// followedBy returns true if the next rune in the input matches the predicate.
+func followedBy(pi Input, predicate func(r rune) bool) bool {
+	r, err := peek(pi)
+	return err == nil && predicate(r)
+}
+
+// Identifier captures a string which starts with a rune matching the start predicate,
+// followed by any number of runes matching the next predicate. If either predicate is nil,
+// IsIdentifierStart and IsIdentifierContinue are used. Identifiers which exactly match one of
+// the reserved words are rolled back.
+func Identifier(start, next func(r rune) bool, reserved ...string) Function {
+	return identifierParser("identifier", start, next, reservedWords(reserved, false))
+}
+
+// IdentifierInsensitive is the same as Identifier, except that reserved words are compared
+// without regard to string casing, e.g. "SELECT" and "select" are both rejected if "select"
+// is reserved.
+func IdentifierInsensitive(start, next func(r rune) bool, reserved ...string) Function {
+	return identifierParser("identifierinsensitive", start, next, reservedWords(reserved, true))
+}
+
+func reservedWords(reserved []string, insensitive bool) func(s string) bool {
+	words := make(map[string]struct{}, len(reserved))
+	for _, w := range reserved {
+		if insensitive {
+			w = strings.ToLower(w)
+		}
+		words[w] = struct{}{}
+	}
+	return func(s string) bool {
+		if insensitive {
+			s = strings.ToLower(s)
+		}
+		_, isReserved := words[s]
+		return isReserved
+	}
+}
+
+func identifierParser(name string, start, next func(r rune) bool, isReserved func(s string) bool) Function {
+	if start == nil {
+		start = IsIdentifierStart
+	}
+	if next == nil {
+		next = IsIdentifierContinue
+	}
+	return func(pi Input) Result {
+		return identifier(pi, name, start, next, isReserved)
+	}
+}
+
+func identifier(pi Input, name string, start, next func(r rune) bool, isReserved func(s string) bool) Result {
+	begin := pi.Index()
+	var sb strings.Builder
+	r, err := peek(pi)
+	if err != nil || !start(r) {
+		return Failure(name, err)
+	}
+	for err == nil && (sb.Len() == 0 || next(r)) {
+		pi.Advance()
+		sb.WriteRune(r)
+		r, err = peek(pi)
+	}
+	s := sb.String()
+	if isReserved(s) {
+		rewind(pi, int(pi.Index()-begin))
+		return Failure(name, nil)
+	}
+	return Success(name, s, nil)
+}
+
+// IsIdentifierStart returns true if the rune has the Unicode ID_Start property, i.e. it can
+// be the first rune of an identifier.
+func IsIdentifierStart(r rune) bool {
+	if r < 0x80 {
+		return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z'
+	}
+	return unicode.In(r, unicode.Letter, unicode.Nl, unicode.Other_ID_Start) &&
+		!unicode.In(r, unicode.Pattern_Syntax, unicode.Pattern_White_Space)
+}
+
+// IsIdentifierContinue returns true if the rune has the Unicode ID_Continue property, i.e.
+// it can be used after the first rune of an identifier.
+func IsIdentifierContinue(r rune) bool {
+	if r < 0x80 {
+		return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '_'
+	}
+	return unicode.In(r, unicode.Letter, unicode.Nl, unicode.Other_ID_Start,
+		unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc, unicode.Other_ID_Continue) &&
+		!unicode.In(r, unicode.Pattern_Syntax, unicode.Pattern_White_Space)
+}
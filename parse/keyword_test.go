@@ -0,0 +1,235 @@
+package parse
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/a-h/lexical/input"
+)
+
+func TestKeyword(t *testing.T) {
+	tests := []struct {
+		input         string
+		parser        Function
+		expected      bool
+		expectedItem  interface{}
+		expectedIndex int64
+	}{
+		{
+			input:         "if",
+			parser:        Keyword("if"),
+			expected:      true,
+			expectedItem:  "if",
+			expectedIndex: 2,
+		},
+		{
+			input:         "if (",
+			parser:        Keyword("if"),
+			expected:      true,
+			expectedItem:  "if",
+			expectedIndex: 2,
+		},
+		{
+			input:         "iffy",
+			parser:        Keyword("if"),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "if_",
+			parser:        Keyword("if"),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "if2",
+			parser:        Keyword("if"),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "i",
+			parser:        Keyword("if"),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "if-x",
+			parser:        KeywordWithBoundary("if", unicode.IsLetter),
+			expected:      true,
+			expectedItem:  "if",
+			expectedIndex: 2,
+		},
+		{
+			input:         "if2",
+			parser:        KeywordWithBoundary("if", unicode.IsLetter),
+			expected:      true,
+			expectedItem:  "if",
+			expectedIndex: 2,
+		},
+		{
+			input:         "SELECT *",
+			parser:        KeywordInsensitive("select"),
+			expected:      true,
+			expectedItem:  "select",
+			expectedIndex: 6,
+		},
+		{
+			input:         "SELECTED",
+			parser:        KeywordInsensitive("select"),
+			expected:      false,
+			expectedIndex: 0,
+		},
+	}
+
+	for i, test := range tests {
+		pi := input.NewFromString(test.input)
+		result := test.parser(pi)
+		actual := result.Success
+		if actual != test.expected {
+			t.Errorf("test %v: for input '%v' expected %v but got %v", i, test.input, test.expected, actual)
+		}
+		if test.expected && result.Item != test.expectedItem {
+			t.Errorf("test %v: for input '%v' expected item '%v' but got '%v'", i, test.input, test.expectedItem, result.Item)
+		}
+		if test.expectedIndex != pi.Index() {
+			t.Errorf("test %v: for input '%v' expected index %d, got %d", i, test.input, test.expectedIndex, pi.Index())
+		}
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	isLowerOrUnderscore := func(r rune) bool { return unicode.IsLower(r) || r == '_' }
+	tests := []struct {
+		input         string
+		parser        Function
+		expected      bool
+		expectedItem  interface{}
+		expectedIndex int64
+	}{
+		{
+			input:         "name",
+			parser:        Identifier(nil, nil),
+			expected:      true,
+			expectedItem:  "name",
+			expectedIndex: 4,
+		},
+		{
+			input:         "name2 = 1",
+			parser:        Identifier(nil, nil),
+			expected:      true,
+			expectedItem:  "name2",
+			expectedIndex: 5,
+		},
+		{
+			input:         "2name",
+			parser:        Identifier(nil, nil),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "",
+			parser:        Identifier(nil, nil),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "名前=1",
+			parser:        Identifier(nil, nil),
+			expected:      true,
+			expectedItem:  "名前",
+			expectedIndex: 2,
+		},
+		{
+			input:         "_private",
+			parser:        Identifier(nil, nil),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "_private",
+			parser:        Identifier(isLowerOrUnderscore, nil),
+			expected:      true,
+			expectedItem:  "_private",
+			expectedIndex: 8,
+		},
+		{
+			input:         "if",
+			parser:        Identifier(nil, nil, "if", "else"),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "iffy",
+			parser:        Identifier(nil, nil, "if", "else"),
+			expected:      true,
+			expectedItem:  "iffy",
+			expectedIndex: 4,
+		},
+		{
+			input:         "IF",
+			parser:        Identifier(nil, nil, "if", "else"),
+			expected:      true,
+			expectedItem:  "IF",
+			expectedIndex: 2,
+		},
+		{
+			input:         "IF",
+			parser:        IdentifierInsensitive(nil, nil, "if", "else"),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "Else ",
+			parser:        IdentifierInsensitive(nil, nil, "if", "ELSE"),
+			expected:      false,
+			expectedIndex: 0,
+		},
+	}
+
+	for i, test := range tests {
+		pi := input.NewFromString(test.input)
+		result := test.parser(pi)
+		actual := result.Success
+		if actual != test.expected {
+			t.Errorf("test %v: for input '%v' expected %v but got %v", i, test.input, test.expected, actual)
+		}
+		if test.expected && result.Item != test.expectedItem {
+			t.Errorf("test %v: for input '%v' expected item '%v' but got '%v'", i, test.input, test.expectedItem, result.Item)
+		}
+		if test.expectedIndex != pi.Index() {
+			t.Errorf("test %v: for input '%v' expected index %d, got %d", i, test.input, test.expectedIndex, pi.Index())
+		}
+	}
+}
+
+func TestIdentifierThenKeyword(t *testing.T) {
+	ifStatement := All(WithStringConcatCombiner,
+		Keyword("if"),
+		Rune(' '),
+		Identifier(nil, nil, "if"),
+	)
+	result := ifStatement(input.NewFromString("if iffy"))
+	if !result.Success {
+		t.Fatalf("expected success, got %v", result)
+	}
+	if result.Item != "if iffy" {
+		t.Errorf("expected 'if iffy', got '%v'", result.Item)
+	}
+}
+
+func BenchmarkKeyword(b *testing.B) {
+	b.ReportAllocs()
+	parser := Keyword("ABCDEFG")
+	for n := 0; n < b.N; n++ {
+		parser(input.NewFromString("ABCDEFG HIJKLMNOPQRSTUVWXYZ"))
+	}
+}
+
+func BenchmarkIdentifier(b *testing.B) {
+	b.ReportAllocs()
+	parser := Identifier(nil, nil, "if", "else")
+	for n := 0; n < b.N; n++ {
+		parser(input.NewFromString("ABCDEFG HIJKLMNOPQRSTUVWXYZ"))
+	}
+}
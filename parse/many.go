@@ -3,10 +3,13 @@ package parse
 import (
 	"errors"
 	"fmt"
+	"io"
 )
 
 // Many captures the function at least x times and at most y times and sets the
-// result item to an array of the function captures.
+// result item to an array of the function captures. If the function fails with an error
+// other than io.EOF, the input matched but was invalid, so Many rolls back all of its
+// captures and fails with the same error, rather than stopping at the error and succeeding.
 func Many(combiner MultipleResultCombiner, atLeast, atMost int, f Function) Function {
 	return func(pi Input) Result {
 		return many(pi, combiner, atLeast, atMost, f)
@@ -50,6 +53,11 @@ func many(pi Input, combiner MultipleResultCombiner, atLeast, atMost int, f Func
 		r := f(pi)
 		if !r.Success {
 			rewind(pi, int(pi.Index()-localRollback))
+			if r.Error != nil && r.Error != io.EOF {
+				// In the same way as Any, an error means that the input matched, but was invalid.
+				rewind(pi, int(pi.Index()-globalRollback))
+				return r
+			}
 			break
 		}
 		results = append(results, r.Item)
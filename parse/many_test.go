@@ -1,6 +1,7 @@
 package parse
 
 import (
+	"io"
 	"testing"
 
 	"github.com/a-h/lexical/input"
@@ -129,3 +130,59 @@ func BenchmarkMany(b *testing.B) {
 		parser(input.NewFromString("ABCDEFGHIJKLMNOPQRSTUVWXYZ"))
 	}
 }
+
+func TestManyErrorHandling(t *testing.T) {
+	numbers := Many(WithStringConcatCombiner, 0, 0, All(WithStringConcatCombiner, Int(10, 8), Rune(',')))
+	pi := input.NewFromString("1,2,300,4,")
+	result := numbers(pi)
+	if result.Success {
+		t.Fatalf("expected failure, got %v", result)
+	}
+	if result.Error == nil || result.Error.Error() != `line 1, column 4: strconv.ParseInt: parsing "300": value out of range` {
+		t.Errorf("expected the range error to be returned, got %v", result.Error)
+	}
+	if pi.Index() != 0 {
+		t.Errorf("expected to be rolled back to index 0, got %d", pi.Index())
+	}
+}
+
+func TestManyStopsAtFailuresWithoutErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "nil error",
+			err:  nil,
+		},
+		{
+			name: "EOF",
+			err:  io.EOF,
+		},
+	}
+	for i, test := range tests {
+		// Match 'A', otherwise fail with the test's error.
+		f := func(pi Input) Result {
+			start := pi.Index()
+			if r, err := pi.Advance(); err == nil && r == 'A' {
+				return Success("A", "A", nil)
+			}
+			rewind(pi, int(pi.Index()-start))
+			return Failure("A", test.err)
+		}
+		pi := input.NewFromString("AAB")
+		result := Many(WithStringConcatCombiner, 1, 0, f)(pi)
+		if !result.Success {
+			t.Errorf("test %v (%v): expected success, got %v", i, test.name, result)
+		}
+		if result.Item != "AA" {
+			t.Errorf("test %v (%v): expected item 'AA', got '%v'", i, test.name, result.Item)
+		}
+		if result.Error != nil {
+			t.Errorf("test %v (%v): expected no error, got %v", i, test.name, result.Error)
+		}
+		if pi.Index() != 2 {
+			t.Errorf("test %v (%v): expected index 2, got %d", i, test.name, pi.Index())
+		}
+	}
+}
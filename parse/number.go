@@ -0,0 +1,203 @@
+package parse
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Int captures a signed integer and returns it as an int64. The base may be from 2 to 36,
+// or zero, in which case a 0x, 0o or 0b prefix selects hexadecimal, octal or binary, and
+// the integer is decimal otherwise. Unlike Go, a leading zero doesn't imply octal.
+//
+// Underscores may be used to separate digits, e.g. 1_000_000. An integer which doesn't fit
+// into bitSize bits, or has misplaced underscores, results in a failure with an *Error that
+// contains the position of the integer.
+func Int(base, bitSize int) Function {
+	return func(pi Input) Result {
+		return parseInt(pi, base, bitSize, true)
+	}
+}
+
+// Uint captures an unsigned integer in the same way as Int, but returns a uint64, and
+// doesn't accept a sign.
+func Uint(base, bitSize int) Function {
+	return func(pi Input) Result {
+		return parseInt(pi, base, bitSize, false)
+	}
+}
+
+// Float captures a decimal floating point number, with an optional sign, fraction and
+// exponent, e.g. -1.5e10, and returns it as a float64. At least one digit is required
+// before and after the decimal point. Underscores may be used to separate digits.
+func Float(bitSize int) Function {
+	return func(pi Input) Result {
+		return parseFloat(pi, bitSize)
+	}
+}
+
+func parseInt(pi Input, base, bitSize int, signed bool) Result {
+	name := "int"
+	if !signed {
+		name = "uint"
+	}
+	start := pi.Index()
+	pos := PosOf(pi)
+	var sign string
+	if signed {
+		sign = acceptRune(pi, "+-")
+	}
+	digits, base, err := digitsWithPrefix(pi, pos, base)
+	if err != nil {
+		rewind(pi, int(pi.Index()-start))
+		return Failure(name, err)
+	}
+	if digits == "" {
+		rewind(pi, int(pi.Index()-start))
+		return Failure(name, nil)
+	}
+	var item interface{}
+	if signed {
+		item, err = strconv.ParseInt(sign+digits, base, bitSize)
+	} else {
+		item, err = strconv.ParseUint(digits, base, bitSize)
+	}
+	if err != nil {
+		rewind(pi, int(pi.Index()-start))
+		return Failure(name, &Error{Pos: pos, Err: err})
+	}
+	return Success(name, item, nil)
+}
+
+func parseFloat(pi Input, bitSize int) Result {
+	name := "float"
+	start := pi.Index()
+	pos := PosOf(pi)
+	var sb strings.Builder
+	sb.WriteString(acceptRune(pi, "+-"))
+	mantissa, err := digits(pi, pos, 10, false)
+	if err != nil || mantissa == "" {
+		rewind(pi, int(pi.Index()-start))
+		return Failure(name, err)
+	}
+	sb.WriteString(mantissa)
+	if fraction, err := optionalPart(pi, pos, ".", ""); err != nil {
+		rewind(pi, int(pi.Index()-start))
+		return Failure(name, err)
+	} else if fraction != "" {
+		sb.WriteString(fraction)
+	}
+	if exponent, err := optionalPart(pi, pos, "eE", "+-"); err != nil {
+		rewind(pi, int(pi.Index()-start))
+		return Failure(name, err)
+	} else if exponent != "" {
+		sb.WriteString(exponent)
+	}
+	f, err := strconv.ParseFloat(sb.String(), bitSize)
+	if err != nil {
+		rewind(pi, int(pi.Index()-start))
+		return Failure(name, &Error{Pos: pos, Err: err})
+	}
+	return Success(name, f, nil)
+}
+
+// optionalPart reads a rune from the introducers set, an optional rune from the signs set,
+// and some decimal digits, e.g. the exponent of a float. If there are no digits, the input is
+// rolled back and an empty string is returned.
+func optionalPart(pi Input, pos Pos, introducers, signs string) (s string, err error) {
+	start := pi.Index()
+	introducer := acceptRune(pi, introducers)
+	if introducer == "" {
+		return "", nil
+	}
+	sign := acceptRune(pi, signs)
+	ds, err := digits(pi, pos, 10, false)
+	if err != nil || ds == "" {
+		rewind(pi, int(pi.Index()-start))
+		return "", err
+	}
+	return introducer + sign + ds, nil
+}
+
+// acceptRune advances past the next rune if it's in the set, and returns it.
+func acceptRune(pi Input, set string) string {
+	r, err := peek(pi)
+	if err != nil || !strings.ContainsRune(set, r) {
+		return ""
+	}
+	pi.Advance()
+	return string(r)
+}
+
+// digitsWithPrefix reads the digits of an integer. If the base is zero, the base is taken from
+// the prefix of the digits, and is returned.
+func digitsWithPrefix(pi Input, pos Pos, base int) (ds string, actualBase int, err error) {
+	if base != 0 {
+		ds, err = digits(pi, pos, base, false)
+		return ds, base, err
+	}
+	start := pi.Index()
+	if acceptRune(pi, "0") != "" {
+		switch acceptRune(pi, "xXoObB") {
+		case "x", "X":
+			base = 16
+		case "o", "O":
+			base = 8
+		case "b", "B":
+			base = 2
+		}
+	}
+	if base == 0 {
+		rewind(pi, int(pi.Index()-start))
+		ds, err = digits(pi, pos, 10, false)
+		return ds, 10, err
+	}
+	ds, err = digits(pi, pos, base, true)
+	if err == nil && ds == "" {
+		err = Errorf(pos, "missing digits after base prefix")
+	}
+	return ds, base, err
+}
+
+// digits reads digits of the base, and underscores which separate them. The underscores are
+// removed from the returned digits. If the digits follow a base prefix, the first digit may
+// be preceded by an underscore. Otherwise, a leading underscore isn't part of the number, e.g.
+// it may start an identifier, so no digits are returned, without an error.
+func digits(pi Input, pos Pos, base int, prefixed bool) (string, error) {
+	var sb strings.Builder
+	var previous rune
+	if prefixed {
+		previous = '0'
+	}
+	var invalidUnderscore bool
+	for {
+		r, err := peek(pi)
+		if err != nil || (r != '_' && digitValue(r) >= base) || (r == '_' && previous == 0) {
+			break
+		}
+		pi.Advance()
+		if r == '_' {
+			if previous == '_' {
+				invalidUnderscore = true
+			}
+		} else {
+			sb.WriteRune(r)
+		}
+		previous = r
+	}
+	if invalidUnderscore || previous == '_' {
+		return "", Errorf(pos, "'_' must separate successive digits")
+	}
+	return sb.String(), nil
+}
+
+func digitValue(r rune) int {
+	switch {
+	case '0' <= r && r <= '9':
+		return int(r - '0')
+	case 'a' <= r && r <= 'z':
+		return int(r-'a') + 10
+	case 'A' <= r && r <= 'Z':
+		return int(r-'A') + 10
+	}
+	return 36
+}
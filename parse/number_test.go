@@ -0,0 +1,331 @@
+package parse
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+)
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		input         string
+		parser        Function
+		expected      bool
+		expectedItem  interface{}
+		expectedError string
+		expectedIndex int64
+	}{
+		{
+			input:         "123",
+			parser:        Int(10, 64),
+			expected:      true,
+			expectedItem:  int64(123),
+			expectedIndex: 3,
+		},
+		{
+			input:         "-123 ",
+			parser:        Int(10, 64),
+			expected:      true,
+			expectedItem:  int64(-123),
+			expectedIndex: 4,
+		},
+		{
+			input:         "+1_000_000,",
+			parser:        Int(10, 64),
+			expected:      true,
+			expectedItem:  int64(1000000),
+			expectedIndex: 10,
+		},
+		{
+			input:         "0123",
+			parser:        Int(0, 64),
+			expected:      true,
+			expectedItem:  int64(123),
+			expectedIndex: 4,
+		},
+		{
+			input:         "0",
+			parser:        Int(0, 64),
+			expected:      true,
+			expectedItem:  int64(0),
+			expectedIndex: 1,
+		},
+		{
+			input:         "0xFF_FF",
+			parser:        Int(0, 64),
+			expected:      true,
+			expectedItem:  int64(0xFFFF),
+			expectedIndex: 7,
+		},
+		{
+			input:         "-0x_1f",
+			parser:        Int(0, 64),
+			expected:      true,
+			expectedItem:  int64(-0x1F),
+			expectedIndex: 6,
+		},
+		{
+			input:         "0o777",
+			parser:        Int(0, 64),
+			expected:      true,
+			expectedItem:  int64(0777),
+			expectedIndex: 5,
+		},
+		{
+			input:         "0b1012",
+			parser:        Int(0, 64),
+			expected:      true,
+			expectedItem:  int64(5),
+			expectedIndex: 5,
+		},
+		{
+			input:         "ff",
+			parser:        Int(16, 64),
+			expected:      true,
+			expectedItem:  int64(255),
+			expectedIndex: 2,
+		},
+		{
+			input:         "12abc",
+			parser:        Int(10, 64),
+			expected:      true,
+			expectedItem:  int64(12),
+			expectedIndex: 2,
+		},
+		{
+			input:         "abc",
+			parser:        Int(10, 64),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "-",
+			parser:        Int(10, 64),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "0x",
+			parser:        Int(0, 64),
+			expected:      false,
+			expectedError: "line 1, column 0: missing digits after base prefix",
+			expectedIndex: 0,
+		},
+		{
+			input:         "128",
+			parser:        Int(10, 8),
+			expected:      false,
+			expectedError: `line 1, column 0: strconv.ParseInt: parsing "128": value out of range`,
+			expectedIndex: 0,
+		},
+		{
+			input:         "-128",
+			parser:        Int(10, 8),
+			expected:      true,
+			expectedItem:  int64(-128),
+			expectedIndex: 4,
+		},
+		{
+			input:         "1__0",
+			parser:        Int(10, 64),
+			expected:      false,
+			expectedError: "line 1, column 0: '_' must separate successive digits",
+			expectedIndex: 0,
+		},
+		{
+			input:         "10_",
+			parser:        Int(10, 64),
+			expected:      false,
+			expectedError: "line 1, column 0: '_' must separate successive digits",
+			expectedIndex: 0,
+		},
+		{
+			input:         "_foo",
+			parser:        Int(10, 64),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "-_1",
+			parser:        Int(10, 64),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "_foo",
+			parser:        Any(Int(10, 64), Identifier(func(r rune) bool { return r == '_' || IsIdentifierStart(r) }, nil)),
+			expected:      true,
+			expectedItem:  "_foo",
+			expectedIndex: 4,
+		},
+		{
+			input:         "a_b",
+			parser:        Many(WithStringConcatCombiner, 0, 0, Any(Int(10, 64), RuneIn("ab_"))),
+			expected:      true,
+			expectedItem:  "a_b",
+			expectedIndex: 3,
+		},
+		{
+			input:         "1._5",
+			parser:        Float(64),
+			expected:      true,
+			expectedItem:  1.0,
+			expectedIndex: 1,
+		},
+		{
+			input:         "255",
+			parser:        Uint(10, 8),
+			expected:      true,
+			expectedItem:  uint64(255),
+			expectedIndex: 3,
+		},
+		{
+			input:         "256",
+			parser:        Uint(10, 8),
+			expected:      false,
+			expectedError: `line 1, column 0: strconv.ParseUint: parsing "256": value out of range`,
+			expectedIndex: 0,
+		},
+		{
+			input:         "-1",
+			parser:        Uint(10, 64),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "1.5",
+			parser:        Float(64),
+			expected:      true,
+			expectedItem:  1.5,
+			expectedIndex: 3,
+		},
+		{
+			input:         "-1_000.25e-2x",
+			parser:        Float(64),
+			expected:      true,
+			expectedItem:  -10.0025,
+			expectedIndex: 12,
+		},
+		{
+			input:         "2E+3",
+			parser:        Float(64),
+			expected:      true,
+			expectedItem:  2000.0,
+			expectedIndex: 4,
+		},
+		{
+			input:         "42",
+			parser:        Float(64),
+			expected:      true,
+			expectedItem:  42.0,
+			expectedIndex: 2,
+		},
+		{
+			input:         "1.method",
+			parser:        Float(64),
+			expected:      true,
+			expectedItem:  1.0,
+			expectedIndex: 1,
+		},
+		{
+			input:         "1else",
+			parser:        Float(64),
+			expected:      true,
+			expectedItem:  1.0,
+			expectedIndex: 1,
+		},
+		{
+			input:         ".5",
+			parser:        Float(64),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "1e400",
+			parser:        Float(64),
+			expected:      false,
+			expectedError: `line 1, column 0: strconv.ParseFloat: parsing "1e400": value out of range`,
+			expectedIndex: 0,
+		},
+	}
+
+	for i, test := range tests {
+		pi := input.NewFromString(test.input)
+		result := test.parser(pi)
+		if result.Success != test.expected {
+			t.Errorf("test %v: for input '%v' expected %v but got %v", i, test.input, test.expected, result)
+		}
+		if test.expected && result.Item != test.expectedItem {
+			t.Errorf("test %v: for input '%v' expected item %#v but got %#v", i, test.input, test.expectedItem, result.Item)
+		}
+		var actualError string
+		if result.Error != nil {
+			actualError = result.Error.Error()
+		}
+		if actualError != test.expectedError {
+			t.Errorf("test %v: for input '%v' expected error '%v' but got '%v'", i, test.input, test.expectedError, actualError)
+		}
+		if test.expectedIndex != pi.Index() {
+			t.Errorf("test %v: for input '%v' expected index %d, got %d", i, test.input, test.expectedIndex, pi.Index())
+		}
+	}
+}
+
+func TestNumberErrorPosition(t *testing.T) {
+	assignment := All(WithStringConcatCombiner, String("x =\n  "), Int(10, 32))
+	result := assignment(input.NewFromString("x =\n  99999999999"))
+	if result.Success {
+		t.Fatalf("expected failure, got %v", result)
+	}
+	var err *Error
+	if !errors.As(result.Error, &err) {
+		t.Fatalf("expected *Error, got %T", result.Error)
+	}
+	if err.Pos.Line != 2 || err.Pos.Column != 2 || err.Pos.Index != 6 {
+		t.Errorf("expected error at index 6, line 2, column 2, got %+v", err.Pos)
+	}
+	if !errors.Is(result.Error, strconv.ErrRange) {
+		t.Errorf("expected a range error, got %v", result.Error)
+	}
+}
+
+func TestNumberErrorStopsAny(t *testing.T) {
+	value := Any(Int(10, 8), String("300 is too big"))
+	result := value(input.NewFromString("300 is too big"))
+	if result.Success {
+		t.Errorf("expected the overflow to be reported, got %v", result)
+	}
+}
+
+func TestFloat32(t *testing.T) {
+	result := Float(32)(input.NewFromString("3.4e39"))
+	if result.Success {
+		t.Errorf("expected 3.4e39 to overflow a float32")
+	}
+	result = Float(32)(input.NewFromString("0.1"))
+	if result.Item != float64(float32(0.1)) {
+		t.Errorf("expected %v, got %v", float32(0.1), result.Item)
+	}
+	if math.IsInf(result.Item.(float64), 0) {
+		t.Errorf("unexpected infinity")
+	}
+}
+
+func BenchmarkInt(b *testing.B) {
+	b.ReportAllocs()
+	parser := Int(10, 64)
+	for n := 0; n < b.N; n++ {
+		parser(input.NewFromString("1234567890"))
+	}
+}
+
+func BenchmarkFloat(b *testing.B) {
+	b.ReportAllocs()
+	parser := Float(64)
+	for n := 0; n < b.N; n++ {
+		parser(input.NewFromString("12345.6789e10"))
+	}
+}
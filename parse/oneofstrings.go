@@ -0,0 +1,107 @@
+package parse
+
+import "unicode"
+
+// OneOfStrings captures the longest of the provided strings which matches the input. Unlike
+// Any, the input is read once regardless of how many strings there are, and the order of the
+// strings doesn't matter, e.g. OneOfStrings("<", "<=") matches all of "<=".
+func OneOfStrings(strs ...string) Function {
+	root := newTrie(strs, false)
+	return func(pi Input) Result {
+		return oneOfStrings(pi, "one of strings", root, false)
+	}
+}
+
+// OneOfStringsInsensitive captures the longest of the provided strings which matches the
+// input, ignoring string casing. The item is the string as it was provided, not as it
+// appeared in the input, in the same way as StringInsensitive.
+func OneOfStringsInsensitive(strs ...string) Function {
+	root := newTrie(strs, true)
+	return func(pi Input) Result {
+		return oneOfStrings(pi, "one of strings insensitive", root, true)
+	}
+}
+
+// trie is a prefix tree of runes, used to match many strings at once.
+type trie struct {
+	children map[rune]*trie
+	terminal bool
+	value    string
+}
+
+func newTrie(strs []string, insensitive bool) *trie {
+	root := &trie{}
+	for _, s := range strs {
+		node := root
+		for _, r := range s {
+			if insensitive {
+				r = foldRune(r)
+			}
+			if node.children == nil {
+				node.children = make(map[rune]*trie)
+			}
+			child, ok := node.children[r]
+			if !ok {
+				child = &trie{}
+				node.children[r] = child
+			}
+			node = child
+		}
+		// When strings are duplicated, the first one wins.
+		if !node.terminal {
+			node.terminal = true
+			node.value = s
+		}
+	}
+	return root
+}
+
+func oneOfStrings(pi Input, name string, root *trie, insensitive bool) Result {
+	start := pi.Index()
+	node := root
+	matched, value := root.terminal, root.value
+	matchedAt := start
+	var err error
+	for node.children != nil {
+		var r rune
+		r, err = pi.Advance()
+		if err != nil {
+			break
+		}
+		if insensitive {
+			r = foldRune(r)
+		}
+		node = node.children[r]
+		if node == nil {
+			break
+		}
+		if node.terminal {
+			matched, value = true, node.value
+			matchedAt = pi.Index()
+		}
+	}
+	if !matched {
+		rewind(pi, int(pi.Index()-start))
+		return Failure(name, err)
+	}
+	rewind(pi, int(pi.Index()-matchedAt))
+	return Success(name, value, nil)
+}
+
+// foldRune returns the same rune for all runes which are equal under Unicode case folding,
+// i.e. the runes which strings.EqualFold considers to be the same.
+func foldRune(r rune) rune {
+	if r < 0x80 {
+		if 'a' <= r && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		return r
+	}
+	min := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < min {
+			min = f
+		}
+	}
+	return min
+}
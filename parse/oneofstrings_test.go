@@ -0,0 +1,157 @@
+package parse
+
+import (
+	"io"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+)
+
+func TestOneOfStrings(t *testing.T) {
+	operators := OneOfStrings("<", "<=", "<>", "=", ">=", ">", "<<=")
+	tests := []struct {
+		input         string
+		parser        Function
+		expected      bool
+		expectedItem  interface{}
+		expectedError error
+		expectedIndex int64
+	}{
+		{
+			input:         "<",
+			parser:        operators,
+			expected:      true,
+			expectedItem:  "<",
+			expectedIndex: 1,
+		},
+		{
+			input:         "<= 1",
+			parser:        operators,
+			expected:      true,
+			expectedItem:  "<=",
+			expectedIndex: 2,
+		},
+		{
+			input:         "<<1",
+			parser:        operators,
+			expected:      true,
+			expectedItem:  "<",
+			expectedIndex: 1,
+		},
+		{
+			input:         "<<=",
+			parser:        operators,
+			expected:      true,
+			expectedItem:  "<<=",
+			expectedIndex: 3,
+		},
+		{
+			input:         "!=",
+			parser:        operators,
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "",
+			parser:        operators,
+			expected:      false,
+			expectedError: io.EOF,
+			expectedIndex: 0,
+		},
+		{
+			input:         "insert",
+			parser:        OneOfStrings("in", "insert", "inner"),
+			expected:      true,
+			expectedItem:  "insert",
+			expectedIndex: 6,
+		},
+		{
+			input:         "inse",
+			parser:        OneOfStrings("in", "insert", "inner"),
+			expected:      true,
+			expectedItem:  "in",
+			expectedIndex: 2,
+		},
+		{
+			input:         "ins",
+			parser:        OneOfStrings("insert", "inner"),
+			expected:      false,
+			expectedError: io.EOF,
+			expectedIndex: 0,
+		},
+		{
+			input:         "Insert",
+			parser:        OneOfStrings("in", "insert", "inner"),
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "INSERT INTO",
+			parser:        OneOfStringsInsensitive("in", "insert", "inner"),
+			expected:      true,
+			expectedItem:  "insert",
+			expectedIndex: 6,
+		},
+		{
+			input:         "ǅemal",
+			parser:        OneOfStringsInsensitive("ǆemal"),
+			expected:      true,
+			expectedItem:  "ǆemal",
+			expectedIndex: 5,
+		},
+		{
+			input:         "你好",
+			parser:        OneOfStrings("你", "你好", "好"),
+			expected:      true,
+			expectedItem:  "你好",
+			expectedIndex: 2,
+		},
+	}
+
+	for i, test := range tests {
+		pi := input.NewFromString(test.input)
+		result := test.parser(pi)
+		actual := result.Success
+		if actual != test.expected {
+			t.Errorf("test %v: for input '%v' expected %v but got %v", i, test.input, test.expected, actual)
+		}
+		if test.expected && result.Item != test.expectedItem {
+			t.Errorf("test %v: for input '%v' expected item '%v' but got '%v'", i, test.input, test.expectedItem, result.Item)
+		}
+		if result.Error != test.expectedError {
+			t.Errorf("test %v: for input '%v' expected error '%v' but got '%v'", i, test.input, test.expectedError, result.Error)
+		}
+		if test.expectedIndex != pi.Index() {
+			t.Errorf("test %v: for input '%v' expected index %d, got %d", i, test.input, test.expectedIndex, pi.Index())
+		}
+	}
+}
+
+var benchmarkKeywords = []string{
+	"ADD", "ALL", "ALTER", "AND", "ANY", "AS", "ASC", "BACKUP", "BETWEEN", "BY", "CASE", "CHECK",
+	"COLUMN", "CONSTRAINT", "CREATE", "DATABASE", "DEFAULT", "DELETE", "DESC", "DISTINCT", "DROP",
+	"EXEC", "EXISTS", "FOREIGN", "FROM", "FULL", "GROUP", "HAVING", "IN", "INDEX", "INNER", "INSERT",
+	"INTO", "IS", "JOIN", "KEY", "LEFT", "LIKE", "LIMIT", "NOT", "NULL", "OR", "ORDER", "OUTER",
+	"PRIMARY", "PROCEDURE", "RIGHT", "ROWNUM", "SELECT", "SET", "TABLE", "TOP", "TRUNCATE", "UNION",
+	"UNIQUE", "UPDATE", "VALUES", "VIEW", "WHERE",
+}
+
+func BenchmarkOneOfStrings(b *testing.B) {
+	b.ReportAllocs()
+	parser := OneOfStrings(benchmarkKeywords...)
+	for n := 0; n < b.N; n++ {
+		parser(input.NewFromString("WHERE"))
+	}
+}
+
+func BenchmarkOneOfStringsAny(b *testing.B) {
+	b.ReportAllocs()
+	parsers := make([]Function, len(benchmarkKeywords))
+	for i, k := range benchmarkKeywords {
+		parsers[i] = String(k)
+	}
+	parser := Any(parsers...)
+	for n := 0; n < b.N; n++ {
+		parser(input.NewFromString("WHERE"))
+	}
+}
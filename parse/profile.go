@@ -0,0 +1,121 @@
+package parse
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Profile holds the statistics of each Named parser of a Run, so that the costs of a grammar can
+// be attributed to its rules, rather than to the closures of the combinators.
+type Profile struct {
+	rules map[string]*RuleProfile
+	// active is the number of calls of each rule which haven't returned, so that the time of a
+	// recursive rule isn't counted more than once.
+	active map[string]int
+}
+
+// RuleProfile holds the statistics of a Named parser.
+type RuleProfile struct {
+	Name string
+	// Calls is the number of times that the parser was called, and Successes and Failures are
+	// the number of times that it succeeded and failed.
+	Calls     int64
+	Successes int64
+	Failures  int64
+	// Consumed is the number of runes that the parser consumed, and Rewound is the number of runes
+	// that it read, but gave back to the input, e.g. because an alternative of Any failed.
+	Consumed int64
+	Rewound  int64
+	// Time is the cumulative time spent in the parser, including the parsers that it called.
+	Time time.Duration
+}
+
+func newProfile() *Profile {
+	return &Profile{
+		rules:  make(map[string]*RuleProfile),
+		active: make(map[string]int),
+	}
+}
+
+func (p *Profile) enter(name string) time.Time {
+	p.active[name]++
+	return time.Now()
+}
+
+func (p *Profile) exit(name string, began time.Time, success bool, consumed, rewound int64) {
+	elapsed := time.Since(began)
+	rp, ok := p.rules[name]
+	if !ok {
+		rp = &RuleProfile{Name: name}
+		p.rules[name] = rp
+	}
+	rp.Calls++
+	if success {
+		rp.Successes++
+	} else {
+		rp.Failures++
+	}
+	rp.Consumed += consumed
+	rp.Rewound += rewound
+	if p.active[name]--; p.active[name] == 0 {
+		rp.Time += elapsed
+	}
+}
+
+// Add adds the statistics of another profile, e.g. to profile a grammar over many runs.
+func (p *Profile) Add(other *Profile) {
+	for name, o := range other.rules {
+		rp, ok := p.rules[name]
+		if !ok {
+			rp = &RuleProfile{Name: name}
+			p.rules[name] = rp
+		}
+		rp.Calls += o.Calls
+		rp.Successes += o.Successes
+		rp.Failures += o.Failures
+		rp.Consumed += o.Consumed
+		rp.Rewound += o.Rewound
+		rp.Time += o.Time
+	}
+}
+
+// Rules returns the statistics of each rule, sorted by cost: the rules which took the most time
+// come first, and rules which took the same time are sorted by the number of runes rewound, and
+// then by name.
+func (p *Profile) Rules() []RuleProfile {
+	rules := make([]RuleProfile, 0, len(p.rules))
+	for _, rp := range p.rules {
+		rules = append(rules, *rp)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Time != rules[j].Time {
+			return rules[i].Time > rules[j].Time
+		}
+		if rules[i].Rewound != rules[j].Rewound {
+			return rules[i].Rewound > rules[j].Rewound
+		}
+		return rules[i].Name < rules[j].Name
+	})
+	return rules
+}
+
+// String returns the report written by WriteText.
+func (p *Profile) String() string {
+	var sb strings.Builder
+	p.WriteText(&sb)
+	return sb.String()
+}
+
+// WriteText writes a table of the statistics of each rule, sorted by cost.
+func (p *Profile) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "rule\tcalls\tsuccesses\tfailures\tconsumed\trewound\ttime")
+	for _, rp := range p.Rules() {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%v\n", rp.Name, rp.Calls, rp.Successes, rp.Failures, rp.Consumed, rp.Rewound, rp.Time)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,85 @@
+package parse
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/lexical/input"
+)
+
+func TestProfile(t *testing.T) {
+	g := Compile(assignment)
+	g.Profiling = true
+	_, run := g.Parse(context.Background(), input.NewFromString("a=12"))
+	expected := map[string]RuleProfile{
+		"assignment": {Name: "assignment", Calls: 1, Successes: 1, Consumed: 4},
+		"name":       {Name: "name", Calls: 1, Successes: 1, Consumed: 1, Rewound: 1},
+		"float":      {Name: "float", Calls: 1, Failures: 1, Rewound: 2},
+		"integer":    {Name: "integer", Calls: 1, Successes: 1, Consumed: 2},
+	}
+	rules := run.Profile.Rules()
+	if len(rules) != len(expected) {
+		t.Fatalf("expected %d rules, got %v", len(expected), rules)
+	}
+	for _, rp := range rules {
+		e := expected[rp.Name]
+		e.Time = rp.Time
+		if rp != e {
+			t.Errorf("expected %+v, got %+v", e, rp)
+		}
+	}
+	// The time of a rule includes the time of the rules it calls, so the top rule is the costliest.
+	if rules[0].Name != "assignment" {
+		t.Errorf("expected the assignment to be the costliest rule, got %v", rules[0].Name)
+	}
+	if run.Trace != nil {
+		t.Errorf("expected profiling not to trace")
+	}
+
+	_, other := g.Parse(context.Background(), input.NewFromString("b=c"))
+	run.Profile.Add(other.Profile)
+	for _, rp := range run.Profile.Rules() {
+		if rp.Name == "name" && (rp.Calls != 3 || rp.Consumed != 3) {
+			t.Errorf("expected the names of both runs to be added, got %+v", rp)
+		}
+	}
+}
+
+func TestProfileRecursion(t *testing.T) {
+	var list Function
+	list = Named("list", Any(
+		All(WithStringConcatCombiner, Rune('x'), Rune(','), func(pi Input) Result { return list(pi) }),
+		Rune('x'),
+	))
+	g := Compile(list)
+	g.Profiling = true
+	began := time.Now()
+	r, run := g.Parse(context.Background(), input.NewFromString(strings.Repeat("x,", 100)+"x"))
+	elapsed := time.Since(began)
+	if !r.Success {
+		t.Fatalf("expected success, got %v", r)
+	}
+	rp := run.Profile.Rules()[0]
+	if rp.Calls != 101 || rp.Successes != 101 {
+		t.Errorf("expected 101 successful calls, got %+v", rp)
+	}
+	// The time of the nested calls is only counted once.
+	if rp.Time > elapsed {
+		t.Errorf("expected the time of the rule to be at most %v, got %v", elapsed, rp.Time)
+	}
+}
+
+func TestProfileReport(t *testing.T) {
+	p := newProfile()
+	p.rules["value"] = &RuleProfile{Name: "value", Calls: 10, Successes: 4, Failures: 6, Consumed: 20, Rewound: 30, Time: 2 * time.Millisecond}
+	p.rules["key"] = &RuleProfile{Name: "key", Calls: 5, Successes: 5, Consumed: 15, Time: 3 * time.Millisecond}
+	expected := `rule   calls  successes  failures  consumed  rewound  time
+key    5      5          0         15        0        3ms
+value  10     4          6         20        30       2ms
+`
+	if actual := p.String(); actual != expected {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, actual)
+	}
+}
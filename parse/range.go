@@ -0,0 +1,40 @@
+package parse
+
+// Range is the span of the input that an item was parsed from. End is the position
+// immediately after the item.
+type Range struct {
+	Start Pos
+	End   Pos
+}
+
+// String returns the start and end of the range.
+func (r Range) String() string {
+	return r.Start.String() + " to " + r.End.String()
+}
+
+// Ranged is an item, and the range of the input it was parsed from.
+type Ranged struct {
+	Range Range
+	Item  interface{}
+}
+
+// WithRange captures the function, and sets the result item to a Ranged which contains the
+// item and the range of the input it was parsed from.
+func WithRange(f Function) Function {
+	return func(pi Input) Result {
+		return withRange(pi, f)
+	}
+}
+
+func withRange(pi Input, f Function) Result {
+	start := PosOf(pi)
+	r := f(pi)
+	if !r.Success {
+		return r
+	}
+	r.Item = Ranged{
+		Range: Range{Start: start, End: PosOf(pi)},
+		Item:  r.Item,
+	}
+	return r
+}
@@ -0,0 +1,41 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/a-h/lexical/input"
+)
+
+func TestWithRange(t *testing.T) {
+	pi := input.NewFromString("ab\ncd")
+	String("ab\n")(pi)
+	result := WithRange(String("cd"))(pi)
+	if !result.Success {
+		t.Fatalf("expected success, got %v", result)
+	}
+	ranged, ok := result.Item.(Ranged)
+	if !ok {
+		t.Fatalf("expected Ranged, got %T", result.Item)
+	}
+	if ranged.Item != "cd" {
+		t.Errorf("expected item 'cd', got %v", ranged.Item)
+	}
+	expected := Range{
+		Start: Pos{Index: 3, Line: 2, Column: 0},
+		End:   Pos{Index: 5, Line: 2, Column: 2},
+	}
+	if ranged.Range != expected {
+		t.Errorf("expected %v, got %v", expected, ranged.Range)
+	}
+}
+
+func TestWithRangeFailure(t *testing.T) {
+	pi := input.NewFromString("ab")
+	result := WithRange(String("cd"))(pi)
+	if result.Success {
+		t.Fatalf("expected failure, got %v", result)
+	}
+	if result.Item != nil {
+		t.Errorf("expected no item, got %v", result.Item)
+	}
+}
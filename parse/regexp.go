@@ -0,0 +1,90 @@
+package parse
+
+import (
+	"io"
+	"regexp"
+	"unicode/utf8"
+)
+
+// Regexp captures the string matched by the regular expression at the current position of
+// the input. The input is read rune by rune, so the whole input doesn't need to be in memory,
+// and the input is left positioned immediately after the match.
+//
+// The expression is always anchored to the current position, and uses the leftmost-first
+// semantics of the regexp package, e.g. `a|ab` matches "a" within "ab". Regexp panics if the
+// expression can't be compiled, in the same way as regexp.MustCompile. If the expression
+// doesn't match, the failure has a nil error, even if the end of the input was reached.
+func Regexp(pattern string) Function {
+	re := compileAnchored(pattern)
+	name := "regexp: '" + pattern + "'"
+	return func(pi Input) Result {
+		return parseRegexp(pi, name, re, false)
+	}
+}
+
+// RegexpSubmatch captures the regular expression in the same way as Regexp, but the item is
+// a []string containing the whole match followed by the text of each of the subexpressions,
+// in the same way as regexp.FindStringSubmatch. Subexpressions which didn't take part in the
+// match are empty strings.
+func RegexpSubmatch(pattern string) Function {
+	re := compileAnchored(pattern)
+	name := "regexp submatch: '" + pattern + "'"
+	return func(pi Input) Result {
+		return parseRegexp(pi, name, re, true)
+	}
+}
+
+func compileAnchored(pattern string) *regexp.Regexp {
+	return regexp.MustCompile(`^(?:` + pattern + `)`)
+}
+
+// inputRuneReader reads runes from the input, keeping a copy of each rune that's been read.
+type inputRuneReader struct {
+	pi    Input
+	runes []rune
+	err   error
+}
+
+func (rr *inputRuneReader) ReadRune() (r rune, size int, err error) {
+	r, err = rr.pi.Advance()
+	if err != nil {
+		rr.err = err
+		return 0, 0, err
+	}
+	rr.runes = append(rr.runes, r)
+	size = utf8.RuneLen(r)
+	if size < 0 {
+		// Invalid runes are written as utf8.RuneError when converted to a string.
+		size = utf8.RuneLen(utf8.RuneError)
+	}
+	return r, size, nil
+}
+
+func parseRegexp(pi Input, name string, re *regexp.Regexp, submatches bool) Result {
+	start := pi.Index()
+	rr := &inputRuneReader{pi: pi}
+	loc := re.FindReaderSubmatchIndex(rr)
+	if loc == nil {
+		rewind(pi, int(pi.Index()-start))
+		// Reaching the end of the input isn't the reason that the expression didn't match, but
+		// other errors from the input are.
+		if rr.err == io.EOF {
+			return Failure(name, nil)
+		}
+		return Failure(name, rr.err)
+	}
+	text := string(rr.runes)
+	match := text[:loc[1]]
+	// The expression may have read past the end of the match, so step back to it.
+	rewind(pi, int(pi.Index()-start)-utf8.RuneCountInString(match))
+	if !submatches {
+		return Success(name, match, nil)
+	}
+	items := make([]string, len(loc)/2)
+	for i := range items {
+		if loc[2*i] >= 0 {
+			items[i] = text[loc[2*i]:loc[2*i+1]]
+		}
+	}
+	return Success(name, items, nil)
+}
@@ -0,0 +1,162 @@
+package parse
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+)
+
+func TestRegexp(t *testing.T) {
+	tests := []struct {
+		input         string
+		pattern       string
+		expected      bool
+		expectedItem  interface{}
+		expectedError error
+		expectedIndex int64
+	}{
+		{
+			input:         "name_1 = 2",
+			pattern:       `[A-Za-z_][A-Za-z0-9_]*`,
+			expected:      true,
+			expectedItem:  "name_1",
+			expectedIndex: 6,
+		},
+		{
+			input:         "name_1",
+			pattern:       `[A-Za-z_][A-Za-z0-9_]*`,
+			expected:      true,
+			expectedItem:  "name_1",
+			expectedIndex: 6,
+		},
+		{
+			input:         "1name",
+			pattern:       `[A-Za-z_][A-Za-z0-9_]*`,
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         " name",
+			pattern:       `name`,
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "2001-02-03T04:05:06Z",
+			pattern:       `\d{4}-\d{2}-\d{2}`,
+			expected:      true,
+			expectedItem:  "2001-02-03",
+			expectedIndex: 10,
+		},
+		{
+			input:         "2001-02",
+			pattern:       `\d{4}-\d{2}-\d{2}`,
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "abcd",
+			pattern:       `abc\b`,
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         "ab",
+			pattern:       `a|ab`,
+			expected:      true,
+			expectedItem:  "a",
+			expectedIndex: 1,
+		},
+		{
+			input:         "你好, world",
+			pattern:       `\p{Han}+`,
+			expected:      true,
+			expectedItem:  "你好",
+			expectedIndex: 2,
+		},
+		{
+			input:         "abc",
+			pattern:       `x*`,
+			expected:      true,
+			expectedItem:  "",
+			expectedIndex: 0,
+		},
+		{
+			input:         "abc",
+			pattern:       `abc$`,
+			expected:      true,
+			expectedItem:  "abc",
+			expectedIndex: 3,
+		},
+	}
+
+	for i, test := range tests {
+		pi := input.NewFromString(test.input)
+		result := Regexp(test.pattern)(pi)
+		if result.Success != test.expected {
+			t.Errorf("test %v: for input '%v' expected %v but got %v", i, test.input, test.expected, result.Success)
+		}
+		if test.expected && result.Item != test.expectedItem {
+			t.Errorf("test %v: for input '%v' expected item '%v' but got '%v'", i, test.input, test.expectedItem, result.Item)
+		}
+		if result.Error != test.expectedError {
+			t.Errorf("test %v: for input '%v' expected error '%v' but got '%v'", i, test.input, test.expectedError, result.Error)
+		}
+		if test.expectedIndex != pi.Index() {
+			t.Errorf("test %v: for input '%v' expected index %d, got %d", i, test.input, test.expectedIndex, pi.Index())
+		}
+	}
+}
+
+func TestRegexpSubmatch(t *testing.T) {
+	pi := input.NewFromString("2001-02-03 rest")
+	result := RegexpSubmatch(`(\d{4})-(\d{2})-(\d{2})(T\d{2})?`)(pi)
+	if !result.Success {
+		t.Fatalf("expected success, got %v", result)
+	}
+	expected := []string{"2001-02-03", "2001", "02", "03", ""}
+	if !reflect.DeepEqual(result.Item, expected) {
+		t.Errorf("expected %q, got %q", expected, result.Item)
+	}
+	if pi.Index() != 10 {
+		t.Errorf("expected index 10, got %d", pi.Index())
+	}
+}
+
+func TestRegexpInSequence(t *testing.T) {
+	assignment := All(WithStringConcatCombiner,
+		Regexp(`[a-z]+`),
+		Rune('='),
+		Regexp(`[0-9]+`),
+	)
+	pi := input.New(strings.NewReader("abc=123;"))
+	result := assignment(pi)
+	if !result.Success {
+		t.Fatalf("expected success, got %v", result)
+	}
+	if result.Item != "abc=123" {
+		t.Errorf("expected 'abc=123', got '%v'", result.Item)
+	}
+	if r, _ := pi.Peek(); r != ';' {
+		t.Errorf("expected the next rune to be ';', got %q", r)
+	}
+}
+
+func TestRegexpInvalidPatternPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic")
+		}
+	}()
+	Regexp(`[a-z`)
+}
+
+func BenchmarkRegexp(b *testing.B) {
+	b.ReportAllocs()
+	parser := Regexp(`[A-Z]+`)
+	for n := 0; n < b.N; n++ {
+		parser(input.NewFromString("ABCDEFGHIJKLMNOPQRSTUVWXYZ"))
+	}
+}
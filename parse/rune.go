@@ -14,8 +14,10 @@ func parseRune(pi Input, r rune) Result {
 		return Failure(name, err)
 	}
 	if pr == r {
-		_, err = pi.Advance()
-		return Success(name, pr, err)
+		if _, err = pi.Advance(); err != nil {
+			return Failure(name, err)
+		}
+		return Success(name, pr, nil)
 	}
 	return Failure(name, nil)
 }
@@ -1,7 +1,6 @@
 package parse
 
 import (
-	"strings"
 	"unicode"
 )
 
@@ -15,32 +14,37 @@ func RuneWhere(predicate func(r rune) bool) Function {
 // RuneIn captures a rune if it's within the input set.
 func RuneIn(set string) Function {
 	name := "any rune in '" + set + "'"
+	c := ClassOf(set)
 	return func(pi Input) Result {
-		return runeWhere(pi, name, func(r rune) bool { return strings.ContainsRune(set, r) })
+		return runeWhere(pi, name, c.Contains)
 	}
 }
 
 // RuneNotIn captures a rune if it's not within the input set.
 func RuneNotIn(set string) Function {
 	name := "any rune not in '" + set + "'"
+	c := ClassOf(set).Not()
 	return func(pi Input) Result {
-		return runeWhere(pi, name, func(r rune) bool { return !strings.ContainsRune(set, r) })
+		return runeWhere(pi, name, c.Contains)
 	}
 }
 
 func runeWhere(pi Input, name string, predicate func(r rune) bool) Result {
 	pr, err := pi.Peek()
-	if predicate(pr) {
-		_, err = pi.Advance()
-		return Success(name, pr, err)
+	if err == nil && predicate(pr) {
+		if _, err = pi.Advance(); err != nil {
+			return Failure(name, err)
+		}
+		return Success(name, pr, nil)
 	}
 	return Failure(name, err)
 }
 
 // RuneInRanges returns a parser which accepts a rune within the specified Unicode range.
 func RuneInRanges(rts ...*unicode.RangeTable) Function {
+	c := ClassTable(rts...)
 	return func(pi Input) Result {
-		return runeWhere(pi, "rune in ranges", func(r rune) bool { return unicode.IsOneOf(rts, r) })
+		return runeWhere(pi, "rune in ranges", c.Contains)
 	}
 }
 
@@ -19,7 +19,10 @@ func parseString(pi Input, s string) Result {
 			rewind(pi, advancedBy)
 			return Failure(name, err)
 		}
-		pi.Advance()
+		if _, err = pi.Advance(); err != nil {
+			rewind(pi, advancedBy)
+			return Failure(name, err)
+		}
 		advancedBy++
 	}
 	return Success(name, s, nil)
@@ -42,7 +45,10 @@ func parseStringInsensitive(pi Input, s string) Result {
 			rewind(pi, advancedBy)
 			return Failure(name, err)
 		}
-		pi.Advance()
+		if _, err = pi.Advance(); err != nil {
+			rewind(pi, advancedBy)
+			return Failure(name, err)
+		}
 		advancedBy++
 	}
 	return Success(name, s, nil)
@@ -57,3 +63,44 @@ func rewind(pi Input, times int) (err error) {
 	}
 	return
 }
+
+// RewindTo retreats the input until it's at the index, e.g. to give back the runes that were
+// read by a parser which failed.
+func RewindTo(pi Input, index int64) {
+	for pi.Index() > index {
+		if _, err := pi.Retreat(); err != nil {
+			return
+		}
+	}
+}
+
+// Accept captures f, and restores the index of the input if it fails.
+func Accept(pi Input, f Function) Result {
+	start := pi.Index()
+	r := f(pi)
+	if !r.Success {
+		RewindTo(pi, start)
+	}
+	return r
+}
+
+// Unexpected reads the next rune of the input, and returns an error at the position which
+// describes it, e.g. "line 1, column 5: unexpected 'x'".
+func Unexpected(pi Input, pos Pos) *Error {
+	result := AnyRune()(pi)
+	if !result.Success {
+		return Errorf(pos, "unexpected end of input")
+	}
+	return Errorf(pos, "unexpected %q", result.Item)
+}
+
+// peek returns the next rune without consuming it. Unlike Input.Peek, the index of the input
+// is left where it was when the end of the input is reached.
+func peek(pi Input) (r rune, err error) {
+	start := pi.Index()
+	r, err = pi.Peek()
+	if err != nil {
+		rewind(pi, int(pi.Index()-start))
+	}
+	return
+}
@@ -136,3 +136,68 @@ func BenchmarkString(b *testing.B) {
 		String("ABCDEFG")(input.NewFromString("ABCDEFGHIJKLMNOPQRSTUVWXYZ"))
 	}
 }
+
+func TestAccept(t *testing.T) {
+	tests := []struct {
+		input         string
+		parser        Function
+		expected      bool
+		expectedIndex int64
+	}{
+		{
+			input:         "abc",
+			parser:        String("ab"),
+			expected:      true,
+			expectedIndex: 2,
+		},
+		{
+			input:         "abd",
+			parser:        All(WithStringConcatCombiner, Rune('a'), Rune('b'), Rune('c')),
+			expected:      false,
+			expectedIndex: 0,
+		},
+	}
+
+	for i, test := range tests {
+		pi := input.NewFromString(test.input)
+		actual := Accept(pi, test.parser)
+		if actual.Success != test.expected {
+			t.Errorf("test %v: for input '%v' expected %v but got %v", i, test.input, test.expected, actual)
+		}
+		if pi.Index() != test.expectedIndex {
+			t.Errorf("test %v: for input '%v' expected index %d, got %d", i, test.input, test.expectedIndex, pi.Index())
+		}
+	}
+}
+
+func TestRewindTo(t *testing.T) {
+	pi := input.NewFromString("abc")
+	for i := 0; i < 3; i++ {
+		pi.Advance()
+	}
+	RewindTo(pi, 1)
+	if pi.Index() != 1 {
+		t.Errorf("expected index 1, got %d", pi.Index())
+	}
+	RewindTo(pi, 2)
+	if pi.Index() != 1 {
+		t.Errorf("expected rewinding forwards to do nothing, got index %d", pi.Index())
+	}
+}
+
+func TestUnexpected(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "x", expected: "line 1, column 0: unexpected 'x'"},
+		{input: "", expected: "line 1, column 0: unexpected end of input"},
+	}
+
+	for i, test := range tests {
+		pi := input.NewFromString(test.input)
+		if actual := Unexpected(pi, PosOf(pi)).Error(); actual != test.expected {
+			t.Errorf("test %v: for input '%v' expected '%v', got '%v'", i, test.input, test.expected, actual)
+		}
+	}
+}
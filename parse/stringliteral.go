@@ -0,0 +1,226 @@
+package parse
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// StringLiteralOptions configures the syntax of a string literal.
+type StringLiteralOptions struct {
+	// Quotes is the set of runes which can start a string literal, e.g. `"'`. The literal
+	// must end with the same rune that it started with.
+	Quotes string
+	// Escape is the rune which starts an escape sequence, e.g. '\\', or zero if escape
+	// sequences aren't supported, as in a raw string.
+	Escape rune
+	// Escapes is the set of runes which may follow the escape rune. If it's empty, all of the
+	// standard escapes are allowed: \a \b \f \n \r \t \v \\ \' \" \xHH \uHHHH \UHHHHHHHH and
+	// octal escapes of up to three digits, e.g. \0 or \101. As in Go and C, \x and octal escapes
+	// are bytes, not Unicode code points, so \xff is the byte 0xFF, which isn't valid UTF-8,
+	// and \xc3\xa9 is é. Runes in the set which aren't standard escapes stand for themselves,
+	// e.g. including '/' allows \/ in JSON.
+	Escapes string
+	// DoubledQuotes allows the quote to be written within the literal by writing it twice,
+	// as in SQL and CSV, e.g. 'it''s'.
+	DoubledQuotes bool
+	// MultiLine allows the literal to contain newlines.
+	MultiLine bool
+	// DisallowControlRunes rejects literals which contain the control runes U+0000 to U+001F,
+	// as in JSON. Escaped control runes are still allowed.
+	DisallowControlRunes bool
+}
+
+// StringLiteral captures a quoted string literal, and returns its value, i.e. the contents
+// of the literal without the quotes, with any escape sequences decoded.
+//
+// If the input doesn't start with a quote, the parser rolls back without an error. Once
+// a quote has been read, unterminated literals and invalid escape sequences result in a
+// failure with an *Error that contains the position of the problem.
+func StringLiteral(opts StringLiteralOptions) Function {
+	return func(pi Input) Result {
+		return stringLiteral(pi, opts)
+	}
+}
+
+// DoubleQuotedString captures a double quoted string which supports the standard escapes,
+// e.g. "Hello\n".
+var DoubleQuotedString = StringLiteral(StringLiteralOptions{
+	Quotes: `"`,
+	Escape: '\\',
+})
+
+// SingleQuotedString captures a single quoted string which supports the standard escapes,
+// e.g. 'Hello\n'.
+var SingleQuotedString = StringLiteral(StringLiteralOptions{
+	Quotes: `'`,
+	Escape: '\\',
+})
+
+// RawString captures a backtick quoted string which doesn't support escapes, and may span
+// multiple lines, as in Go.
+var RawString = StringLiteral(StringLiteralOptions{
+	Quotes:    "`",
+	MultiLine: true,
+})
+
+// SQLString captures a single quoted string where the quote is escaped by doubling it, e.g.
+//
+//	'it''s'
+var SQLString = StringLiteral(StringLiteralOptions{
+	Quotes:        `'`,
+	DoubledQuotes: true,
+	MultiLine:     true,
+})
+
+const standardEscapes = `abfnrtv\'"xuU01234567`
+
+func stringLiteral(pi Input, opts StringLiteralOptions) Result {
+	name := "string literal"
+	start := pi.Index()
+	pos := PosOf(pi)
+	quote, err := peek(pi)
+	if err != nil || !strings.ContainsRune(opts.Quotes, quote) {
+		return Failure(name, err)
+	}
+	pi.Advance()
+
+	var sb strings.Builder
+	for {
+		runePos := PosOf(pi)
+		r, err := pi.Advance()
+		if err != nil {
+			rewind(pi, int(pi.Index()-start))
+			return Failure(name, Errorf(pos, "unterminated string literal"))
+		}
+		if r == quote {
+			if opts.DoubledQuotes {
+				if next, err := peek(pi); err == nil && next == quote {
+					pi.Advance()
+					sb.WriteRune(quote)
+					continue
+				}
+			}
+			return Success(name, sb.String(), nil)
+		}
+		if opts.Escape != 0 && r == opts.Escape {
+			if err := decodeEscape(pi, runePos, opts, &sb); err != nil {
+				rewind(pi, int(pi.Index()-start))
+				return Failure(name, err)
+			}
+			continue
+		}
+		if !opts.MultiLine && (r == '\n' || r == '\r') {
+			rewind(pi, int(pi.Index()-start))
+			return Failure(name, Errorf(runePos, "newline in string literal"))
+		}
+		if opts.DisallowControlRunes && r < 0x20 {
+			rewind(pi, int(pi.Index()-start))
+			return Failure(name, Errorf(runePos, "invalid control character %U in string literal", r))
+		}
+		sb.WriteRune(r)
+	}
+}
+
+// decodeEscape decodes the escape sequence which follows the escape rune.
+func decodeEscape(pi Input, pos Pos, opts StringLiteralOptions, sb *strings.Builder) error {
+	r, err := pi.Advance()
+	if err != nil {
+		return Errorf(pos, "unterminated escape sequence")
+	}
+	allowed := opts.Escapes
+	if allowed == "" {
+		allowed = standardEscapes
+	}
+	if !strings.ContainsRune(allowed, r) {
+		return Errorf(pos, "invalid escape sequence %q", string(opts.Escape)+string(r))
+	}
+	switch r {
+	case 'a':
+		sb.WriteRune('\a')
+	case 'b':
+		sb.WriteRune('\b')
+	case 'f':
+		sb.WriteRune('\f')
+	case 'n':
+		sb.WriteRune('\n')
+	case 'r':
+		sb.WriteRune('\r')
+	case 't':
+		sb.WriteRune('\t')
+	case 'v':
+		sb.WriteRune('\v')
+	case 'x':
+		v, err := escapeDigits(pi, pos, 16, 2, 2)
+		if err != nil {
+			return err
+		}
+		sb.WriteByte(byte(v))
+	case 'u':
+		v, err := escapeDigits(pi, pos, 16, 4, 4)
+		if err != nil {
+			return err
+		}
+		if utf16.IsSurrogate(v) {
+			v = lowSurrogate(pi, opts.Escape, v)
+		}
+		sb.WriteRune(v)
+	case 'U':
+		v, err := escapeDigits(pi, pos, 16, 8, 8)
+		if err != nil {
+			return err
+		}
+		if !utf8.ValidRune(v) {
+			return Errorf(pos, "escape sequence is an invalid Unicode code point %U", v)
+		}
+		sb.WriteRune(v)
+	case '0', '1', '2', '3', '4', '5', '6', '7':
+		pi.Retreat()
+		v, err := escapeDigits(pi, pos, 8, 1, 3)
+		if err != nil {
+			return err
+		}
+		if v > 0377 {
+			return Errorf(pos, "octal escape value %d > 255", v)
+		}
+		sb.WriteByte(byte(v))
+	default:
+		sb.WriteRune(r)
+	}
+	return nil
+}
+
+// lowSurrogate completes a UTF-16 surrogate pair written as two \u escapes, e.g.
+// \uD83D\uDE00. If the high surrogate isn't followed by a low surrogate, utf8.RuneError is
+// returned, in the same way as encoding/json.
+func lowSurrogate(pi Input, escape rune, high rune) rune {
+	start := pi.Index()
+	if acceptRune(pi, string(escape)) != "" && acceptRune(pi, "u") != "" {
+		low, err := escapeDigits(pi, Pos{}, 16, 4, 4)
+		if err == nil {
+			if r := utf16.DecodeRune(high, low); r != utf8.RuneError {
+				return r
+			}
+		}
+	}
+	rewind(pi, int(pi.Index()-start))
+	return utf8.RuneError
+}
+
+// escapeDigits reads between min and max digits of the base.
+func escapeDigits(pi Input, pos Pos, base, min, max int) (v rune, err error) {
+	var count int
+	for count < max {
+		r, err := peek(pi)
+		if err != nil || digitValue(r) >= base {
+			break
+		}
+		pi.Advance()
+		v = v*rune(base) + rune(digitValue(r))
+		count++
+	}
+	if count < min {
+		return 0, Errorf(pos, "escape sequence requires %d digits", min)
+	}
+	return v, nil
+}
@@ -0,0 +1,219 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/a-h/lexical/input"
+)
+
+func TestStringLiteral(t *testing.T) {
+	jsonString := StringLiteral(StringLiteralOptions{
+		Quotes:               `"`,
+		Escape:               '\\',
+		Escapes:              `"\/bfnrtu`,
+		DisallowControlRunes: true,
+	})
+	tests := []struct {
+		input         string
+		parser        Function
+		expected      bool
+		expectedItem  interface{}
+		expectedError string
+		expectedIndex int64
+	}{
+		{
+			input:         `"hello"`,
+			parser:        DoubleQuotedString,
+			expected:      true,
+			expectedItem:  "hello",
+			expectedIndex: 7,
+		},
+		{
+			input:         `"" rest`,
+			parser:        DoubleQuotedString,
+			expected:      true,
+			expectedItem:  "",
+			expectedIndex: 2,
+		},
+		{
+			input:         `hello`,
+			parser:        DoubleQuotedString,
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         `'hello'`,
+			parser:        DoubleQuotedString,
+			expected:      false,
+			expectedIndex: 0,
+		},
+		{
+			input:         `"a\tb\n\\\"\'"`,
+			parser:        DoubleQuotedString,
+			expected:      true,
+			expectedItem:  "a\tb\n\\\"'",
+			expectedIndex: 14,
+		},
+		{
+			input:         `"\x41é\U0001F600\101\0"`,
+			parser:        DoubleQuotedString,
+			expected:      true,
+			expectedItem:  "Aé😀A\x00",
+			expectedIndex: 23,
+		},
+		{
+			input:         `"\xff\377\xc3\xa9\u00ff"`,
+			parser:        DoubleQuotedString,
+			expected:      true,
+			expectedItem:  "\xff\xffé\u00ff",
+			expectedIndex: 24,
+		},
+		{
+			input:         `"\a\b\f\r\v"`,
+			parser:        DoubleQuotedString,
+			expected:      true,
+			expectedItem:  "\a\b\f\r\v",
+			expectedIndex: 12,
+		},
+		{
+			input:         `"unterminated`,
+			parser:        DoubleQuotedString,
+			expected:      false,
+			expectedError: "line 1, column 0: unterminated string literal",
+			expectedIndex: 0,
+		},
+		{
+			input:         `x = "a\qb"`,
+			parser:        All(WithStringConcatCombiner, String("x = "), DoubleQuotedString),
+			expected:      false,
+			expectedError: `line 1, column 6: invalid escape sequence "\\q"`,
+			expectedIndex: 0,
+		},
+		{
+			input:         `"\x4"`,
+			parser:        DoubleQuotedString,
+			expected:      false,
+			expectedError: "line 1, column 1: escape sequence requires 2 digits",
+			expectedIndex: 0,
+		},
+		{
+			input:         `"\U00110000"`,
+			parser:        DoubleQuotedString,
+			expected:      false,
+			expectedError: "line 1, column 1: escape sequence is an invalid Unicode code point U+110000",
+			expectedIndex: 0,
+		},
+		{
+			input:         `"\777"`,
+			parser:        DoubleQuotedString,
+			expected:      false,
+			expectedError: "line 1, column 1: octal escape value 511 > 255",
+			expectedIndex: 0,
+		},
+		{
+			input:         "\"a\nb\"",
+			parser:        DoubleQuotedString,
+			expected:      false,
+			expectedError: "line 1, column 2: newline in string literal",
+			expectedIndex: 0,
+		},
+		{
+			input:         `'single'`,
+			parser:        SingleQuotedString,
+			expected:      true,
+			expectedItem:  "single",
+			expectedIndex: 8,
+		},
+		{
+			input:         "`raw\\n\nstring`",
+			parser:        RawString,
+			expected:      true,
+			expectedItem:  "raw\\n\nstring",
+			expectedIndex: 14,
+		},
+		{
+			input:         `'it''s' rest`,
+			parser:        SQLString,
+			expected:      true,
+			expectedItem:  "it's",
+			expectedIndex: 7,
+		},
+		{
+			input:         `'''' rest`,
+			parser:        SQLString,
+			expected:      true,
+			expectedItem:  "'",
+			expectedIndex: 4,
+		},
+		{
+			input:         `'a'`,
+			parser:        StringLiteral(StringLiteralOptions{Quotes: `"'`, Escape: '\\'}),
+			expected:      true,
+			expectedItem:  "a",
+			expectedIndex: 3,
+		},
+		{
+			input:         `"a'"`,
+			parser:        StringLiteral(StringLiteralOptions{Quotes: `"'`, Escape: '\\'}),
+			expected:      true,
+			expectedItem:  "a'",
+			expectedIndex: 4,
+		},
+		{
+			input:         `"\/\uD83D\uDE00"`,
+			parser:        jsonString,
+			expected:      true,
+			expectedItem:  "/😀",
+			expectedIndex: 16,
+		},
+		{
+			input:         `"\uD83Dx"`,
+			parser:        jsonString,
+			expected:      true,
+			expectedItem:  "�x",
+			expectedIndex: 9,
+		},
+		{
+			input:         `"\x41"`,
+			parser:        jsonString,
+			expected:      false,
+			expectedError: `line 1, column 1: invalid escape sequence "\\x"`,
+			expectedIndex: 0,
+		},
+		{
+			input:         "\"a\tb\"",
+			parser:        jsonString,
+			expected:      false,
+			expectedError: "line 1, column 2: invalid control character U+0009 in string literal",
+			expectedIndex: 0,
+		},
+	}
+
+	for i, test := range tests {
+		pi := input.NewFromString(test.input)
+		result := test.parser(pi)
+		if result.Success != test.expected {
+			t.Errorf("test %v: for input '%v' expected %v but got %v", i, test.input, test.expected, result)
+		}
+		if test.expected && result.Item != test.expectedItem {
+			t.Errorf("test %v: for input '%v' expected item %q but got %q", i, test.input, test.expectedItem, result.Item)
+		}
+		var actualError string
+		if result.Error != nil {
+			actualError = result.Error.Error()
+		}
+		if actualError != test.expectedError {
+			t.Errorf("test %v: for input '%v' expected error '%v' but got '%v'", i, test.input, test.expectedError, actualError)
+		}
+		if test.expectedIndex != pi.Index() {
+			t.Errorf("test %v: for input '%v' expected index %d, got %d", i, test.input, test.expectedIndex, pi.Index())
+		}
+	}
+}
+
+func BenchmarkStringLiteral(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		DoubleQuotedString(input.NewFromString(`"ABCDEFGHIJKLM\nNOPQRSTUVWXYZ"`))
+	}
+}
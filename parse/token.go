@@ -0,0 +1,196 @@
+package parse
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Token is an item produced by a lexer, e.g. a keyword, an identifier or a number, which can be
+// captured by the token parsers from a TokenInput.
+type Token struct {
+	// Type is the type of the token, e.g. "keyword".
+	Type string
+	// Item is the item of the parser which captured the token.
+	Item interface{}
+	// Text is the text that the token was captured from.
+	Text  string
+	Range Range
+}
+
+// String returns the type and text of the token.
+func (t Token) String() string {
+	return t.Type + " " + strconv.Quote(t.Text)
+}
+
+// TokenInput is an Input over tokens rather than runes. Each token is a single position of the
+// input, so the combinators, which only move the input forwards and backwards, work on tokens
+// in the same way as runes, e.g. Many(combiner, 1, 0, TokenOfType("number")).
+type TokenInput interface {
+	Input
+	// PeekToken returns the next token without consuming it.
+	PeekToken() (Token, error)
+	// AdvanceToken consumes the next token and returns it.
+	AdvanceToken() (Token, error)
+}
+
+// TokenStream is a TokenInput which reads tokens from a function, e.g. the Next method of a
+// lexer, and buffers them until they're collected.
+//
+// Advance and Peek return the first rune of each token's text, so rune parsers, such as
+// Rune(','), can match single rune tokens, but the token parsers should be used for everything
+// else.
+type TokenStream struct {
+	next func() (Token, error)
+	// buffer holds the tokens which have been read since the last call to Collect. The first
+	// token in the buffer is at index start.
+	buffer         []Token
+	start, current int64
+	// end is the end of the last token which was read, which is the position of the input
+	// once all of the tokens have been read.
+	end Pos
+	err error
+}
+
+// NewTokenStream creates a TokenStream which reads tokens from the function until it returns
+// an error. At the end of the tokens, the function should return io.EOF.
+func NewTokenStream(next func() (Token, error)) *TokenStream {
+	return &TokenStream{
+		next: next,
+		end:  Pos{Line: 1},
+	}
+}
+
+// NewTokenStreamFromSlice creates a TokenStream which reads the tokens.
+func NewTokenStreamFromSlice(tokens []Token) *TokenStream {
+	ts := NewTokenStream(func() (Token, error) {
+		return Token{}, io.EOF
+	})
+	ts.buffer = tokens[:len(tokens):len(tokens)]
+	if len(tokens) > 0 {
+		ts.end = tokens[len(tokens)-1].Range.End
+	}
+	return ts
+}
+
+// ErrStartOfTokens is the error used when the input has retreated to the first token that
+// hasn't been collected, and can't retreat any further.
+var ErrStartOfTokens = errors.New("parse: can't retreat before the start of the tokens")
+
+// AdvanceToken consumes the next token and returns it.
+func (ts *TokenStream) AdvanceToken() (t Token, err error) {
+	i := int(ts.current - ts.start)
+	if i == len(ts.buffer) {
+		if ts.err != nil {
+			return t, ts.err
+		}
+		if t, err = ts.next(); err != nil {
+			ts.err = err
+			return t, err
+		}
+		ts.buffer = append(ts.buffer, t)
+		ts.end = t.Range.End
+	}
+	ts.current++
+	return ts.buffer[i], nil
+}
+
+// PeekToken returns the next token without consuming it.
+func (ts *TokenStream) PeekToken() (t Token, err error) {
+	if t, err = ts.AdvanceToken(); err == nil {
+		ts.current--
+	}
+	return t, err
+}
+
+// Advance consumes the next token, and returns the first rune of its text.
+func (ts *TokenStream) Advance() (rune, error) {
+	t, err := ts.AdvanceToken()
+	return firstRune(t), err
+}
+
+// Peek returns the first rune of the next token's text, without consuming it.
+func (ts *TokenStream) Peek() (rune, error) {
+	t, err := ts.PeekToken()
+	return firstRune(t), err
+}
+
+func firstRune(t Token) rune {
+	r, _ := utf8.DecodeRuneInString(t.Text)
+	return r
+}
+
+// Retreat unconsumes the last token, and returns the first rune of its text.
+func (ts *TokenStream) Retreat() (rune, error) {
+	if ts.current == ts.start {
+		return 0, ErrStartOfTokens
+	}
+	ts.current--
+	return firstRune(ts.buffer[ts.current-ts.start]), nil
+}
+
+// Collect returns the text of the tokens which have been consumed since the last call to
+// Collect, and releases them.
+func (ts *TokenStream) Collect() string {
+	n := ts.current - ts.start
+	var sb strings.Builder
+	for _, t := range ts.buffer[:n] {
+		sb.WriteString(t.Text)
+	}
+	ts.buffer = ts.buffer[n:]
+	ts.start = ts.current
+	return sb.String()
+}
+
+// Position returns the line and column of the start of the next token, or the end of the last
+// token if there are no more tokens.
+func (ts *TokenStream) Position() (line, column int) {
+	if t, err := ts.PeekToken(); err == nil {
+		return t.Range.Start.Line, t.Range.Start.Column
+	}
+	return ts.end.Line, ts.end.Column
+}
+
+// Index returns the number of tokens which have been consumed.
+func (ts *TokenStream) Index() int64 {
+	return ts.current
+}
+
+// ErrNotTokenInput is the error of the token parsers when they're used on an Input which isn't a
+// TokenInput.
+var ErrNotTokenInput = errors.New("parse: token parsers require a TokenInput")
+
+// TokenWhere captures the next token of a TokenInput if the predicate returns true, and returns
+// the Token.
+func TokenWhere(name string, predicate func(t Token) bool) Function {
+	return func(pi Input) Result {
+		ti, ok := pi.(TokenInput)
+		if !ok {
+			return Failure(name, ErrNotTokenInput)
+		}
+		t, err := ti.PeekToken()
+		if err != nil {
+			return Failure(name, err)
+		}
+		if !predicate(t) {
+			return Failure(name, nil)
+		}
+		_, err = ti.AdvanceToken()
+		return Success(name, t, err)
+	}
+}
+
+// TokenOfType captures the next token of a TokenInput if it's one of the types, and returns the
+// Token.
+func TokenOfType(types ...string) Function {
+	return TokenWhere("token of type "+strings.Join(types, " or "), func(t Token) bool {
+		for _, typ := range types {
+			if t.Type == typ {
+				return true
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,157 @@
+package parse
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/a-h/lexical/input"
+)
+
+// tokensOf splits the text at spaces, and returns the words as number, punctuation or word
+// tokens.
+func tokensOf(text string) (tokens []Token) {
+	var col int
+	for _, w := range strings.Split(text, " ") {
+		typ := "word"
+		switch {
+		case strings.IndexFunc(w, func(r rune) bool { return !unicode.IsDigit(r) }) < 0:
+			typ = "number"
+		case len(w) == 1 && unicode.IsPunct(rune(w[0])):
+			typ = "punctuation"
+		}
+		start := Pos{Index: int64(col), Line: 1, Column: col}
+		col += len(w)
+		tokens = append(tokens, Token{
+			Type:  typ,
+			Item:  w,
+			Text:  w,
+			Range: Range{Start: start, End: Pos{Index: int64(col), Line: 1, Column: col}},
+		})
+		col++
+	}
+	return tokens
+}
+
+func TestTokenStream(t *testing.T) {
+	ts := NewTokenStreamFromSlice(tokensOf("sum ( 1 , 2 )"))
+	if line, col := ts.Position(); line != 1 || col != 0 {
+		t.Errorf("expected position 1, 0 at the start, got %v, %v", line, col)
+	}
+	tok, err := ts.PeekToken()
+	if err != nil || tok.Text != "sum" || ts.Index() != 0 {
+		t.Errorf("expected to peek the sum token without consuming it, got %v, %v at %v", tok, err, ts.Index())
+	}
+	for _, expected := range []string{"sum", "("} {
+		if tok, err = ts.AdvanceToken(); err != nil || tok.Text != expected {
+			t.Errorf("expected %q, got %v, %v", expected, tok, err)
+		}
+	}
+	if line, col := ts.Position(); line != 1 || col != 6 {
+		t.Errorf("expected position 1, 6 at the third token, got %v, %v", line, col)
+	}
+	if r, err := ts.Retreat(); err != nil || r != '(' {
+		t.Errorf("expected to retreat over '(', got %q, %v", r, err)
+	}
+	if r, err := ts.Advance(); err != nil || r != '(' {
+		t.Errorf("expected to advance over '(', got %q, %v", r, err)
+	}
+	if s := ts.Collect(); s != "sum(" {
+		t.Errorf("expected to collect %q, got %q", "sum(", s)
+	}
+	if _, err := ts.Retreat(); err != ErrStartOfTokens {
+		t.Errorf("expected to be unable to retreat past the collected tokens, got %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		ts.AdvanceToken()
+	}
+	if _, err := ts.AdvanceToken(); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+	if ts.Index() != 6 {
+		t.Errorf("expected index 6 at the end, got %v", ts.Index())
+	}
+	if line, col := ts.Position(); line != 1 || col != 13 {
+		t.Errorf("expected position 1, 13 at the end, got %v, %v", line, col)
+	}
+}
+
+func TestTokenStreamErrors(t *testing.T) {
+	lexErr := errors.New("invalid token")
+	tokens := tokensOf("a b")
+	ts := NewTokenStream(func() (Token, error) {
+		if len(tokens) == 0 {
+			return Token{}, lexErr
+		}
+		t := tokens[0]
+		tokens = tokens[1:]
+		return t, nil
+	})
+	r := Many(asSlice, 0, 0, TokenOfType("word"))(ts)
+	if r.Success || r.Error != lexErr {
+		t.Errorf("expected the lexer's error, got %v", r)
+	}
+	if ts.Index() != 0 {
+		t.Errorf("expected the input to be rewound, got index %v", ts.Index())
+	}
+}
+
+func TestTokenParsers(t *testing.T) {
+	// call captures a function call, e.g. sum ( 1 , 2 ), and returns the function name and the
+	// number of arguments.
+	call := All(func(items []interface{}) (interface{}, bool) {
+		return items[0].(Token).Text + "/" + strconv.Itoa(len(items[2].([]interface{}))), true
+	},
+		TokenOfType("word"),
+		TokenWhere("(", func(t Token) bool { return t.Text == "(" }),
+		Many(asSlice, 0, 0, Then(asSlice, TokenOfType("number", "word"), Optional(asSlice, Rune(',')))),
+		Rune(')'),
+		EOF,
+	)
+	tests := []struct {
+		input        string
+		expected     bool
+		expectedItem string
+	}{
+		{input: "sum ( 1 , 2 )", expected: true, expectedItem: "sum/2"},
+		{input: "now ( )", expected: true, expectedItem: "now/0"},
+		{input: "max ( a , 1 , b )", expected: true, expectedItem: "max/3"},
+		{input: "1 ( )", expected: false},
+		{input: "sum ( 1 , 2", expected: false},
+		{input: "sum ( 1 , 2 ) )", expected: false},
+	}
+	for i, test := range tests {
+		result := call(NewTokenStreamFromSlice(tokensOf(test.input)))
+		if result.Success != test.expected {
+			t.Errorf("test %v: for input '%v' expected %v but got %v", i, test.input, test.expected, result)
+		}
+		if test.expected && result.Item != test.expectedItem {
+			t.Errorf("test %v: for input '%v' expected item '%v' but got '%v'", i, test.input, test.expectedItem, result.Item)
+		}
+	}
+}
+
+func asSlice(items []interface{}) (interface{}, bool) {
+	return items, true
+}
+
+func TestTokenParsersRequireTokenInput(t *testing.T) {
+	r := Any(TokenOfType("word"), Rune('a'))(input.NewFromString("a"))
+	if r.Success || r.Error != ErrNotTokenInput {
+		t.Errorf("expected ErrNotTokenInput, got %v", r)
+	}
+}
+
+func BenchmarkTokenParsers(b *testing.B) {
+	b.ReportAllocs()
+	tokens := tokensOf(strings.Repeat("a 1 , ", 100))
+	list := Many(asSlice, 0, 0, TokenOfType("word", "number", "punctuation"))
+	for n := 0; n < b.N; n++ {
+		if r := list(NewTokenStreamFromSlice(tokens)); !r.Success {
+			b.Fatal(r)
+		}
+	}
+}
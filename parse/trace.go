@@ -0,0 +1,106 @@
+package parse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Trace is the record of the Named parsers that a Run tried, in the order that they were
+// entered and exited.
+type Trace struct {
+	Events []Event
+	depth  int
+}
+
+// Event is the entry to, or exit from, a Named parser.
+type Event struct {
+	// Exit is false when the parser is entered, and true when it returns.
+	Exit bool `json:"exit"`
+	// Name is the name of the parser.
+	Name string `json:"name"`
+	// Depth is the number of Named parsers that the parser was called from.
+	Depth int `json:"depth"`
+	// Pos is the position of the input when the parser was entered, or when it returned.
+	Pos Pos `json:"pos"`
+	// Success, Text and Error are the outcome of the parser, set when it returns. Text is the
+	// input that the parser consumed. For a TokenInput, it's the first rune of each token.
+	Success bool   `json:"success,omitempty"`
+	Text    string `json:"text,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// Backtracked is the number of runes that the parser read beyond the position that it
+	// returned at, which were given back to the input, e.g. by a failed alternative of Any, or by
+	// reading ahead to find the end of a Span.
+	Backtracked int64 `json:"backtracked,omitempty"`
+}
+
+// String returns the event as a line of a text trace, indented by its depth.
+func (e Event) String() string {
+	var sb strings.Builder
+	sb.WriteString(strings.Repeat("  ", e.Depth))
+	if !e.Exit {
+		fmt.Fprintf(&sb, "→ %s at %v", e.Name, e.Pos)
+		return sb.String()
+	}
+	mark := cross
+	if e.Success {
+		mark = tick
+	}
+	fmt.Fprintf(&sb, "← %s %s%q at %v", e.Name, mark, e.Text, e.Pos)
+	if e.Backtracked > 0 {
+		fmt.Fprintf(&sb, ", backtracked %d", e.Backtracked)
+	}
+	if e.Error != "" {
+		fmt.Fprintf(&sb, ", err: %s", e.Error)
+	}
+	return sb.String()
+}
+
+// String returns the trace as text, with each event on its own line, indented by its depth.
+func (t *Trace) String() string {
+	var sb strings.Builder
+	t.WriteText(&sb)
+	return sb.String()
+}
+
+// WriteText writes the trace as text, with each event on its own line, indented by its depth.
+func (t *Trace) WriteText(w io.Writer) error {
+	for _, e := range t.Events {
+		if _, err := fmt.Fprintln(w, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the trace as a log of JSON events, one per line.
+func (t *Trace) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, e := range t.Events {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Trace) enter(name string, start Pos) {
+	t.Events = append(t.Events, Event{Name: name, Depth: t.depth, Pos: start})
+	t.depth++
+}
+
+func (t *Trace) exit(e Event) {
+	t.depth--
+	e.Exit = true
+	e.Depth = t.depth
+	t.Events = append(t.Events, e)
+}
+
+// text returns the runes of the input between the indices.
+func (r *Run) text(from, to int64) string {
+	if from >= to || from < r.start || to-r.start > int64(len(r.runes)) {
+		return ""
+	}
+	return string(r.runes[from-r.start : to-r.start])
+}
@@ -0,0 +1,102 @@
+package parse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+)
+
+// assignment parses "name = value", where the value is a number or a name.
+var assignment = Named("assignment", All(WithStringConcatCombiner,
+	Named("name", Span(ClassRange('a', 'z'), 1, 0)),
+	Rune('='),
+	Any(
+		Named("float", All(WithStringConcatCombiner, Span(ClassRange('0', '9'), 1, 0), Rune('.'), Span(ClassRange('0', '9'), 1, 0))),
+		Named("integer", Span(ClassRange('0', '9'), 1, 0)),
+		Named("name", Span(ClassRange('a', 'z'), 1, 0)),
+	),
+))
+
+func TestTrace(t *testing.T) {
+	// Reading ahead to find the end of a span, and then giving the rune back, is backtracking.
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input: "a=12",
+			expected: `→ assignment at line 1, column 0
+  → name at line 1, column 0
+  ← name ✓ "a" at line 1, column 1, backtracked 1
+  → float at line 1, column 2
+  ← float ✗ "" at line 1, column 2, backtracked 2, err: EOF
+  → integer at line 1, column 2
+  ← integer ✓ "12" at line 1, column 4
+← assignment ✓ "a=12" at line 1, column 4
+`,
+		},
+		{
+			input: "a=b",
+			expected: `→ assignment at line 1, column 0
+  → name at line 1, column 0
+  ← name ✓ "a" at line 1, column 1, backtracked 1
+  → float at line 1, column 2
+  ← float ✗ "" at line 1, column 2, backtracked 1
+  → integer at line 1, column 2
+  ← integer ✗ "" at line 1, column 2, backtracked 1
+  → name at line 1, column 2
+  ← name ✓ "b" at line 1, column 3
+← assignment ✓ "a=b" at line 1, column 3
+`,
+		},
+		{
+			input: "ab",
+			expected: `→ assignment at line 1, column 0
+  → name at line 1, column 0
+  ← name ✓ "ab" at line 1, column 2
+← assignment ✗ "" at line 1, column 0, backtracked 2, err: EOF
+`,
+		},
+	}
+	g := Compile(assignment)
+	g.Tracing = true
+	for i, test := range tests {
+		_, run := g.Parse(context.Background(), input.NewFromString(test.input))
+		if actual := run.Trace.String(); actual != test.expected {
+			t.Errorf("test %v: for input '%v' expected:\n%v\ngot:\n%v", i, test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestTraceJSON(t *testing.T) {
+	g := Compile(assignment)
+	g.Tracing = true
+	_, run := g.Parse(context.Background(), input.NewFromString("x=1"))
+	var buf bytes.Buffer
+	if err := run.Trace.WriteJSON(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(run.Trace.Events) {
+		t.Fatalf("expected a line for each of the %d events, got %d", len(run.Trace.Events), len(lines))
+	}
+	var last Event
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &last); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Event{Exit: true, Name: "assignment", Pos: Pos{Index: 3, Line: 1, Column: 3}, Success: true, Text: "x=1"}
+	if last != expected {
+		t.Errorf("expected %+v, got %+v", expected, last)
+	}
+}
+
+func TestTraceIsOptIn(t *testing.T) {
+	_, run := Compile(assignment).Parse(context.Background(), input.NewFromString("x=1"))
+	if run.Trace != nil {
+		t.Errorf("expected no trace unless the grammar is tracing")
+	}
+}
@@ -0,0 +1,27 @@
+//go:build go1.23
+
+package scanner
+
+import (
+	"io"
+	"iter"
+)
+
+// All returns an iterator over the remaining tokens of the input. Iteration stops at the end of
+// the input, or after the first error is yielded.
+//
+// All is only built with Go 1.23 or later, because it uses the iter package. The rest of the
+// package supports the Go version of the module, so with earlier versions, use Scan instead.
+func (s *Scanner) All() iter.Seq2[Token, error] {
+	return func(yield func(Token, error) bool) {
+		for {
+			t, err := s.NextToken()
+			if err == io.EOF {
+				return
+			}
+			if !yield(t, err) || err != nil {
+				return
+			}
+		}
+	}
+}
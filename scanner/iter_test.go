@@ -0,0 +1,39 @@
+//go:build go1.23
+
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+)
+
+func TestAll(t *testing.T) {
+	s, _ := NewWithModes(input.NewFromString("if a // b\n  return 1"), Modes{"": testLexer}, "")
+	var actual []string
+	for tok, err := range s.All() {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		actual = append(actual, tok.Text)
+	}
+	if strings.Join(actual, " ") != "if a return 1" {
+		t.Errorf("expected the tokens 'if a return 1', got %q", actual)
+	}
+}
+
+func TestAllStopsAtError(t *testing.T) {
+	s, _ := NewWithModes(input.NewFromString("a # b"), Modes{"": testLexer}, "")
+	var tokens, errors int
+	for _, err := range s.All() {
+		if err != nil {
+			errors++
+			continue
+		}
+		tokens++
+	}
+	if tokens != 1 || errors != 1 {
+		t.Errorf("expected 1 token and 1 error, got %d and %d", tokens, errors)
+	}
+}
@@ -0,0 +1,105 @@
+package scanner
+
+import (
+	"io"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// Rule is a rule of a Lexer, which captures one type of token.
+type Rule struct {
+	// Type is the type of the tokens that the rule captures, e.g. "identifier".
+	Type string
+	// Parser captures the token.
+	Parser parse.Function
+	// Skip is true if the tokens aren't passed on to the parser, e.g. whitespace and comments.
+	Skip bool
+	// Push, Pop and Switch change the mode of a Scanner which has Modes after the token has been
+	// captured. Push enters the named mode, e.g. an expression mode after "${", and Pop returns
+	// to the mode that was pushed from. Switch replaces the current mode with the named mode.
+	// A rule should only change the mode in one way.
+	Push   string
+	Pop    bool
+	Switch string
+	// Priority breaks ties between rules which capture the same amount of input, e.g. so that a
+	// keyword rule wins over an identifier rule. The rule with the highest priority wins, and if
+	// the priorities are the same, the first rule wins.
+	Priority int
+}
+
+// Lexer captures the longest token that any of its rules match, in the same way as lex and
+// most other lexer generators, so that the rules can be written in any order. For example,
+// "<=" is captured as a single token, even if the rule for "<" comes first, and "iffy" is an
+// identifier, rather than the keyword "if" followed by "fy".
+type Lexer struct {
+	rules []Rule
+}
+
+// NewLexer creates a Lexer from the rules.
+func NewLexer(rules ...Rule) *Lexer {
+	return &Lexer{
+		rules: rules,
+	}
+}
+
+// Parse tries each of the rules from the current position of the input, and captures the
+// longest match. The name of the result is the Type of the rule, and the item is the item of
+// the rule's parser, so the Lexer can be used as the parser of a Scanner, e.g.
+// New(stream, lexer.Parse).
+//
+// Rules which match without capturing any input are ignored. If any rule fails with an error,
+// such as a *parse.Error for an unterminated string, the error is returned.
+func (l *Lexer) Parse(pi parse.Input) parse.Result {
+	r, _ := l.parse(pi)
+	return r
+}
+
+// parse captures the longest match, and returns the rule which matched.
+func (l *Lexer) parse(pi parse.Input) (parse.Result, *Rule) {
+	name := "lexer"
+	start := pi.Index()
+	best, longest := -1, int64(0)
+	var result parse.Result
+	for i, rule := range l.rules {
+		r := rule.Parser(pi)
+		n := pi.Index() - start
+		parse.RewindTo(pi, start)
+		if !r.Success {
+			if r.Error != nil && r.Error != io.EOF {
+				return parse.Failure(rule.Type, r.Error), nil
+			}
+			continue
+		}
+		if n > longest || (n == longest && n > 0 && rule.Priority > l.rules[best].Priority) {
+			best, longest, result = i, n, r
+		}
+	}
+	if best < 0 {
+		if parse.EOF(pi).Success {
+			return parse.Failure(name, io.EOF), nil
+		}
+		return parse.Failure(name, nil), nil
+	}
+	for i := int64(0); i < longest; i++ {
+		pi.Advance()
+	}
+	result.Name = l.rules[best].Type
+	return result, &l.rules[best]
+}
+
+// Skip returns true if tokens of the type are skipped.
+func (l *Lexer) Skip(typ string) bool {
+	for _, r := range l.rules {
+		if r.Type == typ && r.Skip {
+			return true
+		}
+	}
+	return false
+}
+
+// Tokens returns a parse.TokenInput of the tokens of the input, without the tokens of the rules
+// which are skipped. Tokens which can't be lexed result in an *UnmatchedError.
+func (l *Lexer) Tokens(stream parse.Input) *parse.TokenStream {
+	s, _ := NewWithModes(stream, Modes{"": l}, "")
+	return NewTokenInput(s)
+}
@@ -0,0 +1,152 @@
+package scanner
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/parse"
+)
+
+var ident = parse.Identifier(parse.IsIdentifierStart, parse.IsIdentifierContinue)
+
+// The rules are deliberately in an order where Any would return the wrong token.
+var testLexer = NewLexer(
+	Rule{Type: "operator", Parser: parse.RuneIn("<=>!")},
+	Rule{Type: "operator", Parser: parse.OneOfStrings("<=", ">=", "==", "!=", "<<=")},
+	Rule{Type: "integer", Parser: parse.Int(10, 64)},
+	Rule{Type: "float", Parser: parse.Float(64)},
+	Rule{Type: "identifier", Parser: ident},
+	Rule{Type: "keyword", Parser: parse.OneOfStrings("if", "else", "return"), Priority: 1},
+	Rule{Type: "string", Parser: parse.DoubleQuotedString},
+	Rule{Type: "whitespace", Parser: parse.Span(parse.ClassOf(" \t\r\n"), 1, 0), Skip: true},
+	Rule{Type: "comment", Parser: parse.Then(parse.WithStringConcatCombiner, parse.String("//"), parse.Span(parse.ClassOf("\n").Not(), 0, 0)), Skip: true},
+	// Rules which don't capture anything are ignored.
+	Rule{Type: "empty", Parser: parse.Span(parse.ClassOf("x"), 0, 0)},
+)
+
+// lex returns the type and text of each token of the input.
+func lex(l *Lexer, src string) (tokens []string, err error) {
+	ti := l.Tokens(input.NewFromString(src))
+	for {
+		t, err := ti.AdvanceToken()
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return tokens, err
+		}
+		tokens = append(tokens, t.Type+" "+t.Text)
+	}
+}
+
+func TestLexer(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			input:    "a <= b",
+			expected: []string{"identifier a", "operator <=", "identifier b"},
+		},
+		{
+			input:    "a<<=1",
+			expected: []string{"identifier a", "operator <<=", "integer 1"},
+		},
+		{
+			input:    "x < y",
+			expected: []string{"identifier x", "operator <", "identifier y"},
+		},
+		{
+			input:    "if iffy else",
+			expected: []string{"keyword if", "identifier iffy", "keyword else"},
+		},
+		{
+			input:    "return 1.5 // comment\n2",
+			expected: []string{"keyword return", "float 1.5", "integer 2"},
+		},
+		{
+			input:    `a != "b c"`,
+			expected: []string{"identifier a", "operator !=", `string "b c"`},
+		},
+		{
+			input:    "",
+			expected: nil,
+		},
+	}
+	for i, test := range tests {
+		actual, err := lex(testLexer, test.input)
+		if err != nil {
+			t.Errorf("test %v: for input '%v' unexpected error: %v", i, test.input, err)
+			continue
+		}
+		if strings.Join(actual, ", ") != strings.Join(test.expected, ", ") {
+			t.Errorf("test %v: for input '%v' expected %v, got %v", i, test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestLexerTokenRanges(t *testing.T) {
+	ti := testLexer.Tokens(input.NewFromString("if a\n  <= 10"))
+	expected := []parse.Range{
+		{Start: parse.Pos{Index: 0, Line: 1, Column: 0}, End: parse.Pos{Index: 2, Line: 1, Column: 2}},
+		{Start: parse.Pos{Index: 3, Line: 1, Column: 3}, End: parse.Pos{Index: 4, Line: 1, Column: 4}},
+		{Start: parse.Pos{Index: 7, Line: 2, Column: 2}, End: parse.Pos{Index: 9, Line: 2, Column: 4}},
+		{Start: parse.Pos{Index: 10, Line: 2, Column: 5}, End: parse.Pos{Index: 12, Line: 2, Column: 7}},
+	}
+	for i, e := range expected {
+		tok, err := ti.AdvanceToken()
+		if err != nil {
+			t.Fatalf("token %d: unexpected error: %v", i, err)
+		}
+		if tok.Range != e {
+			t.Errorf("token %d: %v expected range %v, got %v", i, tok, e, tok.Range)
+		}
+	}
+}
+
+func TestLexerErrors(t *testing.T) {
+	_, err := lex(testLexer, "a # b")
+	if ue, ok := err.(*UnmatchedError); !ok || ue.Line != 1 || ue.Column != 2 {
+		t.Errorf("expected an *UnmatchedError at line 1, column 2, got %v", err)
+	}
+	_, err = lex(testLexer, "a \"b")
+	if pe, ok := err.(*parse.Error); !ok || pe.Error() != "line 1, column 2: unterminated string literal" {
+		t.Errorf("expected an unterminated string error, got %v", err)
+	}
+}
+
+func TestLexerPriority(t *testing.T) {
+	// With the same priority, the first rule wins.
+	l := NewLexer(
+		Rule{Type: "first", Parser: parse.String("a")},
+		Rule{Type: "second", Parser: parse.String("a")},
+	)
+	r := l.Parse(input.NewFromString("a"))
+	if !r.Success || r.Name != "first" {
+		t.Errorf("expected the first rule to win, got %v", r)
+	}
+	l = NewLexer(
+		Rule{Type: "first", Parser: parse.String("a")},
+		Rule{Type: "second", Parser: parse.String("a"), Priority: 1},
+	)
+	r = l.Parse(input.NewFromString("a"))
+	if !r.Success || r.Name != "second" {
+		t.Errorf("expected the rule with the highest priority to win, got %v", r)
+	}
+	if l.Skip("whitespace") || !testLexer.Skip("whitespace") {
+		t.Errorf("expected whitespace to be skipped only by the test lexer")
+	}
+}
+
+func BenchmarkLexer(b *testing.B) {
+	b.ReportAllocs()
+	src := strings.Repeat("if a <= 10 { return \"value\" } // comment\n", 20)
+	l := NewLexer(append(testLexer.rules, Rule{Type: "punctuation", Parser: parse.RuneIn("{}")})...)
+	for n := 0; n < b.N; n++ {
+		if _, err := lex(l, src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// Modes are the named Lexers of a Scanner, which it switches between for context-dependent
+// lexing, e.g. string interpolation, heredocs, or CSS and JavaScript embedded in HTML. The rules
+// of each Lexer change the mode with their Push, Pop and Switch fields.
+type Modes map[string]*Lexer
+
+// NewWithModes creates a Scanner which starts in the initial mode.
+func NewWithModes(stream parse.Input, modes Modes, initial string) (*Scanner, error) {
+	s := &Scanner{
+		Input: stream,
+		modes: modes,
+	}
+	if err := s.Switch(initial); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ErrNoMode is returned when a mode is popped, but there's no mode to return to.
+var ErrNoMode = errors.New("scanner: there's no mode to return to")
+
+// Mode returns the name of the current mode.
+func (s *Scanner) Mode() string {
+	return s.mode
+}
+
+// Push enters the named mode, which can be left with Pop.
+func (s *Scanner) Push(mode string) error {
+	current := s.mode
+	if err := s.Switch(mode); err != nil {
+		return err
+	}
+	s.stack = append(s.stack, current)
+	return nil
+}
+
+// Pop returns to the mode that the current mode was pushed from.
+func (s *Scanner) Pop() error {
+	if len(s.stack) == 0 {
+		return ErrNoMode
+	}
+	mode := s.stack[len(s.stack)-1]
+	s.stack = s.stack[:len(s.stack)-1]
+	return s.Switch(mode)
+}
+
+// Switch replaces the current mode with the named mode.
+func (s *Scanner) Switch(mode string) error {
+	l, ok := s.modes[mode]
+	if !ok {
+		return fmt.Errorf("scanner: unknown mode %q", mode)
+	}
+	s.mode, s.lexer, s.Parser = mode, l, l.Parse
+	return nil
+}
+
+// transition changes the mode after a token captured by the rule.
+func (s *Scanner) transition(rule *Rule) error {
+	switch {
+	case rule.Pop:
+		return s.Pop()
+	case rule.Switch != "":
+		return s.Switch(rule.Switch)
+	case rule.Push != "":
+		return s.Push(rule.Push)
+	}
+	return nil
+}
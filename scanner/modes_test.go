@@ -0,0 +1,118 @@
+package scanner
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/parse"
+)
+
+// interpolation lexes expressions which contain strings with interpolated expressions, e.g.
+// "a ${x + "b ${y}"} c".
+var interpolation = Modes{
+	"code": NewLexer(
+		Rule{Type: "whitespace", Parser: parse.Span(parse.ClassOf(" \t\n"), 1, 0), Skip: true},
+		Rule{Type: "identifier", Parser: ident},
+		Rule{Type: "operator", Parser: parse.RuneIn("+-")},
+		Rule{Type: "quote", Parser: parse.Rune('"'), Push: "string"},
+		// Braces within an expression must be balanced, so they push another code mode.
+		Rule{Type: "open", Parser: parse.Rune('{'), Push: "code"},
+		Rule{Type: "close", Parser: parse.Rune('}'), Pop: true},
+	),
+	"string": NewLexer(
+		Rule{Type: "text", Parser: parse.Span(parse.ClassOf(`"$`).Not(), 1, 0)},
+		// A dollar which isn't followed by a brace is text, because "${" is longer.
+		Rule{Type: "text", Parser: parse.Rune('$')},
+		Rule{Type: "interpolation", Parser: parse.String("${"), Push: "code"},
+		Rule{Type: "quote", Parser: parse.Rune('"'), Pop: true},
+	),
+}
+
+func TestModes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    `"a ${x + "b ${y}"} c"`,
+			expected: `quote " | text a  | interpolation ${ | identifier x | operator + | quote " | text b  | interpolation ${ | identifier y | close } | quote " | close } | text  c | quote "`,
+		},
+		{
+			input:    `"$5 {x}" + { a }`,
+			expected: `quote " | text $ | text 5 {x} | quote " | operator + | open { | identifier a | close }`,
+		},
+	}
+	for i, test := range tests {
+		s, err := NewWithModes(input.NewFromString(test.input), interpolation, "code")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ti := NewTokenInput(s)
+		var actual []string
+		for {
+			tok, err := ti.AdvanceToken()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("test %v: for input '%v' unexpected error: %v", i, test.input, err)
+			}
+			actual = append(actual, tok.Type+" "+tok.Text)
+		}
+		if strings.Join(actual, " | ") != test.expected {
+			t.Errorf("test %v: for input '%v' expected:\n%v\ngot:\n%v", i, test.input, test.expected, strings.Join(actual, " | "))
+		}
+		if s.Mode() != "code" {
+			t.Errorf("test %v: for input '%v' expected to finish in code mode, got %q", i, test.input, s.Mode())
+		}
+	}
+}
+
+func TestModeErrors(t *testing.T) {
+	if _, err := NewWithModes(input.NewFromString(""), interpolation, "css"); err == nil || err.Error() != `scanner: unknown mode "css"` {
+		t.Errorf("expected an unknown mode error, got %v", err)
+	}
+
+	// A closing brace at the top level has no mode to return to.
+	s, _ := NewWithModes(input.NewFromString("a\n }"), interpolation, "code")
+	var err error
+	for err == nil {
+		_, err = s.Next()
+	}
+	if err.Error() != "line 2, column 1: scanner: there's no mode to return to" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if pe, ok := err.(*parse.Error); !ok || pe.Err != ErrNoMode {
+		t.Errorf("expected a *parse.Error containing ErrNoMode, got %#v", err)
+	}
+}
+
+func TestModeStack(t *testing.T) {
+	s, _ := NewWithModes(input.NewFromString(""), interpolation, "code")
+	steps := []struct {
+		f        func() error
+		expected string
+	}{
+		{f: func() error { return s.Push("string") }, expected: "string"},
+		{f: func() error { return s.Push("code") }, expected: "code"},
+		{f: func() error { return s.Switch("string") }, expected: "string"},
+		{f: s.Pop, expected: "string"},
+		{f: s.Pop, expected: "code"},
+	}
+	for i, step := range steps {
+		if err := step.f(); err != nil {
+			t.Fatalf("step %d: unexpected error: %v", i, err)
+		}
+		if s.Mode() != step.expected {
+			t.Errorf("step %d: expected mode %q, got %q", i, step.expected, s.Mode())
+		}
+	}
+	if err := s.Pop(); err != ErrNoMode {
+		t.Errorf("expected ErrNoMode, got %v", err)
+	}
+	if err := s.Push("unknown"); err == nil || s.Mode() != "code" {
+		t.Errorf("expected pushing an unknown mode to fail without changing the mode, got %v in %q", err, s.Mode())
+	}
+}
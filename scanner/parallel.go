@@ -0,0 +1,238 @@
+package scanner
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"runtime"
+
+	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/parse"
+)
+
+// Parallel scans record-oriented input, such as newline delimited logs, on multiple goroutines.
+// The input is split into chunks just after a match of the Boundary parser, and each chunk is
+// scanned by its own Scanner. The tokens are passed on in the order of the input, with their
+// positions, and the positions of errors, corrected to be positions within the whole input.
+//
+// The Boundary must only match between records, e.g. a newline can't be the Boundary of a CSV
+// file where quoted fields contain newlines. Positions within the items of tokens aren't
+// corrected.
+type Parallel struct {
+	// Boundary matches the end of a record, e.g. parse.Rune('\n').
+	Boundary parse.Function
+	// Parser captures each token.
+	Parser parse.Function
+	// ChunkSize is the approximate size of each chunk in bytes. A chunk ends at the first match
+	// of the Boundary after ChunkSize bytes. If it's zero or less, DefaultChunkSize is used.
+	ChunkSize int64
+	// MaxRecordSize limits how far past the ChunkSize a chunk can extend to find a match of the
+	// Boundary, so that the size of a chunk, and the memory used to scan it, is bounded. If the
+	// Boundary isn't found, Scan fails with ErrNoBoundary. If it's zero or less,
+	// DefaultMaxRecordSize is used.
+	MaxRecordSize int64
+	// Workers is the number of goroutines which scan chunks.
+	Workers int
+}
+
+// The defaults of the ChunkSize and MaxRecordSize of a Parallel scanner.
+const (
+	DefaultChunkSize     = 4 << 20
+	DefaultMaxRecordSize = 1 << 20
+)
+
+// ErrNoBoundary is the error of Parallel.Scan when the Boundary isn't found within the
+// MaxRecordSize of the end of a chunk, e.g. because a record is larger than MaxRecordSize.
+var ErrNoBoundary = errors.New("scanner: the boundary wasn't found within the maximum record size")
+
+// NewParallel creates a Parallel scanner which splits the input at the boundary, and captures
+// tokens with the parser. It scans 4MB chunks on as many goroutines as GOMAXPROCS.
+func NewParallel(boundary, parser parse.Function) *Parallel {
+	return &Parallel{
+		Boundary:      boundary,
+		Parser:        parser,
+		ChunkSize:     DefaultChunkSize,
+		MaxRecordSize: DefaultMaxRecordSize,
+		Workers:       runtime.GOMAXPROCS(0),
+	}
+}
+
+// chunk is a range of bytes of the input.
+type chunk struct {
+	start, end int64
+}
+
+// chunkResult holds the tokens of a chunk, along with the position at the end of the chunk.
+type chunkResult struct {
+	tokens []Token
+	end    parse.Pos
+	err    error
+}
+
+// Scan calls f with each token of the first size bytes of r, in order, until the end of the input,
+// or an error. It returns nil at the end of the input, otherwise it returns the first error of a
+// chunk's Scanner, or of f. If the input can't be split into chunks, f is called with the tokens
+// of the chunks before the problem, and then ErrNoBoundary is returned.
+func (p *Parallel) Scan(r io.ReaderAt, size int64, f func(t Token) error) error {
+	chunks, splitErr := p.split(r, size)
+	workers := p.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	// Each chunk holds a slot until its tokens have been passed to f, so that a slow chunk
+	// doesn't result in the tokens of every chunk after it being held in memory.
+	slots := make(chan struct{}, workers)
+	results := make([]chan chunkResult, len(chunks))
+	for i := range results {
+		results[i] = make(chan chunkResult, 1)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for i, c := range chunks {
+			select {
+			case slots <- struct{}{}:
+			case <-done:
+				return
+			}
+			go func(i int, c chunk) {
+				results[i] <- p.scan(r, c)
+			}(i, c)
+		}
+	}()
+	base := parse.Pos{Line: 1}
+	for i := range chunks {
+		res := <-results[i]
+		for _, t := range res.tokens {
+			if err := f(offsetToken(t, base)); err != nil {
+				return err
+			}
+		}
+		if res.err != nil {
+			return offsetError(res.err, base)
+		}
+		base = offset(res.end, base)
+		<-slots
+	}
+	return splitErr
+}
+
+// split divides the input into chunks of roughly ChunkSize bytes, which end just after a match
+// of the Boundary. If a chunk doesn't have a boundary, the chunks before it are returned along
+// with ErrNoBoundary.
+func (p *Parallel) split(r io.ReaderAt, size int64) (chunks []chunk, err error) {
+	chunkSize, maxRecordSize := p.ChunkSize, p.MaxRecordSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	if maxRecordSize <= 0 {
+		maxRecordSize = DefaultMaxRecordSize
+	}
+	var start int64
+	for start < size {
+		end := size
+		if start+chunkSize < size {
+			boundary, ok := p.boundaryAfter(r, start+chunkSize, maxRecordSize, size)
+			if !ok {
+				return chunks, ErrNoBoundary
+			}
+			end = boundary
+		}
+		chunks = append(chunks, chunk{start: start, end: end})
+		start = end
+	}
+	return chunks, nil
+}
+
+// boundaryAfter returns the offset just after the first match of the Boundary at, or after, the
+// offset, or the end of the input, if it's within limit bytes of the offset.
+func (p *Parallel) boundaryAfter(r io.ReaderAt, from, limit, size int64) (offset int64, ok bool) {
+	end := size
+	if from+limit < size {
+		end = from + limit
+	}
+	or := &offsetReader{
+		r:       bufio.NewReader(io.NewSectionReader(r, from, end-from)),
+		offsets: []int64{0},
+	}
+	stream := input.NewWithBufferSize(or, 4096)
+	for {
+		start := stream.Index()
+		if p.Boundary(stream).Success && stream.Index() > start {
+			return from + or.offsets[stream.Index()], true
+		}
+		parse.RewindTo(stream, start)
+		if _, err := stream.Advance(); err != nil {
+			return size, end == size
+		}
+		stream.Collect()
+	}
+}
+
+// scan captures the tokens of the chunk.
+func (p *Parallel) scan(r io.ReaderAt, c chunk) (res chunkResult) {
+	// The chunk can't contain more runes than bytes, so the buffer never needs to be larger, and
+	// a chunk is at most ChunkSize plus MaxRecordSize bytes.
+	stream := input.NewWithBufferSize(bufio.NewReader(io.NewSectionReader(r, c.start, c.end-c.start)), int(c.end-c.start)+1)
+	res.err = New(stream, p.Parser).Scan(func(t Token) error {
+		res.tokens = append(res.tokens, t)
+		return nil
+	})
+	res.end = parse.PosOf(stream)
+	return res
+}
+
+// offsetReader records the byte offset after each rune that it reads, so that the index of a
+// rune of the stream can be converted to a byte offset, even if the input isn't valid UTF-8.
+type offsetReader struct {
+	r       io.RuneReader
+	offsets []int64
+}
+
+func (or *offsetReader) ReadRune() (r rune, size int, err error) {
+	r, size, err = or.r.ReadRune()
+	if err == nil {
+		or.offsets = append(or.offsets, or.offsets[len(or.offsets)-1]+int64(size))
+	}
+	return r, size, err
+}
+
+// offset converts a position within a chunk to a position within the input, where base is the
+// position of the start of the chunk.
+func offset(p, base parse.Pos) parse.Pos {
+	if p.Line == 1 {
+		p.Column += base.Column
+	}
+	p.Line += base.Line - 1
+	p.Index += base.Index
+	return p
+}
+
+func offsetRange(r parse.Range, base parse.Pos) parse.Range {
+	return parse.Range{Start: offset(r.Start, base), End: offset(r.End, base)}
+}
+
+func offsetToken(t Token, base parse.Pos) Token {
+	t.Range = offsetRange(t.Range, base)
+	t.Leading = offsetTrivia(t.Leading, base)
+	t.Trailing = offsetTrivia(t.Trailing, base)
+	return t
+}
+
+func offsetTrivia(trivia []parse.Token, base parse.Pos) []parse.Token {
+	for i := range trivia {
+		trivia[i].Range = offsetRange(trivia[i].Range, base)
+	}
+	return trivia
+}
+
+func offsetError(err error, base parse.Pos) error {
+	switch e := err.(type) {
+	case *parse.Error:
+		return &parse.Error{Pos: offset(e.Pos, base), Err: e.Err}
+	case *UnmatchedError:
+		pos := offset(parse.Pos{Line: e.Line, Column: e.Column}, base)
+		return &UnmatchedError{Line: pos.Line, Column: pos.Column, Result: e.Result}
+	}
+	return err
+}
@@ -0,0 +1,162 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/parse"
+)
+
+var logLine = parse.Any(
+	parse.Named("line", parse.Span(parse.ClassOf("\n;").Not(), 1, 0)),
+	parse.Named("end", parse.RuneIn("\n;")),
+)
+
+// sequential scans the input with a single Scanner.
+func sequential(src string, parser parse.Function) (tokens []string, err error) {
+	err = New(input.NewFromString(src), parser).Scan(func(t Token) error {
+		tokens = append(tokens, fmt.Sprintf("%s %q %v", t.Type, t.Text, t.Range))
+		return nil
+	})
+	return tokens, err
+}
+
+// parallel scans the input in chunks.
+func parallel(p *Parallel, src string) (tokens []string, err error) {
+	err = p.Scan(strings.NewReader(src), int64(len(src)), func(t Token) error {
+		tokens = append(tokens, fmt.Sprintf("%s %q %v", t.Type, t.Text, t.Range))
+		return nil
+	})
+	return tokens, err
+}
+
+func TestParallel(t *testing.T) {
+	var lines []string
+	for i := 0; i < 200; i++ {
+		lines = append(lines, fmt.Sprintf("2024-01-01 level=info msg=\"événement %d\"", i))
+	}
+	tests := []struct {
+		name     string
+		input    string
+		boundary parse.Function
+	}{
+		{
+			name:     "newlines",
+			input:    strings.Join(lines, "\n") + "\n",
+			boundary: parse.Rune('\n'),
+		},
+		{
+			name:     "no trailing newline",
+			input:    strings.Join(lines, "\n"),
+			boundary: parse.Rune('\n'),
+		},
+		{
+			name:     "records within lines",
+			input:    strings.Join(lines, ";") + "\n" + strings.Join(lines, ";"),
+			boundary: parse.Rune(';'),
+		},
+		{
+			name:     "empty",
+			input:    "",
+			boundary: parse.Rune('\n'),
+		},
+	}
+	for i, test := range tests {
+		expected, err := sequential(test.input, logLine)
+		if err != nil {
+			t.Fatalf("test %v: %s unexpected error: %v", i, test.name, err)
+		}
+		for _, chunkSize := range []int64{0, 1, 7, 100, 1000, 1 << 20} {
+			for _, workers := range []int{1, 3, 8} {
+				p := NewParallel(test.boundary, logLine)
+				p.ChunkSize, p.Workers = chunkSize, workers
+				actual, err := parallel(p, test.input)
+				if err != nil {
+					t.Fatalf("test %v: %s with chunks of %d bytes and %d workers unexpected error: %v", i, test.name, chunkSize, workers, err)
+				}
+				if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+					t.Errorf("test %v: %s with chunks of %d bytes and %d workers expected %d tokens, got %d, which differ", i, test.name, chunkSize, workers, len(expected), len(actual))
+				}
+			}
+		}
+	}
+}
+
+func TestParallelErrors(t *testing.T) {
+	digits := parse.Any(
+		parse.Named("digits", parse.Span(parse.ClassRange('0', '9'), 1, 0)),
+		parse.Named("end", parse.Rune('\n')),
+	)
+	src := strings.Repeat("123\n", 50) + "12a\n" + strings.Repeat("456\n", 50)
+	_, expected := sequential(src, digits)
+	p := NewParallel(parse.Rune('\n'), digits)
+	p.ChunkSize = 10
+	count := 0
+	err := p.Scan(strings.NewReader(src), int64(len(src)), func(t Token) error {
+		count++
+		return nil
+	})
+	if _, ok := err.(*UnmatchedError); !ok || err.Error() != expected.Error() {
+		t.Errorf("expected error %v, got %v", expected, err)
+	}
+	if count != 101 {
+		t.Errorf("expected the 101 tokens before the error, got %d", count)
+	}
+
+	// The error of the callback stops the scan.
+	stop := fmt.Errorf("stop")
+	err = p.Scan(strings.NewReader(src), int64(len(src)), func(t Token) error {
+		return stop
+	})
+	if err != stop {
+		t.Errorf("expected the callback's error, got %v", err)
+	}
+}
+
+func TestParallelNoBoundary(t *testing.T) {
+	src := strings.Repeat("abc\n", 100) + strings.Repeat("x", 5000) + "\ndef\n"
+	p := NewParallel(parse.Rune('\n'), logLine)
+	p.ChunkSize, p.MaxRecordSize = 50, 1000
+	var tokens []string
+	err := p.Scan(strings.NewReader(src), int64(len(src)), func(t Token) error {
+		tokens = append(tokens, t.Text)
+		return nil
+	})
+	if err != ErrNoBoundary {
+		t.Errorf("expected ErrNoBoundary, got %v", err)
+	}
+	if len(tokens) == 0 || len(tokens) > 200 {
+		t.Errorf("expected the tokens of the lines before the long record, got %d tokens", len(tokens))
+	}
+	for _, text := range tokens {
+		if text != "abc" && text != "\n" {
+			t.Errorf("expected the tokens of the lines before the long record, got %q", text)
+			break
+		}
+	}
+
+	// A record within the limit is scanned, even if it's larger than the chunk size.
+	p.MaxRecordSize = 6000
+	expected, _ := sequential(src, logLine)
+	actual, err := parallel(p, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected %d tokens, got %d, which differ", len(expected), len(actual))
+	}
+}
+
+func BenchmarkParallel(b *testing.B) {
+	b.ReportAllocs()
+	src := strings.Repeat("2024-01-01T00:00:00Z level=info msg=\"request completed\" status=200\n", 20000)
+	p := NewParallel(parse.Rune('\n'), logLine)
+	p.ChunkSize = 64 << 10
+	for n := 0; n < b.N; n++ {
+		if err := p.Scan(strings.NewReader(src), int64(len(src)), func(t Token) error { return nil }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
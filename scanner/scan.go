@@ -0,0 +1,49 @@
+package scanner
+
+import (
+	"context"
+	"io"
+)
+
+// Scan calls f with each token of the input, until the end of the input, or an error. At the end
+// of the input, Scan returns nil, otherwise it returns the error of the scanner, or of f.
+func (s *Scanner) Scan(f func(t Token) error) error {
+	for {
+		t, err := s.NextToken()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = f(t); err != nil {
+			return err
+		}
+	}
+}
+
+// Tokens scans the input in a goroutine, and sends each token to the returned channel, which is
+// closed at the end of the input, after an error, or when the context is cancelled. The error
+// channel receives the error of the scanner, or of the context, before it's closed, and is
+// closed without an error at the end of the input. If the tokens aren't read to the end, the
+// context must be cancelled to stop the goroutine.
+func (s *Scanner) Tokens(ctx context.Context) (<-chan Token, <-chan error) {
+	tokens := make(chan Token)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(tokens)
+		err := s.Scan(func(t Token) error {
+			select {
+			case tokens <- t:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return tokens, errs
+}
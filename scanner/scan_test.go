@@ -0,0 +1,118 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/parse"
+)
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		err      string
+	}{
+		{
+			input:    "",
+			expected: "",
+		},
+		{
+			input:    "if a <= 1 // c",
+			expected: "if a <= 1",
+		},
+		{
+			input:    "a # b",
+			expected: "a",
+			err:      "scanner: unmatched at line 1, column 2, item: ✗ (lexer) err: <nil>",
+		},
+	}
+	for i, test := range tests {
+		s, _ := NewWithModes(input.NewFromString(test.input), Modes{"": testLexer}, "")
+		var actual []string
+		err := s.Scan(func(tok Token) error {
+			actual = append(actual, tok.Text)
+			return nil
+		})
+		if (err == nil && test.err != "") || (err != nil && err.Error() != test.err) {
+			t.Errorf("test %v: for input '%v' expected error %q, got %v", i, test.input, test.err, err)
+		}
+		if strings.Join(actual, " ") != test.expected {
+			t.Errorf("test %v: for input '%v' expected %q, got %q", i, test.input, test.expected, strings.Join(actual, " "))
+		}
+	}
+}
+
+func TestScanStopsAtCallbackError(t *testing.T) {
+	stop := errors.New("stop")
+	s, _ := NewWithModes(input.NewFromString("a b c"), Modes{"": testLexer}, "")
+	var count int
+	err := s.Scan(func(tok Token) error {
+		count++
+		if tok.Text == "b" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || count != 2 {
+		t.Errorf("expected to stop after 2 tokens with the callback's error, got %d tokens and %v", count, err)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	s, _ := NewWithModes(input.NewFromString("a <= b\n"), Modes{"": testLexer}, "")
+	tokens, errs := s.Tokens(context.Background())
+	var actual []string
+	for tok := range tokens {
+		actual = append(actual, tok.Type+" "+tok.Text)
+	}
+	if err := <-errs; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if strings.Join(actual, ", ") != "identifier a, operator <=, identifier b" {
+		t.Errorf("unexpected tokens: %v", actual)
+	}
+
+	s, _ = NewWithModes(input.NewFromString("a # b"), Modes{"": testLexer}, "")
+	tokens, errs = s.Tokens(context.Background())
+	for range tokens {
+	}
+	if _, ok := (<-errs).(*UnmatchedError); !ok {
+		t.Errorf("expected an *UnmatchedError")
+	}
+}
+
+func TestTokensCancel(t *testing.T) {
+	s, _ := NewWithModes(input.NewFromString(strings.Repeat("a ", 1000)), Modes{"": testLexer}, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	tokens, errs := s.Tokens(ctx)
+	<-tokens
+	cancel()
+	for range tokens {
+	}
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestNoProgress(t *testing.T) {
+	// The optional parser succeeds without consuming the input, so Next would return the same
+	// item forever.
+	s := New(input.NewFromString("ab"), parse.Optional(parse.WithStringConcatCombiner, parse.Rune('a')))
+	if _, err := s.Next(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err := s.Next()
+	if pe, ok := err.(*parse.Error); !ok || pe.Err != ErrNoProgress || pe.Pos.Column != 1 {
+		t.Errorf("expected ErrNoProgress at column 1, got %v", err)
+	}
+
+	s = New(input.NewFromString("a"), parse.Optional(parse.WithStringConcatCombiner, parse.Rune('a')))
+	err = s.Scan(func(Token) error { return nil })
+	if err != nil {
+		t.Errorf("expected the end of the input to stop the scan, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package scanner
 
 import (
+	"errors"
 	"fmt"
 	"io"
 
@@ -9,21 +10,81 @@ import (
 
 // Scanner can take an input stream and execute parse results.
 type Scanner struct {
-	Input  parse.Input
+	Input parse.Input
+	// Parser captures each token. If the scanner has modes, it's the Parse method of the current
+	// mode's Lexer.
 	Parser parse.Function
+	// lexer is the Lexer of the current mode, if the scanner was created with modes.
+	lexer *Lexer
+	modes Modes
+	mode  string
+	// stack holds the modes to return to when a mode is popped.
+	stack []string
+	// skip is true if the last token was captured by a rule which is skipped.
+	skip bool
+	// leading, ahead and err hold the trivia, token and error which NextToken has read past the
+	// end of the token it returned, to return with the next token.
+	leading []parse.Token
+	ahead   *parse.Token
+	err     error
 }
 
+// UnmatchedError is returned by Next when the parser doesn't match the input.
+type UnmatchedError struct {
+	Line   int
+	Column int
+	Result parse.Result
+}
+
+func (e *UnmatchedError) Error() string {
+	return fmt.Sprintf("scanner: unmatched at line %v, column %v, item: %v", e.Line, e.Column, e.Result)
+}
+
+// ErrNoProgress is returned when the parser succeeds without consuming any input, because the
+// scanner would return the same token forever.
+var ErrNoProgress = errors.New("scanner: the parser succeeded without consuming any input")
+
 // Next should be called repeatedly to request the next token from the stream.
-// If
+// If the parser fails with an error, such as a *parse.Error, the error is returned,
+// otherwise an *UnmatchedError is returned if the parser doesn't match the input.
+// At the end of the input, the error is io.EOF.
 func (s *Scanner) Next() (item interface{}, err error) {
-	result := s.Parser(s.Input)
+	result, _, err := s.next()
+	return result.Item, err
+}
+
+// next runs the parser, and returns its result along with the text that it captured. If the
+// token was captured by a Lexer rule which changes the mode, the mode is changed.
+func (s *Scanner) next() (result parse.Result, text string, err error) {
+	if parse.EOF(s.Input).Success {
+		return parse.Failure("scanner", io.EOF), "", io.EOF
+	}
+	start := parse.PosOf(s.Input)
+	var rule *Rule
+	if s.lexer != nil {
+		result, rule = s.lexer.parse(s.Input)
+	} else {
+		result = s.Parser(s.Input)
+	}
+	s.skip = rule != nil && rule.Skip
 	success := result.Success
 	if !success && result.Error != io.EOF {
+		if result.Error != nil {
+			return result, "", result.Error
+		}
 		line, col := s.Input.Position()
-		return result.Item, fmt.Errorf("scanner: unmatched at line %v, column %v, item: %v", line, col, result)
+		return result, "", &UnmatchedError{Line: line, Column: col, Result: result}
+	}
+	if success && s.Input.Index() == start.Index {
+		return result, "", &parse.Error{Pos: start, Err: ErrNoProgress}
+	}
+	text = s.Input.Collect()
+	if rule != nil {
+		if err = s.transition(rule); err != nil {
+			return result, text, &parse.Error{Pos: start, Err: err}
+		}
 	}
-	s.Input.Collect()
-	return result.Item, result.Error
+	return result, text, result.Error
 }
 
 // New creates a new Scanner.
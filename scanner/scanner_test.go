@@ -126,3 +126,27 @@ func TestXMLName(t *testing.T) {
 		}
 	}
 }
+
+func TestScanningErrors(t *testing.T) {
+	digits := parse.Span(parse.ClassRange('0', '9'), 1, 0)
+	numbers := parse.Any(parse.Int(10, 8), parse.Rune(' '))
+
+	_, err := New(input.NewFromString("abc"), digits).Next()
+	if _, ok := err.(*UnmatchedError); !ok {
+		t.Errorf("expected *UnmatchedError, got %T", err)
+	}
+	if err.Error() != "scanner: unmatched at line 1, column 0, item: ✗ (span of class) err: <nil>" {
+		t.Errorf("unexpected message: %v", err)
+	}
+
+	scanner := New(input.NewFromString("1 2 300"), numbers)
+	for i := 0; i < 4; i++ {
+		if _, err = scanner.Next(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	_, err = scanner.Next()
+	if _, ok := err.(*parse.Error); !ok {
+		t.Errorf("expected *parse.Error, got %T", err)
+	}
+}
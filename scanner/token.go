@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"io"
+	"strings"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// Token is a token captured by a Scanner, along with the trivia around it. Trivia are the
+// tokens of Lexer rules which are skipped, e.g. whitespace and comments, which aren't needed to
+// parse the input, but are needed to reproduce it, e.g. in a formatter or syntax highlighter.
+type Token struct {
+	parse.Token
+	// Leading is the trivia before the token which isn't the Trailing trivia of the previous
+	// token, e.g. the comments on the lines before it.
+	Leading []parse.Token
+	// Trailing is the trivia after the token, up to the end of its line, e.g. a comment on the
+	// same line. Trivia which ends with a newline ends the Trailing trivia.
+	Trailing []parse.Token
+}
+
+// EOF is the Type of the Token that NextToken returns when the input ends with trivia which
+// doesn't belong to a token. The trivia is its Leading trivia, and it has no text.
+const EOF = "EOF"
+
+// NextToken returns the next token which isn't skipped, along with its trivia. At the end of the
+// input, the error is io.EOF. Concatenating the text of each token's Leading trivia, the token,
+// and its Trailing trivia, reproduces the input.
+//
+// NextToken reads ahead to find the end of the Trailing trivia, so it shouldn't be mixed with
+// calls to Next, or with a token input created by NewTokenInput.
+func (s *Scanner) NextToken() (t Token, err error) {
+	if s.err != nil {
+		err, s.err = s.err, nil
+		return t, err
+	}
+	t.Leading, s.leading = s.leading, nil
+	if s.ahead != nil {
+		t.Token, s.ahead = *s.ahead, nil
+	} else if t.Token, err = s.untilToken(&t.Leading); err != nil {
+		if err == io.EOF && len(t.Leading) > 0 {
+			end := t.Leading[len(t.Leading)-1].Range.End
+			t.Token = parse.Token{Type: EOF, Range: parse.Range{Start: end, End: end}}
+			return t, nil
+		}
+		return Token{}, err
+	}
+	// Read the trivia after the token, up to the end of its line.
+	line := t.Range.End.Line
+	for {
+		next, err := s.token()
+		if err != nil {
+			// The error is returned by the next call, once the token has been returned.
+			s.err = err
+			return t, nil
+		}
+		if !s.skip {
+			s.ahead = &next
+			return t, nil
+		}
+		if next.Range.Start.Line != line {
+			s.leading = append(s.leading, next)
+			return t, nil
+		}
+		t.Trailing = append(t.Trailing, next)
+		if strings.ContainsAny(next.Text, "\r\n") {
+			return t, nil
+		}
+	}
+}
+
+// untilToken returns the next token which isn't skipped, and appends the skipped tokens before it
+// to trivia.
+func (s *Scanner) untilToken(trivia *[]parse.Token) (t parse.Token, err error) {
+	for {
+		if t, err = s.token(); err != nil || !s.skip {
+			return t, err
+		}
+		*trivia = append(*trivia, t)
+	}
+}
@@ -0,0 +1,143 @@
+package scanner
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/parse"
+)
+
+// texts returns the text of each token.
+func texts(tokens []parse.Token) (s []string) {
+	for _, t := range tokens {
+		s = append(s, t.Text)
+	}
+	return s
+}
+
+// describe returns the leading trivia, type, text and trailing trivia of a token.
+func describe(t Token) string {
+	return "[" + strings.Join(texts(t.Leading), "|") + "] " + t.Type + " " + t.Text + " [" + strings.Join(texts(t.Trailing), "|") + "]"
+}
+
+func TestNextToken(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			input:    "",
+			expected: nil,
+		},
+		{
+			input:    "a",
+			expected: []string{"[] identifier a []"},
+		},
+		{
+			input: "// leading\nif a // trailing\n  return 1\n",
+			expected: []string{
+				"[// leading|\n] keyword if [ ]",
+				"[] identifier a [ |// trailing|\n  ]",
+				"[] keyword return [ ]",
+				"[] integer 1 [\n]",
+			},
+		},
+		{
+			input: "a\n\n// b\nc",
+			expected: []string{
+				"[] identifier a [\n\n]",
+				"[// b|\n] identifier c []",
+			},
+		},
+		{
+			input: "a // end",
+			expected: []string{
+				"[] identifier a [ |// end]",
+			},
+		},
+		{
+			input: "a\n// end",
+			expected: []string{
+				"[] identifier a [\n]",
+				"[// end] EOF  []",
+			},
+		},
+		{
+			input: "  ",
+			expected: []string{
+				"[  ] EOF  []",
+			},
+		},
+	}
+	for i, test := range tests {
+		s, _ := NewWithModes(input.NewFromString(test.input), Modes{"": testLexer}, "")
+		var actual []string
+		var src strings.Builder
+		for {
+			tok, err := s.NextToken()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("test %v: for input '%v' unexpected error: %v", i, test.input, err)
+			}
+			actual = append(actual, describe(tok))
+			src.WriteString(strings.Join(texts(tok.Leading), "") + tok.Text + strings.Join(texts(tok.Trailing), ""))
+		}
+		if strings.Join(actual, "\n") != strings.Join(test.expected, "\n") {
+			t.Errorf("test %v: for input '%v' expected:\n%v\ngot:\n%v", i, test.input, strings.Join(test.expected, "\n"), strings.Join(actual, "\n"))
+		}
+		if src.String() != test.input {
+			t.Errorf("test %v: for input '%v' expected the tokens to reproduce the input, got '%v'", i, test.input, src.String())
+		}
+	}
+}
+
+func TestNextTokenRanges(t *testing.T) {
+	s, _ := NewWithModes(input.NewFromString("if a\n  // c\n"), Modes{"": testLexer}, "")
+	tok, _ := s.NextToken()
+	expected := parse.Range{Start: parse.Pos{Index: 0, Line: 1, Column: 0}, End: parse.Pos{Index: 2, Line: 1, Column: 2}}
+	if tok.Type != "keyword" || tok.Range != expected {
+		t.Errorf("expected keyword at %v, got %v at %v", expected, tok, tok.Range)
+	}
+	tok, _ = s.NextToken()
+	if tok.Text != "a" || tok.Range.Start.Column != 3 {
+		t.Errorf("expected identifier a at column 3, got %v at %v", tok, tok.Range)
+	}
+	tok, _ = s.NextToken()
+	expected = parse.Range{Start: parse.Pos{Index: 12, Line: 3, Column: 0}, End: parse.Pos{Index: 12, Line: 3, Column: 0}}
+	if tok.Type != EOF || tok.Range != expected || len(tok.Leading) != 2 {
+		t.Errorf("expected EOF at %v with the comment and newline as leading trivia, got %v", expected, describe(tok))
+	}
+}
+
+func TestNextTokenErrors(t *testing.T) {
+	// The token before the error is returned, and then the error.
+	s, _ := NewWithModes(input.NewFromString("a # b"), Modes{"": testLexer}, "")
+	tok, err := s.NextToken()
+	if err != nil || describe(tok) != "[] identifier a [ ]" {
+		t.Errorf("expected the identifier before the error, got %v, %v", describe(tok), err)
+	}
+	_, err = s.NextToken()
+	if ue, ok := err.(*UnmatchedError); !ok || ue.Column != 2 {
+		t.Errorf("expected an *UnmatchedError at column 2, got %v", err)
+	}
+}
+
+func BenchmarkNextToken(b *testing.B) {
+	b.ReportAllocs()
+	src := strings.Repeat("if a <= 10 // comment\n  return \"value\"\n", 20)
+	for n := 0; n < b.N; n++ {
+		s, _ := NewWithModes(input.NewFromString(src), Modes{"": testLexer}, "")
+		for {
+			if _, err := s.NextToken(); err != nil {
+				if err != io.EOF {
+					b.Fatal(err)
+				}
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package scanner
+
+import (
+	"github.com/a-h/lexical/parse"
+)
+
+// NewTokenInput returns a parse.TokenInput which reads tokens from the scanner, so that the input
+// can be lexed once, and its structure parsed with token parsers such as parse.TokenOfType.
+//
+// The Type of each token is the Name of the result of the scanner's parser, which can be set
+// with parse.Named. Tokens of the skip types, e.g. whitespace, and tokens of Lexer rules which
+// are skipped, aren't passed to the parsers.
+func NewTokenInput(s *Scanner, skip ...string) *parse.TokenStream {
+	return parse.NewTokenStream(func() (t parse.Token, err error) {
+		for {
+			if t, err = s.token(); err != nil || !(s.skip || contains(skip, t.Type)) {
+				return t, err
+			}
+		}
+	})
+}
+
+// token returns the next token from the input, or io.EOF at the end of the input.
+func (s *Scanner) token() (parse.Token, error) {
+	start := parse.PosOf(s.Input)
+	result, text, err := s.next()
+	if err != nil {
+		return parse.Token{}, err
+	}
+	return parse.Token{
+		Type:  result.Name,
+		Item:  result.Item,
+		Text:  text,
+		Range: parse.Range{Start: start, End: parse.PosOf(s.Input)},
+	}, nil
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package scanner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/parse"
+)
+
+// sqlLexer lexes a small SQL-like language, and names each token with its type.
+var sqlLexer = parse.Any(
+	parse.Named("whitespace", parse.Span(parse.ClassOf(" \t\r\n"), 1, 0)),
+	parse.Named("keyword", parse.Any(
+		parse.KeywordInsensitive("select"),
+		parse.KeywordInsensitive("from"),
+		parse.KeywordInsensitive("where"),
+		parse.KeywordInsensitive("and"),
+	)),
+	parse.Named("identifier", parse.Identifier(parse.IsIdentifierStart, parse.IsIdentifierContinue)),
+	parse.Named("number", parse.Int(10, 64)),
+	parse.Named("operator", parse.OneOfStrings("<=", ">=", "=", "<", ">")),
+	parse.Named("punctuation", parse.RuneIn(",*")),
+)
+
+func keyword(k string) parse.Function {
+	return parse.TokenWhere(k, func(t parse.Token) bool {
+		return t.Type == "keyword" && strings.EqualFold(t.Text, k)
+	})
+}
+
+func text(t interface{}) string {
+	return t.(parse.Token).Text
+}
+
+func asSlice(items []interface{}) (interface{}, bool) {
+	return items, true
+}
+
+type query struct {
+	Columns    []string
+	Table      string
+	Conditions []string
+}
+
+var identifier = parse.TokenOfType("identifier")
+
+// columns captures a comma separated list of identifiers.
+var columns = parse.Then(func(items []interface{}) (interface{}, bool) {
+	columns := []string{text(items[0])}
+	for _, item := range items[1].([]interface{}) {
+		columns = append(columns, text(item.([]interface{})[1]))
+	}
+	return columns, true
+}, identifier, parse.Many(asSlice, 0, 0, parse.Then(asSlice, parse.Rune(','), identifier)))
+
+var condition = parse.All(func(items []interface{}) (interface{}, bool) {
+	return text(items[0]) + text(items[1]) + text(items[2]), true
+}, identifier, parse.TokenOfType("operator"), parse.TokenOfType("number", "identifier"))
+
+// where captures a where clause, and returns its conditions.
+var where = parse.Then(func(items []interface{}) (interface{}, bool) {
+	conditions := []string{items[0].([]interface{})[1].(string)}
+	for _, item := range items[1].([]interface{}) {
+		conditions = append(conditions, item.([]interface{})[1].(string))
+	}
+	return conditions, true
+}, parse.Then(asSlice, keyword("where"), condition), parse.Many(asSlice, 0, 0, parse.Then(asSlice, keyword("and"), condition)))
+
+var selectStatement = parse.All(func(items []interface{}) (interface{}, bool) {
+	q := query{
+		Columns: items[1].([]string),
+		Table:   text(items[3]),
+	}
+	if w := items[4].([]interface{}); len(w) > 0 {
+		q.Conditions = w[0].([]string)
+	}
+	return q, true
+}, keyword("select"), columns, keyword("from"), identifier, parse.Optional(asSlice, where), parse.EOF)
+
+func TestTokenInput(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected query
+	}{
+		{
+			input:    "SELECT a FROM t",
+			expected: query{Columns: []string{"a"}, Table: "t"},
+		},
+		{
+			input: "select id, name\nfrom users\nwhere age >= 18 and id < max_id",
+			expected: query{
+				Columns:    []string{"id", "name"},
+				Table:      "users",
+				Conditions: []string{"age>=18", "id<max_id"},
+			},
+		},
+	}
+	for i, test := range tests {
+		ti := NewTokenInput(New(input.NewFromString(test.input), sqlLexer), "whitespace")
+		r := selectStatement(ti)
+		if !r.Success {
+			t.Errorf("test %v: for input '%v' expected success, got %v", i, test.input, r)
+			continue
+		}
+		if !reflect.DeepEqual(r.Item, test.expected) {
+			t.Errorf("test %v: for input '%v' expected %+v, got %+v", i, test.input, test.expected, r.Item)
+		}
+	}
+}
+
+func TestTokenInputTokens(t *testing.T) {
+	ti := NewTokenInput(New(input.NewFromString("select a,\n  b"), sqlLexer), "whitespace")
+	var actual []parse.Token
+	for {
+		tok, err := ti.AdvanceToken()
+		if err != nil {
+			break
+		}
+		actual = append(actual, tok)
+	}
+	expected := []struct {
+		typ, text      string
+		line, col, end int
+	}{
+		{typ: "keyword", text: "select", line: 1, col: 0, end: 6},
+		{typ: "identifier", text: "a", line: 1, col: 7, end: 8},
+		{typ: "punctuation", text: ",", line: 1, col: 8, end: 9},
+		{typ: "identifier", text: "b", line: 2, col: 2, end: 3},
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d tokens, got %v", len(expected), actual)
+	}
+	for i, e := range expected {
+		a := actual[i]
+		if a.Type != e.typ || a.Text != e.text || a.Range.Start.Line != e.line || a.Range.Start.Column != e.col || a.Range.End.Column != e.end {
+			t.Errorf("token %d: expected %s %q at %d:%d-%d, got %v at %v", i, e.typ, e.text, e.line, e.col, e.end, a, a.Range)
+		}
+	}
+}
+
+func TestTokenInputErrors(t *testing.T) {
+	// The lexer doesn't match '!', so the parser fails with the scanner's error.
+	ti := NewTokenInput(New(input.NewFromString("select a ! from t"), sqlLexer), "whitespace")
+	r := selectStatement(ti)
+	if _, ok := r.Error.(*UnmatchedError); r.Success || !ok {
+		t.Errorf("expected an *UnmatchedError, got %v", r)
+	}
+
+	// The tokens are valid, but the structure isn't.
+	ti = NewTokenInput(New(input.NewFromString("select a\nfrom where"), sqlLexer), "whitespace")
+	r = selectStatement(ti)
+	if r.Success || r.Error != nil {
+		t.Errorf("expected a failure without an error, got %v", r)
+	}
+}
+
+func BenchmarkTokenInput(b *testing.B) {
+	b.ReportAllocs()
+	q := "select id, name, email from users where age >= 18 and id < 1000 and score > 50"
+	for n := 0; n < b.N; n++ {
+		ti := NewTokenInput(New(input.NewFromString(q), sqlLexer), "whitespace")
+		if r := selectStatement(ti); !r.Success {
+			b.Fatal(r)
+		}
+	}
+}
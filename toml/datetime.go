@@ -0,0 +1,204 @@
+package toml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// LocalDate is a date without a time or a time zone, e.g. 1979-05-27.
+type LocalDate struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// String returns the date in the form it's written in a document.
+func (d LocalDate) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// In returns the start of the date in the location.
+func (d LocalDate) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// LocalTime is a time of day without a date or a time zone, e.g. 07:32:00.999.
+type LocalTime struct {
+	Hour       int
+	Minute     int
+	Second     int
+	Nanosecond int
+}
+
+// String returns the time in the form it's written in a document.
+func (t LocalTime) String() string {
+	s := fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	if t.Nanosecond != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%09d", t.Nanosecond), "0")
+	}
+	return s
+}
+
+// LocalDateTime is a date and time without a time zone, e.g. 1979-05-27T07:32:00.
+type LocalDateTime struct {
+	Date LocalDate
+	Time LocalTime
+}
+
+// String returns the date and time in the form it's written in a document.
+func (dt LocalDateTime) String() string {
+	return dt.Date.String() + "T" + dt.Time.String()
+}
+
+// In returns the date and time in the location.
+func (dt LocalDateTime) In(loc *time.Location) time.Time {
+	return time.Date(dt.Date.Year, dt.Date.Month, dt.Date.Day, dt.Time.Hour, dt.Time.Minute, dt.Time.Second, dt.Time.Nanosecond, loc)
+}
+
+// field is a number within a date or time, and its position.
+type field struct {
+	pos   parse.Pos
+	value int
+}
+
+// fixed captures n digits, and returns them as a field.
+func fixed(n int) parse.Function {
+	digits := parse.Span(decimalDigits, n, n)
+	return func(pi parse.Input) parse.Result {
+		pos := parse.PosOf(pi)
+		r := digits(pi)
+		if !r.Success {
+			return r
+		}
+		v, _ := strconv.Atoi(r.Item.(string))
+		return parse.Success("number", field{pos: pos, value: v}, nil)
+	}
+}
+
+// fields captures all of the functions, and returns the fields within their items.
+func fields(functions ...parse.Function) parse.Function {
+	return parse.All(func(items []interface{}) (interface{}, bool) {
+		var fs []field
+		for _, item := range items {
+			if f, ok := item.(field); ok {
+				fs = append(fs, f)
+			}
+		}
+		return fs, true
+	}, functions...)
+}
+
+var fullDate = fields(fixed(4), parse.Rune('-'), fixed(2), parse.Rune('-'), fixed(2))
+var partialTime = fields(fixed(2), parse.Rune(':'), fixed(2), parse.Rune(':'), fixed(2))
+var hoursAndMinutes = fields(fixed(2), parse.Rune(':'), fixed(2))
+var fraction = parse.Then(parse.WithStringConcatCombiner, parse.Rune('.'), parse.Span(decimalDigits, 1, 0))
+
+// offset is the offset of a time zone from UTC, e.g. -07:00.
+type offset struct {
+	negative     bool
+	hour, minute field
+}
+
+var zoneOffset = parse.All(func(items []interface{}) (interface{}, bool) {
+	return offset{
+		negative: items[0].(rune) == '-',
+		hour:     items[1].(field),
+		minute:   items[3].(field),
+	}, true
+}, sign, fixed(2), parse.Rune(':'), fixed(2))
+
+// datetime captures an offset date-time, a local date-time, a local date or a local time.
+func (p *parser) datetime() (v interface{}, ok bool, err error) {
+	pi := p.input
+	r := parse.Accept(pi, fullDate)
+	if !r.Success {
+		t, ok, err := p.localTime()
+		if !ok || err != nil {
+			return nil, ok, err
+		}
+		return t, true, nil
+	}
+	d, err := date(r.Item.([]field))
+	if err != nil {
+		return nil, true, err
+	}
+	// The date and time can be separated by a space, but a date followed by a space and
+	// something other than a time is a local date.
+	delimiter := pi.Index()
+	if !parse.Accept(pi, parse.RuneIn("Tt ")).Success {
+		return d, true, nil
+	}
+	t, ok, err := p.localTime()
+	if err != nil {
+		return nil, true, err
+	}
+	if !ok {
+		parse.RewindTo(pi, delimiter)
+		return d, true, nil
+	}
+	dt := LocalDateTime{Date: d, Time: t}
+	if parse.Accept(pi, parse.RuneIn("Zz")).Success {
+		return dt.In(time.UTC), true, nil
+	}
+	o := parse.Accept(pi, zoneOffset)
+	if !o.Success {
+		return dt, true, nil
+	}
+	z := o.Item.(offset)
+	if z.hour.value > 23 {
+		return nil, true, parse.Errorf(z.hour.pos, "zone offset hour %d out of range", z.hour.value)
+	}
+	if z.minute.value > 59 {
+		return nil, true, parse.Errorf(z.minute.pos, "zone offset minute %d out of range", z.minute.value)
+	}
+	seconds := (z.hour.value*60 + z.minute.value) * 60
+	if z.negative {
+		seconds = -seconds
+	}
+	return dt.In(time.FixedZone("", seconds)), true, nil
+}
+
+// date validates the fields of a date.
+func date(fs []field) (d LocalDate, err error) {
+	d = LocalDate{Year: fs[0].value, Month: time.Month(fs[1].value), Day: fs[2].value}
+	if d.Month < time.January || d.Month > time.December {
+		return d, parse.Errorf(fs[1].pos, "month %d out of range", fs[1].value)
+	}
+	if d.Day < 1 || d.Day > daysIn(d.Month, d.Year) {
+		return d, parse.Errorf(fs[2].pos, "day %d out of range for %v %d", d.Day, d.Month, d.Year)
+	}
+	return d, nil
+}
+
+func daysIn(m time.Month, year int) int {
+	return time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// localTime captures and validates a time of day, with an optional fraction of a second.
+func (p *parser) localTime() (t LocalTime, ok bool, err error) {
+	pi := p.input
+	r := parse.Accept(pi, partialTime)
+	if !r.Success {
+		if hm := parse.Accept(pi, hoursAndMinutes); hm.Success {
+			return t, true, parse.Errorf(parse.PosOf(pi), "expected the seconds of the time")
+		}
+		return t, false, nil
+	}
+	fs := r.Item.([]field)
+	t = LocalTime{Hour: fs[0].value, Minute: fs[1].value, Second: fs[2].value}
+	for i, max := range []int{23, 59, 59} {
+		if fs[i].value > max {
+			return t, true, parse.Errorf(fs[i].pos, "%s %d out of range", []string{"hour", "minute", "second"}[i], fs[i].value)
+		}
+	}
+	if f := parse.Accept(pi, fraction); f.Success {
+		// Keep nanosecond precision, and discard the rest.
+		digits := (f.Item.(string)[1:] + "000000000")[:9]
+		t.Nanosecond, _ = strconv.Atoi(digits)
+	}
+	return t, true, nil
+}
@@ -0,0 +1,186 @@
+package toml
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// Unmarshal parses a complete TOML document from the input, and stores its values in the
+// struct or map that v points to.
+//
+// Each key of a table is stored in the exported struct field named by its `toml` tag, or
+// the field with the same name, in any case, if there isn't a tag. Fields with the tag
+// `toml:"-"` are ignored, as are keys which don't have a field. Tables can be stored in
+// structs and maps with string keys, arrays in slices, and values in fields of the same
+// kind, e.g. an integer can be stored in any integer or float field that can hold it. Values
+// are also stored in interface{} fields, in the same form as Decode, and strings in
+// encoding.TextUnmarshalers. Pointers are allocated as needed.
+func Unmarshal(pi parse.Input, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("toml: Unmarshal requires a non-nil pointer")
+	}
+	m, err := Decode(pi)
+	if err != nil {
+		return err
+	}
+	return store(rv.Elem(), m, nil)
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// store stores the value in the field. The path is the key of the value, which is used in
+// error messages.
+func store(field reflect.Value, value interface{}, path []string) error {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return store(field.Elem(), value, path)
+	}
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(field.Type()) {
+		field.Set(v)
+		return nil
+	}
+	if s, ok := value.(string); ok && reflect.PtrTo(field.Type()).Implements(textUnmarshalerType) {
+		if err := field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("toml: key %s: %w", keyName(path), err)
+		}
+		return nil
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return storeTable(field, value, path)
+	case []interface{}:
+		if field.Kind() != reflect.Slice {
+			break
+		}
+		s := reflect.MakeSlice(field.Type(), len(value), len(value))
+		for i, e := range value {
+			if err := store(s.Index(i), e, append(path, fmt.Sprint(i))); err != nil {
+				return err
+			}
+		}
+		field.Set(s)
+		return nil
+	case string:
+		if field.Kind() == reflect.String {
+			field.SetString(value)
+			return nil
+		}
+	case bool:
+		if field.Kind() == reflect.Bool {
+			field.SetBool(value)
+			return nil
+		}
+	case int64:
+		switch field.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if field.OverflowInt(value) {
+				return fmt.Errorf("toml: key %s: %d overflows %s", keyName(path), value, field.Type())
+			}
+			field.SetInt(value)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if value < 0 || field.OverflowUint(uint64(value)) {
+				return fmt.Errorf("toml: key %s: %d overflows %s", keyName(path), value, field.Type())
+			}
+			field.SetUint(uint64(value))
+			return nil
+		case reflect.Float32, reflect.Float64:
+			field.SetFloat(float64(value))
+			return nil
+		}
+	case float64:
+		if field.Kind() == reflect.Float32 || field.Kind() == reflect.Float64 {
+			field.SetFloat(value)
+			return nil
+		}
+	}
+	return fmt.Errorf("toml: key %s: cannot store %s in %s", keyName(path), typeName(value), field.Type())
+}
+
+// storeTable stores the table in a struct, or a map with string keys.
+func storeTable(field reflect.Value, table map[string]interface{}, path []string) error {
+	switch {
+	case field.Kind() == reflect.Map && field.Type().Key().Kind() == reflect.String:
+		if field.IsNil() {
+			field.Set(reflect.MakeMapWithSize(field.Type(), len(table)))
+		}
+		for k, v := range table {
+			e := reflect.New(field.Type().Elem()).Elem()
+			if err := store(e, v, append(path, k)); err != nil {
+				return err
+			}
+			field.SetMapIndex(reflect.ValueOf(k).Convert(field.Type().Key()), e)
+		}
+		return nil
+	case field.Kind() == reflect.Struct:
+		t := field.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			name := f.Name
+			if tag, ok := f.Tag.Lookup("toml"); ok {
+				if tag == "-" {
+					continue
+				}
+				name = tag
+			}
+			key, ok := lookup(table, name)
+			if !ok {
+				continue
+			}
+			if err := store(field.Field(i), table[key], append(path, key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("toml: key %s: cannot store a table in %s", keyName(path), field.Type())
+}
+
+// lookup returns the key of the table which matches the name, preferring an exact match to
+// one in a different case.
+func lookup(table map[string]interface{}, name string) (key string, ok bool) {
+	if _, ok = table[name]; ok {
+		return name, true
+	}
+	for k := range table {
+		if strings.EqualFold(k, name) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// typeName returns the TOML name of the type of a decoded value.
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "a string"
+	case int64:
+		return "an integer"
+	case float64:
+		return "a float"
+	case bool:
+		return "a boolean"
+	case []interface{}:
+		return "an array"
+	case LocalDateTime:
+		return "a local date-time"
+	case LocalDate:
+		return "a local date"
+	case LocalTime:
+		return "a local time"
+	}
+	return "a date-time"
+}
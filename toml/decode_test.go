@@ -0,0 +1,134 @@
+package toml
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/a-h/lexical/input"
+)
+
+type server struct {
+	Name    string
+	Address net.IP `toml:"address"`
+	Ports   []uint16
+	Enabled *bool
+	Ignored string `toml:"-"`
+}
+
+type config struct {
+	Title   string            `toml:"title"`
+	Owner   map[string]string `toml:"owner"`
+	Servers []server          `toml:"servers"`
+	Timeout float32
+	Started time.Time
+	Date    LocalDate
+	Extra   map[string]interface{}
+}
+
+func TestUnmarshal(t *testing.T) {
+	src := `title = "Example"
+timeout = 30
+started = 1979-05-27T07:32:00Z
+date = 1979-05-27
+ignored = "not stored"
+
+[owner]
+name = "Tom"
+
+[[servers]]
+name = "alpha"
+address = "10.0.0.1"
+ports = [8000, 8001]
+enabled = true
+ignored = "not stored"
+
+[[servers]]
+NAME = "beta"
+address = "10.0.0.2"
+
+[extra]
+a = 1
+b = [true]
+`
+	var actual config
+	if err := Unmarshal(input.NewFromString(src), &actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	enabled := true
+	expected := config{
+		Title:   "Example",
+		Owner:   map[string]string{"name": "Tom"},
+		Timeout: 30,
+		Started: time.Date(1979, 5, 27, 7, 32, 0, 0, time.FixedZone("", 0)),
+		Date:    LocalDate{Year: 1979, Month: time.May, Day: 27},
+		Servers: []server{
+			{Name: "alpha", Address: net.ParseIP("10.0.0.1"), Ports: []uint16{8000, 8001}, Enabled: &enabled},
+			{Name: "beta", Address: net.ParseIP("10.0.0.2")},
+		},
+		Extra: map[string]interface{}{"a": int64(1), "b": []interface{}{true}},
+	}
+	if !actual.Started.Equal(expected.Started) {
+		t.Errorf("expected started %v, got %v", expected.Started, actual.Started)
+	}
+	actual.Started, expected.Started = time.Time{}, time.Time{}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected:\n%#v\ngot:\n%#v", expected, actual)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		v        interface{}
+		expected string
+	}{
+		{
+			input:    "a = 300",
+			v:        &struct{ A int8 }{},
+			expected: "toml: key a: 300 overflows int8",
+		},
+		{
+			input:    "a = -1",
+			v:        &struct{ A uint }{},
+			expected: "toml: key a: -1 overflows uint",
+		},
+		{
+			input:    "a = \"1\"",
+			v:        &struct{ A int }{},
+			expected: "toml: key a: cannot store a string in int",
+		},
+		{
+			input:    "[a]\nb = [1, \"x\"]",
+			v:        &struct{ A struct{ B []int } }{},
+			expected: "toml: key a.b.1: cannot store a string in int",
+		},
+		{
+			input:    "a.b = 1",
+			v:        &struct{ A string }{},
+			expected: "toml: key a: cannot store a table in string",
+		},
+		{
+			input:    "a = \"999.0.0.1\"",
+			v:        &struct{ A net.IP }{},
+			expected: "toml: key a: invalid IP address: 999.0.0.1",
+		},
+		{
+			input:    "a = 1",
+			v:        struct{ A int }{},
+			expected: "toml: Unmarshal requires a non-nil pointer",
+		},
+		{
+			input:    "a = 1\na = 2",
+			v:        &struct{ A int }{},
+			expected: "line 2, column 0: key a is already defined",
+		},
+	}
+	for i, test := range tests {
+		err := Unmarshal(input.NewFromString(test.input), test.v)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("test %v: for input '%v' expected error %q, got %v", i, test.input, test.expected, err)
+		}
+	}
+}
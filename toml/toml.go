@@ -0,0 +1,408 @@
+// Package toml is a TOML 1.0 (https://toml.io/en/v1.0.0) parser built from the parse
+// package's combinators.
+//
+// Documents are decoded into map[string]interface{} values, or into structs with Unmarshal.
+// Invalid documents, including those which define a key or table more than once, result in
+// a *parse.Error which contains the line and column of the problem.
+//
+// TOML values are decoded into Go values as follows:
+//
+//	string                    string
+//	integer                   int64
+//	float                     float64
+//	boolean                   bool
+//	offset date-time          time.Time
+//	local date-time           LocalDateTime
+//	local date                LocalDate
+//	local time                LocalTime
+//	array, array of tables    []interface{}
+//	table, inline table       map[string]interface{}
+package toml
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/parse"
+)
+
+// Decode parses a complete TOML document from the input, and returns its root table.
+func Decode(pi parse.Input) (map[string]interface{}, error) {
+	p := &parser{
+		input: pi,
+		root:  newTable(header),
+	}
+	p.current = p.root
+	if err := p.document(); err != nil {
+		return nil, err
+	}
+	return p.root.toMap(), nil
+}
+
+// DecodeBytes parses a complete TOML document, which must be valid UTF-8. Decode can't check
+// the encoding of a document, because invalid bytes have been replaced with U+FFFD by the time
+// that they're read from the input.
+func DecodeBytes(data []byte) (map[string]interface{}, error) {
+	if err := validUTF8(data); err != nil {
+		return nil, err
+	}
+	return Decode(input.NewFromString(string(data)))
+}
+
+// validUTF8 returns an error at the position of the first invalid UTF-8 sequence of the data.
+func validUTF8(data []byte) error {
+	pos := parse.Pos{Line: 1}
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size == 1 {
+			return parse.Errorf(pos, "invalid UTF-8 byte 0x%02x", data[0])
+		}
+		data = data[size:]
+		pos.Index++
+		pos.Column++
+		if r == '\n' {
+			pos.Line++
+			pos.Column = 0
+		}
+	}
+	return nil
+}
+
+// tableKind records how a table was defined, which determines how it can be extended.
+type tableKind int
+
+const (
+	// implicit tables are created as the parents of a table header, e.g. the a of [a.b]. They
+	// can be defined later.
+	implicit tableKind = iota
+	// header tables are defined by a table header, e.g. [a].
+	header
+	// dotted tables are defined by a dotted key, e.g. the a of a.b = 1. They can be extended
+	// with more dotted keys, and can have sub-tables, but can't be defined by a header.
+	dotted
+	// inline tables can't be changed once they've been defined.
+	inline
+)
+
+// table is a table while it's being parsed. Its values are *table, *tableArray or values.
+type table struct {
+	kind   tableKind
+	values map[string]interface{}
+}
+
+func newTable(kind tableKind) *table {
+	return &table{
+		kind:   kind,
+		values: map[string]interface{}{},
+	}
+}
+
+// tableArray is an array of tables, which is defined by [[headers]].
+type tableArray struct {
+	tables []*table
+}
+
+// freeze marks the table, and its sub-tables, as inline, so that they can't be changed.
+func (t *table) freeze() {
+	t.kind = inline
+	for _, v := range t.values {
+		if st, ok := v.(*table); ok {
+			st.freeze()
+		}
+	}
+}
+
+func (t *table) toMap() map[string]interface{} {
+	m := make(map[string]interface{}, len(t.values))
+	for k, v := range t.values {
+		m[k] = toInterface(v)
+	}
+	return m
+}
+
+func toInterface(v interface{}) interface{} {
+	switch v := v.(type) {
+	case *table:
+		return v.toMap()
+	case *tableArray:
+		values := make([]interface{}, len(v.tables))
+		for i, t := range v.tables {
+			values[i] = t.toMap()
+		}
+		return values
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i, e := range v {
+			values[i] = toInterface(e)
+		}
+		return values
+	}
+	return v
+}
+
+type parser struct {
+	input parse.Input
+	root  *table
+	// current is the table that key/value pairs are added to, i.e. the table of the last
+	// header, and path is its key.
+	current *table
+	path    []string
+}
+
+func (p *parser) document() error {
+	pi := p.input
+	for !parse.EOF(pi).Success {
+		ws(pi)
+		pos := parse.PosOf(pi)
+		var err error
+		switch {
+		case atLineEnd(pi):
+		case parse.Accept(pi, parse.String("[[")).Success:
+			err = p.tableArrayHeader(pos)
+		case parse.Accept(pi, parse.Rune('[')).Success:
+			err = p.tableHeader(pos)
+		default:
+			err = p.keyValue(p.current, p.path)
+		}
+		if err != nil {
+			return err
+		}
+		if err = lineEnd(pi); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var ws = parse.Span(parse.ClassOf(" \t"), 0, 0)
+var newline = parse.Or(parse.String("\r\n"), parse.String("\n"))
+
+// control is the set of control runes which aren't allowed in comments and strings. Tabs are
+// allowed.
+var control = parse.ClassRange(0, 8).Union(parse.ClassRange(0x0A, 0x1F), parse.ClassOf("\x7f"))
+var commentText = parse.Span(control.Not(), 0, 0)
+
+// atLineEnd returns true if the next rune starts a comment or a newline, or if it's the end of
+// the input, without consuming anything.
+func atLineEnd(pi parse.Input) bool {
+	start := pi.Index()
+	defer parse.RewindTo(pi, start)
+	return parse.RuneIn("#\r\n")(pi).Success || parse.EOF(pi).Success
+}
+
+// lineEnd captures optional whitespace and a comment, followed by a newline or the end of the
+// input.
+func lineEnd(pi parse.Input) error {
+	ws(pi)
+	if parse.Accept(pi, parse.Rune('#')).Success {
+		commentText(pi)
+	}
+	pos := parse.PosOf(pi)
+	if parse.Accept(pi, newline).Success || parse.EOF(pi).Success {
+		return nil
+	}
+	return parse.Unexpected(pi, pos)
+}
+
+// skip captures whitespace, comments and newlines, which can appear between the values of an
+// array.
+func skip(pi parse.Input) error {
+	for {
+		ws(pi)
+		if !atLineEnd(pi) || parse.EOF(pi).Success {
+			return nil
+		}
+		if err := lineEnd(pi); err != nil {
+			return err
+		}
+	}
+}
+
+var bareKeyRune = parse.ClassRange('a', 'z').Union(parse.ClassRange('A', 'Z'), parse.ClassRange('0', '9'), parse.ClassOf("_-"))
+var bareKey = parse.Span(bareKeyRune, 1, 0)
+
+// key captures a dotted key, e.g. a."b c".d, and returns its parts.
+func (p *parser) key() (keys []string, err error) {
+	pi := p.input
+	for {
+		pos := parse.PosOf(pi)
+		if r := parse.Accept(pi, bareKey); r.Success {
+			keys = append(keys, r.Item.(string))
+		} else {
+			s, ok, err := p.str(false)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, parse.Unexpected(pi, pos)
+			}
+			keys = append(keys, s)
+		}
+		start := pi.Index()
+		ws(pi)
+		if !parse.Accept(pi, parse.Rune('.')).Success {
+			parse.RewindTo(pi, start)
+			return keys, nil
+		}
+		ws(pi)
+	}
+}
+
+// keyName returns the dotted key as it would be written in a document.
+func keyName(keys []string) string {
+	quoted := make([]string, len(keys))
+	for i, k := range keys {
+		quoted[i] = k
+		if k == "" || strings.IndexFunc(k, func(r rune) bool { return !bareKeyRune.Contains(r) }) >= 0 {
+			quoted[i] = strconv.Quote(k)
+		}
+	}
+	return strings.Join(quoted, ".")
+}
+
+// keyValue captures a key/value pair, and adds it to the table. The path is the key of the
+// table, which is used in error messages.
+func (p *parser) keyValue(t *table, path []string) error {
+	pi := p.input
+	pos := parse.PosOf(pi)
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	ws(pi)
+	if !parse.Accept(pi, parse.Rune('=')).Success {
+		return parse.Errorf(parse.PosOf(pi), "expected '=' after the key %s", keyName(keys))
+	}
+	ws(pi)
+	// full is the key from the root table, which is used in error messages.
+	full := append(path[:len(path):len(path)], keys...)
+	v, err := p.value(full)
+	if err != nil {
+		return err
+	}
+	// Dotted keys define tables within the table.
+	for i, k := range keys[:len(keys)-1] {
+		name := keyName(full[:len(path)+i+1])
+		switch c := t.values[k].(type) {
+		case nil:
+			st := newTable(dotted)
+			t.values[k] = st
+			t = st
+		case *table:
+			if c.kind == inline {
+				return parse.Errorf(pos, "inline table %s can't be extended", name)
+			}
+			if c.kind == header {
+				return parse.Errorf(pos, "table %s is already defined", name)
+			}
+			c.kind = dotted
+			t = c
+		default:
+			return parse.Errorf(pos, "key %s is already defined", name)
+		}
+	}
+	k := keys[len(keys)-1]
+	if _, ok := t.values[k]; ok {
+		return parse.Errorf(pos, "key %s is already defined", keyName(full))
+	}
+	t.values[k] = v
+	return nil
+}
+
+// header captures the key of a table header, and the closing brackets.
+func (p *parser) header(closing string) (keys []string, err error) {
+	pi := p.input
+	ws(pi)
+	if keys, err = p.key(); err != nil {
+		return nil, err
+	}
+	ws(pi)
+	if !parse.Accept(pi, parse.String(closing)).Success {
+		return nil, parse.Errorf(parse.PosOf(pi), "expected '%s' at the end of the table header", closing)
+	}
+	return keys, nil
+}
+
+// walk returns the parent table of a header, creating implicit tables where they don't exist.
+// The parent of an array of tables is its last table.
+func (p *parser) walk(keys []string, pos parse.Pos) (t *table, err error) {
+	t = p.root
+	for i, k := range keys[:len(keys)-1] {
+		switch c := t.values[k].(type) {
+		case nil:
+			st := newTable(implicit)
+			t.values[k] = st
+			t = st
+		case *table:
+			if c.kind == inline {
+				return nil, parse.Errorf(pos, "inline table %s can't be extended", keyName(keys[:i+1]))
+			}
+			t = c
+		case *tableArray:
+			t = c.tables[len(c.tables)-1]
+		default:
+			return nil, parse.Errorf(pos, "key %s is already defined", keyName(keys[:i+1]))
+		}
+	}
+	return t, nil
+}
+
+func (p *parser) tableHeader(pos parse.Pos) error {
+	keys, err := p.header("]")
+	if err != nil {
+		return err
+	}
+	t, err := p.walk(keys, pos)
+	if err != nil {
+		return err
+	}
+	k := keys[len(keys)-1]
+	switch c := t.values[k].(type) {
+	case nil:
+		p.current = newTable(header)
+		t.values[k] = p.current
+	case *table:
+		if c.kind != implicit {
+			return parse.Errorf(pos, "table %s is already defined", keyName(keys))
+		}
+		c.kind = header
+		p.current = c
+	case *tableArray:
+		return parse.Errorf(pos, "%s is already defined as an array of tables", keyName(keys))
+	default:
+		return parse.Errorf(pos, "key %s is already defined", keyName(keys))
+	}
+	p.path = keys
+	return nil
+}
+
+func (p *parser) tableArrayHeader(pos parse.Pos) error {
+	keys, err := p.header("]]")
+	if err != nil {
+		return err
+	}
+	t, err := p.walk(keys, pos)
+	if err != nil {
+		return err
+	}
+	k := keys[len(keys)-1]
+	var a *tableArray
+	switch c := t.values[k].(type) {
+	case nil:
+		a = &tableArray{}
+		t.values[k] = a
+	case *tableArray:
+		a = c
+	case *table:
+		return parse.Errorf(pos, "table %s is already defined", keyName(keys))
+	case []interface{}:
+		return parse.Errorf(pos, "array %s is already defined, and can't be extended", keyName(keys))
+	default:
+		return parse.Errorf(pos, "key %s is already defined", keyName(keys))
+	}
+	p.current, p.path = newTable(header), keys
+	a.tables = append(a.tables, p.current)
+	return nil
+}
@@ -0,0 +1,221 @@
+package toml
+
+import (
+	"encoding/json"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/lexical/input"
+)
+
+// fixtures returns the .toml files of the kind, valid or invalid, of this repository's test
+// fixtures, and of the cases of the TOML test suite.
+func fixtures(t *testing.T, kind string) (files []string) {
+	for _, dir := range []string{"testdata", filepath.Join("testdata", "toml-test")} {
+		err := filepath.WalkDir(filepath.Join(dir, kind), func(path string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && filepath.Ext(path) == ".toml" {
+				files = append(files, path)
+			}
+			return err
+		})
+		if err != nil {
+			t.Fatalf("failed to find test fixtures: %v", err)
+		}
+	}
+	if len(files) == 0 {
+		t.Fatalf("failed to find test fixtures in %s", kind)
+	}
+	return files
+}
+
+func TestValidFixtures(t *testing.T) {
+	for _, file := range fixtures(t, "valid") {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", file, err)
+		}
+		expectedJSON, err := os.ReadFile(strings.TrimSuffix(file, ".toml") + ".json")
+		if err != nil {
+			t.Fatalf("failed to read the JSON for %s: %v", file, err)
+		}
+		var expected interface{}
+		if err := json.Unmarshal(expectedJSON, &expected); err != nil {
+			t.Fatalf("failed to parse the JSON for %s: %v", file, err)
+		}
+		m, err := DecodeBytes(src)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", file, err)
+			continue
+		}
+		if actual, expected := tagged(m), normalize(expected); !reflect.DeepEqual(actual, expected) {
+			actualJSON, _ := json.MarshalIndent(actual, "", "  ")
+			expectedJSON, _ := json.MarshalIndent(expected, "", "  ")
+			t.Errorf("%s:\nexpected %s\ngot      %s", file, expectedJSON, actualJSON)
+		}
+	}
+}
+
+func TestInvalidFixtures(t *testing.T) {
+	for _, file := range fixtures(t, "invalid") {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", file, err)
+		}
+		if m, err := DecodeBytes(src); err == nil {
+			t.Errorf("%s: expected an error, got %v", file, m)
+		}
+	}
+}
+
+// tagged converts a decoded value into the JSON encoding of the TOML test suite.
+func tagged(v interface{}) interface{} {
+	tag := func(t, v string) interface{} {
+		return map[string]interface{}{"type": t, "value": v}
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = tagged(e)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, e := range v {
+			a[i] = tagged(e)
+		}
+		return a
+	case string:
+		return tag("string", v)
+	case int64:
+		return tag("integer", strconv.FormatInt(v, 10))
+	case float64:
+		return tag("float", formatFloat(v))
+	case bool:
+		return tag("bool", strconv.FormatBool(v))
+	case time.Time:
+		return tag("datetime", v.Format(time.RFC3339Nano))
+	case LocalDateTime:
+		return tag("datetime-local", v.String())
+	case LocalDate:
+		return tag("date-local", v.String())
+	case LocalTime:
+		return tag("time-local", v.String())
+	}
+	panic("unexpected type")
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// normalize puts the expected values of the test suite's JSON into the same form as tagged,
+// e.g. "+inf" becomes "inf".
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if t, ok := v["type"].(string); ok && len(v) == 2 {
+			s := v["value"].(string)
+			switch t {
+			case "float":
+				f, err := strconv.ParseFloat(strings.TrimPrefix(s, "+"), 64)
+				if err != nil {
+					panic(err)
+				}
+				s = formatFloat(f)
+			case "datetime":
+				d, err := time.Parse(time.RFC3339Nano, s)
+				if err != nil {
+					panic(err)
+				}
+				s = d.Format(time.RFC3339Nano)
+			}
+			return map[string]interface{}{"type": t, "value": s}
+		}
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = normalize(e)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, e := range v {
+			a[i] = normalize(e)
+		}
+		return a
+	}
+	return v
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "a = 1\na = 2", expected: "line 2, column 0: key a is already defined"},
+		{input: "[a]\n[a]", expected: "line 2, column 0: table a is already defined"},
+		{input: "[a]\nb.c = 1\n[a.b]", expected: "line 3, column 0: table a.b is already defined"},
+		{input: "[\"a b\".c]\n[\"a b\".c]", expected: "line 2, column 0: table \"a b\".c is already defined"},
+		{input: "a = { b = 1 }\n[a.c]", expected: "line 2, column 0: inline table a can't be extended"},
+		{input: "[a.b]\nc = 1\nc = 2", expected: "line 3, column 0: key a.b.c is already defined"},
+		{input: "[a]\nb = { c = 1, c = 2 }", expected: "line 2, column 13: key a.b.c is already defined"},
+		{input: "[a]\n[[a]]", expected: "line 2, column 0: table a is already defined"},
+		{input: "[[a]]\n[a]", expected: "line 2, column 0: a is already defined as an array of tables"},
+		{input: "a = [1]\n[[a]]", expected: "line 2, column 0: array a is already defined, and can't be extended"},
+		{input: "a = [1, 2\n", expected: "line 2, column 0: unexpected end of input"},
+		{input: "a = 1 b = 2", expected: "line 1, column 6: unexpected 'b'"},
+		{input: "a", expected: "line 1, column 1: expected '=' after the key a"},
+		{input: "[a", expected: "line 1, column 2: expected ']' at the end of the table header"},
+		{input: "a = \"abc", expected: "line 1, column 4: unterminated string"},
+		{input: "a = \"\\q\"", expected: "line 1, column 5: invalid escape sequence \\q"},
+		{input: "a = 0123", expected: "line 1, column 4: leading zeros are not allowed in 0123"},
+		{input: "a = 1__000", expected: "line 1, column 4: underscores in 1__000 must be between digits"},
+		{input: "a = 9223372036854775808", expected: "line 1, column 4: integer 9223372036854775808 out of range"},
+		{input: "a = 2001-02-29", expected: "line 1, column 12: day 29 out of range for February 2001"},
+		{input: "a = 1979-05-27T07:60:00Z", expected: "line 1, column 18: minute 60 out of range"},
+		{input: "a = 07:32", expected: "line 1, column 9: expected the seconds of the time"},
+		{input: "a = 07:32:00+01:00", expected: "line 1, column 12: unexpected '+'"},
+	}
+	for i, test := range tests {
+		_, err := Decode(input.NewFromString(test.input))
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("test %v: for input '%v' expected error %q, got %v", i, test.input, test.expected, err)
+		}
+	}
+}
+
+func TestDecodeBytes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "a = \"\xff\"", expected: "line 1, column 5: invalid UTF-8 byte 0xff"},
+		{input: "a = 1\n# \xed\xa0\x80", expected: "line 2, column 2: invalid UTF-8 byte 0xed"},
+		{input: "a = \"\ufffd\"\nb = \"é\"", expected: ""},
+	}
+	for i, test := range tests {
+		_, err := DecodeBytes([]byte(test.input))
+		var actual string
+		if err != nil {
+			actual = err.Error()
+		}
+		if actual != test.expected {
+			t.Errorf("test %v: for input '%v' expected error %q, got %v", i, test.input, test.expected, err)
+		}
+	}
+}
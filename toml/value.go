@@ -0,0 +1,344 @@
+package toml
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// value captures any TOML value. The path is the key of the value, which is used in error
+// messages.
+func (p *parser) value(path []string) (v interface{}, err error) {
+	pi := p.input
+	pos := parse.PosOf(pi)
+	if s, ok, err := p.str(true); ok || err != nil {
+		return s, err
+	}
+	if parse.Accept(pi, parse.Rune('[')).Success {
+		return p.array(path)
+	}
+	if parse.Accept(pi, parse.Rune('{')).Success {
+		return p.inlineTable(path)
+	}
+	if r := parse.Accept(pi, boolean); r.Success {
+		return r.Item, nil
+	}
+	if v, ok, err := p.datetime(); ok || err != nil {
+		return v, err
+	}
+	if v, ok, err := p.number(); ok || err != nil {
+		return v, err
+	}
+	return nil, parse.Unexpected(pi, pos)
+}
+
+var boolean = parse.Or(
+	parse.All(func([]interface{}) (interface{}, bool) { return true, true }, parse.String("true")),
+	parse.All(func([]interface{}) (interface{}, bool) { return false, true }, parse.String("false")),
+)
+
+// array captures the rest of an array, after the opening bracket. The values can be
+// separated by newlines and comments, and may be followed by a trailing comma.
+func (p *parser) array(path []string) (v interface{}, err error) {
+	pi := p.input
+	values := []interface{}{}
+	for {
+		if err = skip(pi); err != nil {
+			return nil, err
+		}
+		if parse.Accept(pi, parse.Rune(']')).Success {
+			return values, nil
+		}
+		if v, err = p.value(path); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		if err = skip(pi); err != nil {
+			return nil, err
+		}
+		if parse.Accept(pi, parse.Rune(',')).Success {
+			continue
+		}
+		pos := parse.PosOf(pi)
+		if !parse.Accept(pi, parse.Rune(']')).Success {
+			return nil, parse.Unexpected(pi, pos)
+		}
+		return values, nil
+	}
+}
+
+// inlineTable captures the rest of an inline table, after the opening brace. Inline tables
+// must be written on a single line, and can't have a trailing comma.
+func (p *parser) inlineTable(path []string) (v interface{}, err error) {
+	pi := p.input
+	t := newTable(dotted)
+	ws(pi)
+	if !parse.Accept(pi, parse.Rune('}')).Success {
+		for {
+			if err = p.keyValue(t, path); err != nil {
+				return nil, err
+			}
+			ws(pi)
+			if parse.Accept(pi, parse.Rune(',')).Success {
+				ws(pi)
+				continue
+			}
+			pos := parse.PosOf(pi)
+			if !parse.Accept(pi, parse.Rune('}')).Success {
+				return nil, parse.Unexpected(pi, pos)
+			}
+			break
+		}
+	}
+	t.freeze()
+	return t, nil
+}
+
+var basicText = parse.Span(control.Union(parse.ClassOf(`"\`)).Not(), 1, 0)
+var literalText = parse.Span(control.Union(parse.ClassOf(`'`)).Not(), 1, 0)
+
+// str captures a basic or literal string. If multiLine is true, multi-line strings are also
+// captured.
+func (p *parser) str(multiLine bool) (s string, ok bool, err error) {
+	pi := p.input
+	pos := parse.PosOf(pi)
+	switch {
+	case multiLine && parse.Accept(pi, parse.String(`"""`)).Success:
+		s, err = p.stringContents(pos, '"', basicText, true)
+	case multiLine && parse.Accept(pi, parse.String(`'''`)).Success:
+		s, err = p.stringContents(pos, '\'', literalText, true)
+	case parse.Accept(pi, parse.Rune('"')).Success:
+		s, err = p.stringContents(pos, '"', basicText, false)
+	case parse.Accept(pi, parse.Rune('\'')).Success:
+		s, err = p.stringContents(pos, '\'', literalText, false)
+	default:
+		return "", false, nil
+	}
+	return s, true, err
+}
+
+// stringContents captures the rest of a string, after the opening quotes. Basic strings,
+// which are quoted with '"', can contain escape sequences.
+func (p *parser) stringContents(start parse.Pos, quote rune, text parse.Function, multiLine bool) (s string, err error) {
+	pi := p.input
+	var sb strings.Builder
+	if multiLine {
+		// A newline immediately after the opening quotes is trimmed.
+		parse.Accept(pi, newline)
+	}
+	for {
+		if t := text(pi); t.Success {
+			sb.WriteString(t.Item.(string))
+			continue
+		}
+		pos := parse.PosOf(pi)
+		if multiLine {
+			if nl := parse.Accept(pi, newline); nl.Success {
+				sb.WriteString(nl.Item.(string))
+				continue
+			}
+		}
+		if quote == '"' && parse.Accept(pi, parse.Rune('\\')).Success {
+			if err = p.escape(&sb, pos, multiLine); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if parse.Accept(pi, parse.Rune(quote)).Success {
+			if !multiLine {
+				return sb.String(), nil
+			}
+			// Up to two quotes can be written before the closing quotes of a multi-line string.
+			quotes := 1
+			for quotes < 6 && parse.Accept(pi, parse.Rune(quote)).Success {
+				quotes++
+			}
+			if quotes < 3 {
+				sb.WriteString(strings.Repeat(string(quote), quotes))
+				continue
+			}
+			if quotes > 5 {
+				return "", parse.Errorf(pos, "too many quotes at the end of a multi-line string")
+			}
+			sb.WriteString(strings.Repeat(string(quote), quotes-3))
+			return sb.String(), nil
+		}
+		r := parse.Accept(pi, parse.AnyRune())
+		if !r.Success || (!multiLine && lineEndRune.Contains(r.Item.(rune))) {
+			return "", parse.Errorf(start, "unterminated string")
+		}
+		return "", parse.Errorf(pos, "control character %U in string", r.Item)
+	}
+}
+
+var lineEndRune = parse.ClassOf("\r\n")
+var hexDigit = parse.ClassRange('0', '9').Union(parse.ClassRange('a', 'f'), parse.ClassRange('A', 'F'))
+var lineEndingBackslash = parse.All(parse.WithStringConcatCombiner, ws, newline, parse.Span(parse.ClassOf(" \t\r\n"), 0, 0))
+
+var escapes = map[rune]string{
+	'b':  "\b",
+	't':  "\t",
+	'n':  "\n",
+	'f':  "\f",
+	'r':  "\r",
+	'"':  `"`,
+	'\\': `\`,
+}
+
+// escape captures an escape sequence after the backslash.
+func (p *parser) escape(sb *strings.Builder, pos parse.Pos, multiLine bool) error {
+	pi := p.input
+	if multiLine && parse.Accept(pi, lineEndingBackslash).Success {
+		// A backslash at the end of a line trims the whitespace and newlines which follow.
+		return nil
+	}
+	r := parse.Accept(pi, parse.AnyRune())
+	if !r.Success {
+		return parse.Errorf(pos, "unterminated string")
+	}
+	c := r.Item.(rune)
+	if e, ok := escapes[c]; ok {
+		sb.WriteString(e)
+		return nil
+	}
+	if c != 'u' && c != 'U' {
+		return parse.Errorf(pos, "invalid escape sequence \\%c", c)
+	}
+	n := 4
+	if c == 'U' {
+		n = 8
+	}
+	h := parse.Accept(pi, parse.Span(hexDigit, n, n))
+	if !h.Success {
+		return parse.Errorf(pos, "invalid escape sequence, expected %d hex digits after \\%c", n, c)
+	}
+	v, _ := strconv.ParseUint(h.Item.(string), 16, 32)
+	if !utf8.ValidRune(rune(v)) {
+		return parse.Errorf(pos, "invalid escape sequence \\%c%s, which isn't a Unicode scalar value", c, h.Item)
+	}
+	sb.WriteRune(rune(v))
+	return nil
+}
+
+var sign = parse.RuneIn("+-")
+var decimalDigits = parse.ClassRange('0', '9')
+var special = parse.OneOfStrings("inf", "nan")
+
+// radixes are the prefixes of non-decimal integers, and the digits they can contain.
+var radixes = []struct {
+	prefix string
+	base   int
+	digits parse.Class
+}{
+	{prefix: "0x", base: 16, digits: hexDigit},
+	{prefix: "0o", base: 8, digits: parse.ClassRange('0', '7')},
+	{prefix: "0b", base: 2, digits: parse.ClassRange('0', '1')},
+}
+
+// number captures an integer or a float.
+func (p *parser) number() (v interface{}, ok bool, err error) {
+	pi := p.input
+	pos := parse.PosOf(pi)
+	var text string
+	if r := parse.Accept(pi, sign); r.Success {
+		text = string(r.Item.(rune))
+	}
+	if r := parse.Accept(pi, special); r.Success {
+		if r.Item.(string) == "nan" {
+			return math.NaN(), true, nil
+		}
+		if text == "-" {
+			return math.Inf(-1), true, nil
+		}
+		return math.Inf(1), true, nil
+	}
+	if text == "" {
+		for _, radix := range radixes {
+			if !parse.Accept(pi, parse.String(radix.prefix)).Success {
+				continue
+			}
+			digits, ok, err := underscored(pi, radix.digits)
+			if err != nil {
+				return nil, true, err
+			}
+			if !ok {
+				return nil, true, parse.Unexpected(pi, parse.PosOf(pi))
+			}
+			i, err := strconv.ParseInt(digits, radix.base, 64)
+			if err != nil {
+				return nil, true, parse.Errorf(pos, "integer %s%s out of range", radix.prefix, digits)
+			}
+			return i, true, nil
+		}
+	}
+	digits, ok, err := underscored(pi, decimalDigits)
+	if err != nil || !ok {
+		parse.RewindTo(pi, pos.Index)
+		return nil, false, err
+	}
+	if len(digits) > 1 && digits[0] == '0' {
+		return nil, true, parse.Errorf(pos, "leading zeros are not allowed in %s", digits)
+	}
+	text += digits
+	float := false
+	if parse.Accept(pi, parse.Rune('.')).Success {
+		digits, ok, err := underscored(pi, decimalDigits)
+		if err != nil {
+			return nil, true, err
+		}
+		if !ok {
+			return nil, true, parse.Errorf(parse.PosOf(pi), "expected digits after the decimal point")
+		}
+		text += "." + digits
+		float = true
+	}
+	if r := parse.Accept(pi, parse.RuneIn("eE")); r.Success {
+		text += "e"
+		if r := parse.Accept(pi, sign); r.Success {
+			text += string(r.Item.(rune))
+		}
+		digits, ok, err := underscored(pi, decimalDigits)
+		if err != nil {
+			return nil, true, err
+		}
+		if !ok {
+			return nil, true, parse.Errorf(parse.PosOf(pi), "expected digits in the exponent")
+		}
+		text += digits
+		float = true
+	}
+	if float {
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, true, parse.Errorf(pos, "float %s out of range", text)
+		}
+		return f, true, nil
+	}
+	i, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return nil, true, parse.Errorf(pos, "integer %s out of range", text)
+	}
+	return i, true, nil
+}
+
+// underscored captures digits in the class, which may be separated by single underscores,
+// and returns the digits without the underscores.
+func underscored(pi parse.Input, digits parse.Class) (s string, ok bool, err error) {
+	pos := parse.PosOf(pi)
+	r := parse.Accept(pi, parse.Span(digits.Union(parse.ClassOf("_")), 1, 0))
+	if !r.Success {
+		return "", false, nil
+	}
+	s = r.Item.(string)
+	if s[0] == '_' {
+		parse.RewindTo(pi, pos.Index)
+		return "", false, nil
+	}
+	if s[len(s)-1] == '_' || strings.Contains(s, "__") {
+		return "", false, parse.Errorf(pos, "underscores in %s must be between digits", s)
+	}
+	return strings.Replace(s, "_", "", -1), true, nil
+}
@@ -0,0 +1,342 @@
+// Package uri contains parsers for RFC 3986 URIs and URI references.
+//
+// The parsers are parse.Functions, so a URI can be captured from within a larger grammar,
+// e.g. the request line of an access log, without a second pass. Parsing stops at the first
+// rune which can't be part of a URI, such as a space.
+package uri
+
+import (
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/lexical/ip"
+	"github.com/a-h/lexical/parse"
+)
+
+// HostType is the syntax used to write the host of a URI.
+type HostType int
+
+// The types of host.
+const (
+	// RegName is a registered name, e.g. example.com.
+	RegName HostType = iota
+	// IPv4 is an IPv4 address, e.g. 192.0.2.1.
+	IPv4
+	// IPv6 is an IPv6 address written in square brackets, e.g. [2001:db8::1].
+	IPv6
+	// IPvFuture is a future IP version written in square brackets, e.g. [v7.abc].
+	IPvFuture
+)
+
+// URI is a parsed URI or URI reference. Components which contain percent-encoded octets are
+// decoded, and their raw form is kept alongside them. Components which can be present but
+// empty, e.g. the query of "http://example.com/?", have a flag which records whether they're
+// present.
+type URI struct {
+	Scheme string
+
+	// HasAuthority is true if the URI has an authority, which starts with "//".
+	HasAuthority bool
+	HasUserinfo  bool
+	Userinfo     string
+	RawUserinfo  string
+	Host         string
+	HostType     HostType
+	// IP is the address of an IPv4 or IPv6 host.
+	IP net.IP
+	// Zone is the zone of an IPv6 host, e.g. the "en1" of [fe80::1%25en1], as defined in
+	// RFC 6874.
+	Zone string
+	Port string
+
+	Path    string
+	RawPath string
+
+	HasQuery bool
+	// Query is the query as it was written. It's not decoded, because decoding it depends on
+	// how it's used, e.g. with url.ParseQuery.
+	Query string
+
+	HasFragment bool
+	Fragment    string
+	RawFragment string
+}
+
+// URL converts the URI to a *url.URL, in the same form as url.Parse.
+func (u *URI) URL() *url.URL {
+	v := &url.URL{
+		Scheme:   u.Scheme,
+		Path:     u.Path,
+		RawQuery: u.Query,
+		Fragment: u.Fragment,
+	}
+	v.ForceQuery = u.HasQuery && u.Query == ""
+	if u.Scheme != "" && !u.HasAuthority && !strings.HasPrefix(u.RawPath, "/") {
+		v.Opaque, v.Path = u.RawPath, ""
+	}
+	if v.Path != "" && v.EscapedPath() != u.RawPath {
+		v.RawPath = u.RawPath
+	}
+	if v.Fragment != "" && v.EscapedFragment() != u.RawFragment {
+		v.RawFragment = u.RawFragment
+	}
+	if u.HasUserinfo {
+		// Split the raw form, so that an encoded colon in the user name doesn't end it.
+		if i := strings.IndexByte(u.RawUserinfo, ':'); i >= 0 {
+			v.User = url.UserPassword(decode(u.RawUserinfo[:i]), decode(u.RawUserinfo[i+1:]))
+		} else {
+			v.User = url.User(u.Userinfo)
+		}
+	}
+	if u.HasAuthority {
+		v.Host = u.Host
+		if u.HostType == IPv6 || u.HostType == IPvFuture {
+			v.Host = "[" + u.Host
+			if u.Zone != "" {
+				v.Host += "%" + u.Zone
+			}
+			v.Host += "]"
+		}
+		if u.Port != "" {
+			v.Host += ":" + u.Port
+		}
+	}
+	return v
+}
+
+// String returns the URI in the form of url.URL's String method.
+func (u *URI) String() string {
+	return u.URL().String()
+}
+
+// Parser captures a URI, which must have a scheme, e.g. https://example.com/path?q#f, and
+// returns a *URI.
+var Parser parse.Function = func(pi parse.Input) parse.Result {
+	return parseURI(pi, true)
+}
+
+// ReferenceParser captures a URI reference, which is a URI, or a relative reference such as
+// //example.com/path, /path?q or path#f, and returns a *URI. Although RFC 3986 allows an
+// empty reference, the parser must capture at least one rune to succeed.
+var ReferenceParser parse.Function = func(pi parse.Input) parse.Result {
+	return parseURI(pi, false)
+}
+
+var alpha = parse.ClassRange('a', 'z').Union(parse.ClassRange('A', 'Z'))
+var digit = parse.ClassRange('0', '9')
+var hexDigit = digit.Union(parse.ClassRange('a', 'f'), parse.ClassRange('A', 'F'))
+var unreserved = alpha.Union(digit, parse.ClassOf("-._~"))
+var subDelims = parse.ClassOf("!$&'()*+,;=")
+var pchar = unreserved.Union(subDelims, parse.ClassOf(":@"))
+
+var scheme = parse.All(func(items []interface{}) (interface{}, bool) {
+	return strings.ToLower(items[0].(string) + items[1].(string)), true
+}, parse.Span(alpha, 1, 1), parse.Span(alpha.Union(digit, parse.ClassOf("+-.")), 0, 0), parse.Rune(':'))
+
+// percentEncoded captures a percent-encoded octet, e.g. %20.
+func percentEncoded(pi parse.Input) parse.Result {
+	name := "percent-encoded octet"
+	start := pi.Index()
+	pos := parse.PosOf(pi)
+	if r := parse.Rune('%')(pi); !r.Success {
+		parse.RewindTo(pi, start)
+		return parse.Failure(name, nil)
+	}
+	r := hexDigits(pi)
+	if !r.Success {
+		parse.RewindTo(pi, start)
+		return parse.Failure(name, parse.Errorf(pos, "invalid percent-encoding"))
+	}
+	return parse.Success(name, "%"+r.Item.(string), nil)
+}
+
+var hexDigits = parse.Span(hexDigit, 2, 2)
+
+// text captures runes from the class, and percent-encoded octets, and returns the raw text.
+func text(c parse.Class) parse.Function {
+	return parse.Many(parse.WithStringConcatCombiner, 0, 0, parse.Any(parse.Span(c, 1, 0), percentEncoded))
+}
+
+var userinfo = parse.All(func(items []interface{}) (interface{}, bool) {
+	return items[0], true
+}, text(unreserved.Union(subDelims, parse.ClassOf(":"))), parse.Rune('@'))
+
+var regName = text(unreserved.Union(subDelims))
+var port = parse.Span(digit, 0, 0)
+var path = text(pchar.Union(parse.ClassOf("/")))
+var query = text(pchar.Union(parse.ClassOf("/?")))
+
+// failed returns true if the result is an error, rather than a non-match.
+func failed(r parse.Result) bool {
+	return r.Error != nil && r.Error != io.EOF
+}
+
+func parseURI(pi parse.Input, requireScheme bool) parse.Result {
+	name := "uri"
+	start := pi.Index()
+	fail := func(err error) parse.Result {
+		parse.RewindTo(pi, start)
+		return parse.Failure(name, err)
+	}
+	u := &URI{}
+	if r := parse.Accept(pi, scheme); r.Success {
+		u.Scheme = r.Item.(string)
+	} else if requireScheme {
+		return fail(nil)
+	}
+
+	if parse.Accept(pi, parse.String("//")).Success {
+		u.HasAuthority = true
+		if err := parseAuthority(pi, u); err != nil {
+			return fail(err)
+		}
+	}
+
+	pathPos := parse.PosOf(pi)
+	r := path(pi)
+	if failed(r) {
+		return fail(r.Error)
+	}
+	u.RawPath = r.Item.(string)
+	if u.HasAuthority && u.RawPath != "" && !strings.HasPrefix(u.RawPath, "/") {
+		// The path after an authority must be empty or start with a slash, so the URI ends
+		// at the authority.
+		parse.RewindTo(pi, pathPos.Index)
+		u.RawPath = ""
+	}
+	if u.Scheme == "" && !u.HasAuthority {
+		// The first segment of a relative path can't contain a colon, otherwise it would be
+		// mistaken for a scheme.
+		segment := strings.SplitN(u.RawPath, "/", 2)[0]
+		if strings.Contains(segment, ":") {
+			return fail(nil)
+		}
+	}
+	u.Path = decode(u.RawPath)
+
+	if parse.Accept(pi, parse.Rune('?')).Success {
+		r := query(pi)
+		if failed(r) {
+			return fail(r.Error)
+		}
+		u.HasQuery, u.Query = true, r.Item.(string)
+	}
+	if parse.Accept(pi, parse.Rune('#')).Success {
+		r := query(pi)
+		if failed(r) {
+			return fail(r.Error)
+		}
+		u.HasFragment, u.RawFragment = true, r.Item.(string)
+		u.Fragment = decode(u.RawFragment)
+	}
+	if pi.Index() == start {
+		return fail(nil)
+	}
+	return parse.Success(name, u, nil)
+}
+
+func parseAuthority(pi parse.Input, u *URI) error {
+	if r := parse.Accept(pi, userinfo); r.Success {
+		u.HasUserinfo, u.RawUserinfo = true, r.Item.(string)
+		u.Userinfo = decode(u.RawUserinfo)
+	} else if failed(r) {
+		return r.Error
+	}
+	if err := parseHost(pi, u); err != nil {
+		return err
+	}
+	if !parse.Accept(pi, parse.Rune(':')).Success {
+		return nil
+	}
+	pos := parse.PosOf(pi)
+	u.Port = port(pi).Item.(string)
+	if u.Port != "" {
+		if p, err := strconv.ParseUint(u.Port, 10, 16); err != nil || p > 65535 {
+			return parse.Errorf(pos, "port %s out of range", u.Port)
+		}
+	}
+	return nil
+}
+
+func parseHost(pi parse.Input, u *URI) error {
+	pos := parse.PosOf(pi)
+	if parse.Accept(pi, parse.Rune('[')).Success {
+		return parseIPLiteral(pi, u, pos)
+	}
+	start := pi.Index()
+	if r := ip.IPv4(pi); r.Success {
+		// An IPv4 address which is followed by more of a registered name, e.g. 192.0.2.1.com,
+		// is a registered name.
+		if !parse.Accept(pi, parse.RuneInClass(regNameStart)).Success {
+			u.Host, u.HostType, u.IP = r.Item.(net.IP).String(), IPv4, r.Item.(net.IP)
+			return nil
+		}
+		parse.RewindTo(pi, start)
+	}
+	r := regName(pi)
+	if failed(r) {
+		return r.Error
+	}
+	u.Host = decode(r.Item.(string))
+	return nil
+}
+
+var regNameStart = unreserved.Union(subDelims, parse.ClassOf("%"))
+
+var ipLiteral = parse.Span(hexDigit.Union(parse.ClassOf(":.")), 1, 0)
+var ipvFuture = parse.All(parse.WithStringConcatCombiner,
+	parse.RuneIn("vV"), parse.Span(hexDigit, 1, 0), parse.Rune('.'), parse.Span(unreserved.Union(subDelims, parse.ClassOf(":")), 1, 0))
+var zone = parse.Then(func(items []interface{}) (interface{}, bool) {
+	return items[1], true
+}, parse.String("%25"), text(unreserved))
+
+// parseIPLiteral parses the rest of an IP literal, after the opening square bracket.
+func parseIPLiteral(pi parse.Input, u *URI, pos parse.Pos) error {
+	if r := parse.Accept(pi, ipvFuture); r.Success {
+		u.Host, u.HostType = r.Item.(string), IPvFuture
+	} else {
+		// The host is kept as it was written, so the text of the address is captured before
+		// it's parsed.
+		start := pi.Index()
+		text := parse.Accept(pi, ipLiteral)
+		parse.RewindTo(pi, start)
+		r := ip.IPv6(pi)
+		if !r.Success {
+			if r.Error != nil {
+				return r.Error
+			}
+			return parse.Errorf(pos, "invalid IP literal")
+		}
+		u.Host, u.HostType, u.IP = text.Item.(string)[:pi.Index()-start], IPv6, r.Item.(net.IP)
+		if r := parse.Accept(pi, zone); r.Success {
+			u.Zone = decode(r.Item.(string))
+		} else if failed(r) {
+			return r.Error
+		}
+	}
+	if !parse.Accept(pi, parse.Rune(']')).Success {
+		return parse.Errorf(parse.PosOf(pi), "expected ']' at the end of the IP literal")
+	}
+	return nil
+}
+
+// decode decodes the percent-encoded octets of text which has been validated.
+func decode(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) {
+			v, _ := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			sb.WriteByte(byte(v))
+			i += 2
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
@@ -0,0 +1,367 @@
+package xml
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/a-h/lexical/parse"
+)
+
+// Name is the name of an element or attribute. Space is the namespace URI that the prefix
+// refers to, or the prefix itself if it hasn't been declared.
+type Name struct {
+	Space  string
+	Prefix string
+	Local  string
+}
+
+// String returns the name as it was written in the document, e.g. "xs:element".
+func (n Name) String() string {
+	if n.Prefix == "" {
+		return n.Local
+	}
+	return n.Prefix + ":" + n.Local
+}
+
+// Attr is an attribute of an element, e.g. key="value". The value has its entity
+// references decoded, and its whitespace normalized.
+type Attr struct {
+	Name  Name
+	Value string
+}
+
+// StartElement is a start tag, e.g. <div class="x">. Self closing tags, e.g. <br/>, are
+// followed by an EndElement.
+type StartElement struct {
+	Name        Name
+	Attr        []Attr
+	SelfClosing bool
+}
+
+// EndElement is an end tag, e.g. </div>.
+type EndElement struct {
+	Name Name
+}
+
+// CharData is text between tags, with its entity references decoded. Long runs of text are
+// split into multiple CharData tokens, to limit the amount of memory used.
+type CharData string
+
+// CData is the text within a CDATA section, e.g. <![CDATA[text]]>.
+type CData string
+
+// Comment is the text within a comment, e.g. <!--text-->.
+type Comment string
+
+// ProcInst is a processing instruction, e.g. <?xml version="1.0"?>.
+type ProcInst struct {
+	Target string
+	Inst   string
+}
+
+// Directive is the text within a directive, e.g. <!DOCTYPE html>.
+type Directive string
+
+var nameStart = parse.ClassOf(":_").Union(
+	parse.ClassRange('A', 'Z'),
+	parse.ClassRange('a', 'z'),
+	parse.ClassRange(0xC0, 0xD6),
+	parse.ClassRange(0xD8, 0xF6),
+	parse.ClassRange(0xF8, 0x2FF),
+	parse.ClassRange(0x370, 0x37D),
+	parse.ClassRange(0x37F, 0x1FFF),
+	parse.ClassRange(0x200C, 0x200D),
+	parse.ClassRange(0x2070, 0x218F),
+	parse.ClassRange(0x2C00, 0x2FEF),
+	parse.ClassRange(0x3001, 0xD7FF),
+	parse.ClassRange(0xF900, 0xFDCF),
+	parse.ClassRange(0xFDF0, 0xFFFD),
+	parse.ClassRange(0x10000, 0xEFFFF),
+)
+
+var nameChar = nameStart.Union(
+	parse.ClassOf("-.·"),
+	parse.ClassRange('0', '9'),
+	parse.ClassRange(0x300, 0x36F),
+	parse.ClassRange(0x203F, 0x2040),
+)
+
+var whitespaceClass = parse.ClassOf(" \t\r\n")
+var whitespace = parse.Span(whitespaceClass, 1, 0)
+var optionalWhitespace = parse.Span(whitespaceClass, 0, 0)
+
+var rawName = parse.Then(parse.WithStringConcatCombiner,
+	parse.RuneInClass(nameStart),
+	parse.Span(nameChar, 0, 0),
+)
+
+var name = parse.All(func(items []interface{}) (interface{}, bool) {
+	s := items[0].(string)
+	if i := strings.IndexRune(s, ':'); i > 0 && i < len(s)-1 {
+		return Name{Prefix: s[:i], Local: s[i+1:]}, true
+	}
+	return Name{Local: s}, true
+}, rawName)
+
+var entities = map[string]string{
+	"lt":   "<",
+	"gt":   ">",
+	"amp":  "&",
+	"apos": "'",
+	"quot": `"`,
+}
+
+var referenceSyntax = parse.All(func(items []interface{}) (interface{}, bool) {
+	return items[1], true
+}, parse.Rune('&'), parse.Or(
+	parse.All(parse.WithStringConcatCombiner,
+		parse.Rune('#'),
+		parse.Or(
+			parse.Then(parse.WithStringConcatCombiner, parse.Rune('x'), parse.Span(parse.ClassRange('0', '9').Union(parse.ClassRange('a', 'f'), parse.ClassRange('A', 'F')), 1, 8)),
+			parse.Span(parse.ClassRange('0', '9'), 1, 10),
+		),
+	),
+	rawName,
+), parse.Rune(';'))
+
+// reference captures an entity or character reference, e.g. &amp; or &#x41;, and returns
+// the text it refers to.
+func reference(pi parse.Input) parse.Result {
+	pos := parse.PosOf(pi)
+	if r := parse.Rune('&')(pi); !r.Success {
+		return r
+	}
+	pi.Retreat()
+	r := referenceSyntax(pi)
+	if !r.Success {
+		return parse.Failure("reference", parse.Errorf(pos, "invalid entity reference"))
+	}
+	ref := r.Item.(string)
+	if !strings.HasPrefix(ref, "#") {
+		s, ok := entities[ref]
+		if !ok {
+			return parse.Failure("reference", parse.Errorf(pos, "unknown entity &%s;", ref))
+		}
+		return parse.Success("reference", s, nil)
+	}
+	base, digits := 10, ref[1:]
+	if strings.HasPrefix(digits, "x") {
+		base, digits = 16, digits[1:]
+	}
+	v, err := strconv.ParseUint(digits, base, 32)
+	if err != nil || !isChar(rune(v)) {
+		return parse.Failure("reference", parse.Errorf(pos, "invalid character reference &%s;", ref))
+	}
+	return parse.Success("reference", string(rune(v)), nil)
+}
+
+// isChar returns true if the rune is allowed in an XML document.
+func isChar(r rune) bool {
+	return r == 0x09 || r == 0x0A || r == 0x0D ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
+
+// maxTextRunes limits the length of CharData tokens, so that the input's buffer doesn't
+// need to hold a whole run of text.
+const maxTextRunes = 4096
+
+var text = parse.Many(parse.WithStringConcatCombiner, 0, 16,
+	parse.Any(parse.Span(parse.ClassOf("<&]").Not(), 1, maxTextRunes), reference, bracket))
+
+var cdataEnd = parse.String("]]>")
+
+// bracket captures a ']' within text, which mustn't be the start of the end of a CDATA section.
+func bracket(pi parse.Input) parse.Result {
+	pos := parse.PosOf(pi)
+	if r := parse.Accept(pi, cdataEnd); r.Success {
+		return parse.Failure("char data", parse.Errorf(pos, "']]>' is not allowed in character data"))
+	}
+	return parse.Rune(']')(pi)
+}
+
+// charData captures text up to the next tag. Unlike Many with a minimum of one, it fails
+// without an error when there's no text, so that it can be used within Any.
+func charData(pi parse.Input) parse.Result {
+	r := text(pi)
+	if !r.Success {
+		return r
+	}
+	if r.Item == "" {
+		return parse.Failure("char data", nil)
+	}
+	return parse.Success("char data", CharData(r.Item.(string)), nil)
+}
+
+var asNormalizedString parse.MultipleResultCombiner = func(items []interface{}) (interface{}, bool) {
+	s := items[0].(string)
+	return strings.Map(func(r rune) rune {
+		if whitespaceClass.Contains(r) {
+			return ' '
+		}
+		return r
+	}, s), true
+}
+
+func quotedValue(quote rune) parse.Function {
+	return parse.All(func(items []interface{}) (interface{}, bool) {
+		return items[1], true
+	}, parse.Rune(quote), parse.Many(parse.WithStringConcatCombiner, 0, 0, parse.Any(
+		parse.All(asNormalizedString, parse.Span(parse.ClassOf("<&"+string(quote)).Not(), 1, 0)),
+		reference,
+	)), parse.Rune(quote))
+}
+
+var attrValue = parse.Or(quotedValue('"'), quotedValue('\''))
+
+var attr = parse.All(func(items []interface{}) (interface{}, bool) {
+	return Attr{
+		Name:  items[1].(Name),
+		Value: items[5].(string),
+	}, true
+}, whitespace, name, optionalWhitespace, parse.Rune('='), optionalWhitespace, attrValue)
+
+var startElement = parse.All(func(items []interface{}) (interface{}, bool) {
+	attrs := make([]Attr, len(items[2].([]interface{})))
+	for i, a := range items[2].([]interface{}) {
+		attrs[i] = a.(Attr)
+	}
+	return StartElement{
+		Name:        items[1].(Name),
+		Attr:        attrs,
+		SelfClosing: items[4] == "/>",
+	}, true
+},
+	parse.Rune('<'),
+	name,
+	parse.Many(func(items []interface{}) (interface{}, bool) { return items, true }, 0, 0, attr),
+	optionalWhitespace,
+	parse.Or(parse.String("/>"), parse.String(">")),
+)
+
+var endElement = parse.All(func(items []interface{}) (interface{}, bool) {
+	return EndElement{Name: items[1].(Name)}, true
+}, parse.String("</"), name, optionalWhitespace, parse.Rune('>'))
+
+// until captures the text before the end, which it doesn't consume. It fails with an error if
+// there are more than max runes before the end, so that the input's buffer doesn't need to hold
+// an unlimited amount of text.
+func until(name, end string, max int) parse.Function {
+	delimiter := parse.String(end)
+	return func(pi parse.Input) parse.Result {
+		pos := parse.PosOf(pi)
+		var sb strings.Builder
+		for n := 0; ; n++ {
+			current := pi.Index()
+			if delimiter(pi).Success {
+				parse.RewindTo(pi, current)
+				return parse.Success(name, sb.String(), nil)
+			}
+			if n == max {
+				return parse.Failure(name, parse.Errorf(pos, "%s is longer than %d runes", name, max))
+			}
+			r, err := pi.Advance()
+			if err != nil {
+				return parse.Failure(name, err)
+			}
+			sb.WriteRune(r)
+		}
+	}
+}
+
+// comment captures a comment of up to max runes, which mustn't contain "--", or end with '-'.
+func comment(max int) parse.Function {
+	syntax := parse.All(func(items []interface{}) (interface{}, bool) {
+		return Comment(items[1].(string)), true
+	}, parse.String("<!--"), until("comment", "-->", max), parse.String("-->"))
+	return func(pi parse.Input) parse.Result {
+		pos := parse.PosOf(pi)
+		r := syntax(pi)
+		if !r.Success {
+			return r
+		}
+		s := string(r.Item.(Comment))
+		i := strings.Index(s, "--")
+		if i < 0 && strings.HasSuffix(s, "-") {
+			i = len(s) - 1
+		}
+		if i >= 0 {
+			parse.RewindTo(pi, pos.Index)
+			return parse.Failure("comment", parse.Errorf(advance(pos, "<!--"+s[:i]), "'--' is not allowed in a comment"))
+		}
+		return r
+	}
+}
+
+// cdata captures a CDATA section of up to max runes.
+func cdata(max int) parse.Function {
+	return parse.All(func(items []interface{}) (interface{}, bool) {
+		return CData(items[1].(string)), true
+	}, parse.String("<![CDATA["), until("CDATA section", "]]>", max), parse.String("]]>"))
+}
+
+// procInst captures a processing instruction of up to max runes.
+func procInst(max int) parse.Function {
+	return parse.All(func(items []interface{}) (interface{}, bool) {
+		return ProcInst{
+			Target: items[1].(string),
+			Inst:   items[2].(string),
+		}, true
+	},
+		parse.String("<?"),
+		rawName,
+		parse.Optional(parse.WithStringConcatCombiner, parse.Then(func(items []interface{}) (interface{}, bool) {
+			return items[1], true
+		}, whitespace, until("processing instruction", "?>", max))),
+		parse.String("?>"),
+	)
+}
+
+// directive captures a directive such as <!DOCTYPE ...> of up to max runes, which may contain
+// an internal subset in square brackets, and quoted strings which contain '>'.
+func directive(max int) parse.Function {
+	return func(pi parse.Input) parse.Result {
+		name := "directive"
+		start := pi.Index()
+		if r := parse.String("<!")(pi); !r.Success {
+			return r
+		}
+		pos := parse.PosOf(pi)
+		var sb strings.Builder
+		var depth int
+		var quote rune
+		for n := 0; ; n++ {
+			r, err := pi.Advance()
+			if err != nil {
+				parse.RewindTo(pi, start)
+				return parse.Failure(name, err)
+			}
+			switch {
+			case quote != 0:
+				if r == quote {
+					quote = 0
+				}
+			case r == '"' || r == '\'':
+				quote = r
+			case r == '[':
+				depth++
+			case r == ']':
+				depth--
+			case r == '>' && depth <= 0:
+				return parse.Success(name, Directive(sb.String()), nil)
+			}
+			if n == max {
+				parse.RewindTo(pi, start)
+				return parse.Failure(name, parse.Errorf(pos, "directive is longer than %d runes", max))
+			}
+			sb.WriteRune(r)
+		}
+	}
+}
+
+// tokenParser returns a parser of a token, where comments, CDATA sections, processing
+// instructions and directives are limited to max runes.
+func tokenParser(max int) parse.Function {
+	return parse.WithRange(parse.Any(comment(max), cdata(max), directive(max), procInst(max), endElement, startElement, charData))
+}
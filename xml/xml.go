@@ -0,0 +1,222 @@
+// Package xml is a streaming XML tokenizer built from the parse package's combinators.
+//
+// A Decoder reads one token at a time from a scanner.Scanner, so the input is collected as
+// it's read, and only the current token needs to be held in memory. This allows files which
+// are too large to fit into memory to be processed, as long as individual tags are of a
+// reasonable size. Long runs of text are split into multiple tokens, and comments, CDATA
+// sections, processing instructions and directives longer than the Decoder's MaxMarkupRunes
+// are an error.
+//
+// Each token is returned with the range of the input it was parsed from. Entity references
+// are decoded, namespace prefixes are resolved, and the document is checked to ensure that
+// end tags match their start tags, and that there's a single root element, with no text outside
+// of it.
+package xml
+
+import (
+	"io"
+	"strings"
+
+	"github.com/a-h/lexical/parse"
+	"github.com/a-h/lexical/scanner"
+)
+
+const (
+	xmlURL   = "http://www.w3.org/XML/1998/namespace"
+	xmlnsURL = "http://www.w3.org/2000/xmlns/"
+)
+
+// DefaultMaxMarkupRunes is the MaxMarkupRunes of a Decoder created by NewDecoder.
+const DefaultMaxMarkupRunes = 1 << 20
+
+// Decoder reads XML tokens from an input.
+type Decoder struct {
+	// MaxMarkupRunes limits the length of comments, CDATA sections, processing instructions and
+	// directives, which are each returned as a single token, so that the memory used to read
+	// them is bounded. Longer ones fail with a *parse.Error. If it's zero or less,
+	// DefaultMaxMarkupRunes is used. It must be set before the first call to Token.
+	MaxMarkupRunes int
+
+	input   *parse.FurthestInput
+	scanner *scanner.Scanner
+	parser  parse.Function
+	// open contains the elements which have been started, but not ended.
+	open []element
+	// closed is true when the root element has ended.
+	closed bool
+	// pending is the EndElement which follows a self closing StartElement.
+	pending *parse.Ranged
+	err     error
+}
+
+type element struct {
+	name Name
+	// namespaces are the prefixes declared by the element.
+	namespaces map[string]string
+}
+
+// NewDecoder creates a Decoder which reads from the input.
+func NewDecoder(pi parse.Input) *Decoder {
+	fi := parse.NewFurthestInput(pi)
+	d := &Decoder{
+		MaxMarkupRunes: DefaultMaxMarkupRunes,
+		input:          fi,
+	}
+	d.scanner = scanner.New(fi, d.next)
+	return d
+}
+
+// next captures a token, or returns io.EOF at the end of the input.
+func (d *Decoder) next(pi parse.Input) parse.Result {
+	if parse.EOF(pi).Success {
+		return parse.Failure("xml", io.EOF)
+	}
+	if d.parser == nil {
+		max := d.MaxMarkupRunes
+		if max <= 0 {
+			max = DefaultMaxMarkupRunes
+		}
+		d.parser = tokenParser(max)
+	}
+	return d.parser(pi)
+}
+
+// Token returns the next token from the input. The item of the returned parse.Ranged is one
+// of StartElement, EndElement, CharData, CData, Comment, ProcInst or Directive. At the end
+// of the input, io.EOF is returned. If the document is invalid, the error is a *parse.Error
+// which contains the position of the problem, and all further calls return the same error.
+func (d *Decoder) Token() (t parse.Ranged, err error) {
+	if d.err != nil {
+		return t, d.err
+	}
+	if d.pending != nil {
+		t, d.pending = *d.pending, nil
+		d.end()
+		return t, nil
+	}
+	t, err = d.token()
+	if err != nil {
+		d.err = err
+	}
+	return t, err
+}
+
+func (d *Decoder) token() (t parse.Ranged, err error) {
+	item, err := d.scanner.Next()
+	if err == io.EOF {
+		if len(d.open) > 0 {
+			return t, parse.Errorf(parse.PosOf(d.input), "unexpected end of input, element <%s> is not closed", d.open[len(d.open)-1].name)
+		}
+		return t, io.EOF
+	}
+	if _, ok := err.(*scanner.UnmatchedError); ok {
+		return t, d.input.Unexpected()
+	}
+	if err != nil {
+		return t, err
+	}
+	t = item.(parse.Ranged)
+	switch v := t.Item.(type) {
+	case StartElement:
+		if d.closed {
+			return t, parse.Errorf(t.Range.Start, "element <%s> is outside of the root element", v.Name)
+		}
+		if err = d.start(&v, t.Range); err != nil {
+			return t, err
+		}
+		t.Item = v
+		if v.SelfClosing {
+			d.pending = &parse.Ranged{Range: t.Range, Item: EndElement{Name: v.Name}}
+		}
+	case CharData:
+		if len(d.open) > 0 {
+			break
+		}
+		s := string(v)
+		if i := strings.IndexFunc(s, func(r rune) bool { return !whitespaceClass.Contains(r) }); i >= 0 {
+			return t, parse.Errorf(advance(t.Range.Start, s[:i]), "text is not allowed outside of the root element")
+		}
+	case EndElement:
+		if len(d.open) == 0 {
+			return t, parse.Errorf(t.Range.Start, "unexpected end element </%s>", v.Name)
+		}
+		top := d.open[len(d.open)-1]
+		if top.name.String() != v.Name.String() {
+			return t, parse.Errorf(t.Range.Start, "element <%s> closed by </%s>", top.name, v.Name)
+		}
+		t.Item = EndElement{Name: top.name}
+		d.end()
+	}
+	return t, nil
+}
+
+// end closes the innermost open element.
+func (d *Decoder) end() {
+	d.open = d.open[:len(d.open)-1]
+	d.closed = len(d.open) == 0
+}
+
+// advance returns the position after the text from the position.
+func advance(pos parse.Pos, text string) parse.Pos {
+	for _, r := range text {
+		pos.Index++
+		pos.Column++
+		if r == '\n' {
+			pos.Line++
+			pos.Column = 0
+		}
+	}
+	return pos
+}
+
+// start declares the element's namespaces, and resolves the prefixes of its name and
+// attributes.
+func (d *Decoder) start(se *StartElement, r parse.Range) error {
+	e := element{}
+	seen := make(map[string]bool, len(se.Attr))
+	for i, a := range se.Attr {
+		if seen[a.Name.String()] {
+			return parse.Errorf(r.Start, "duplicate attribute %s in element <%s>", a.Name, se.Name)
+		}
+		seen[a.Name.String()] = true
+		switch {
+		case a.Name.Prefix == "xmlns":
+			e.declare(a.Name.Local, a.Value)
+		case a.Name.Prefix == "" && a.Name.Local == "xmlns":
+			e.declare("", a.Value)
+		default:
+			continue
+		}
+		se.Attr[i].Name.Space = xmlnsURL
+	}
+	d.open = append(d.open, e)
+	se.Name.Space = d.lookup(se.Name.Prefix)
+	for i, a := range se.Attr {
+		if a.Name.Prefix != "" && a.Name.Prefix != "xmlns" {
+			se.Attr[i].Name.Space = d.lookup(a.Name.Prefix)
+		}
+	}
+	d.open[len(d.open)-1].name = se.Name
+	return nil
+}
+
+func (e *element) declare(prefix, url string) {
+	if e.namespaces == nil {
+		e.namespaces = make(map[string]string)
+	}
+	e.namespaces[prefix] = url
+}
+
+// lookup returns the namespace that the prefix refers to within the open elements, or the
+// prefix itself if it hasn't been declared.
+func (d *Decoder) lookup(prefix string) string {
+	if prefix == "xml" {
+		return xmlURL
+	}
+	for i := len(d.open) - 1; i >= 0; i-- {
+		if url, ok := d.open[i].namespaces[prefix]; ok {
+			return url
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,442 @@
+package xml
+
+import (
+	stdxml "encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/a-h/lexical/input"
+	"github.com/a-h/lexical/parse"
+)
+
+func tokens(t *testing.T, doc string) (items []interface{}, err error) {
+	t.Helper()
+	d := NewDecoder(input.NewFromString(doc))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return items, err
+		}
+		items = append(items, tok.Item)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []interface{}
+	}{
+		{
+			input: `<?xml version="1.0" encoding="utf-8"?><a/>`,
+			expected: []interface{}{
+				ProcInst{Target: "xml", Inst: `version="1.0" encoding="utf-8"`},
+				StartElement{Name: Name{Local: "a"}, Attr: []Attr{}, SelfClosing: true},
+				EndElement{Name: Name{Local: "a"}},
+			},
+		},
+		{
+			input: `<a x="1" y = '2'>text</a >`,
+			expected: []interface{}{
+				StartElement{Name: Name{Local: "a"}, Attr: []Attr{
+					{Name: Name{Local: "x"}, Value: "1"},
+					{Name: Name{Local: "y"}, Value: "2"},
+				}},
+				CharData("text"),
+				EndElement{Name: Name{Local: "a"}},
+			},
+		},
+		{
+			input: "\n<a>x[1] ]] ]&gt;</a>\n",
+			expected: []interface{}{
+				CharData("\n"),
+				StartElement{Name: Name{Local: "a"}, Attr: []Attr{}},
+				CharData("x[1] ]] ]>"),
+				EndElement{Name: Name{Local: "a"}},
+				CharData("\n"),
+			},
+		},
+		{
+			input: `<a>&lt;b&gt; &amp; &apos;&quot; &#65;&#x42;&#x1F600;</a>`,
+			expected: []interface{}{
+				StartElement{Name: Name{Local: "a"}, Attr: []Attr{}},
+				CharData(`<b> & '" AB😀`),
+				EndElement{Name: Name{Local: "a"}},
+			},
+		},
+		{
+			input: "<a v=\"x&amp;y\n\tz &#10;\"/>",
+			expected: []interface{}{
+				StartElement{Name: Name{Local: "a"}, Attr: []Attr{
+					{Name: Name{Local: "v"}, Value: "x&y  z \n"},
+				}, SelfClosing: true},
+				EndElement{Name: Name{Local: "a"}},
+			},
+		},
+		{
+			input: `<a><!-- a <comment> --><![CDATA[<not> &a tag]]></a>`,
+			expected: []interface{}{
+				StartElement{Name: Name{Local: "a"}, Attr: []Attr{}},
+				Comment(" a <comment> "),
+				CData("<not> &a tag"),
+				EndElement{Name: Name{Local: "a"}},
+			},
+		},
+		{
+			input: `<!DOCTYPE note [<!ENTITY x "a>b">]><note/>`,
+			expected: []interface{}{
+				Directive(`DOCTYPE note [<!ENTITY x "a>b">]`),
+				StartElement{Name: Name{Local: "note"}, Attr: []Attr{}, SelfClosing: true},
+				EndElement{Name: Name{Local: "note"}},
+			},
+		},
+		{
+			input: `<r xmlns="urn:default" xmlns:p="urn:p"><p:a p:x="1" y="2"><b xml:lang="en"/></p:a><q:c/></r>`,
+			expected: []interface{}{
+				StartElement{Name: Name{Space: "urn:default", Local: "r"}, Attr: []Attr{
+					{Name: Name{Space: xmlnsURL, Local: "xmlns"}, Value: "urn:default"},
+					{Name: Name{Space: xmlnsURL, Prefix: "xmlns", Local: "p"}, Value: "urn:p"},
+				}},
+				StartElement{Name: Name{Space: "urn:p", Prefix: "p", Local: "a"}, Attr: []Attr{
+					{Name: Name{Space: "urn:p", Prefix: "p", Local: "x"}, Value: "1"},
+					{Name: Name{Local: "y"}, Value: "2"},
+				}},
+				StartElement{Name: Name{Space: "urn:default", Local: "b"}, Attr: []Attr{
+					{Name: Name{Space: xmlURL, Prefix: "xml", Local: "lang"}, Value: "en"},
+				}, SelfClosing: true},
+				EndElement{Name: Name{Space: "urn:default", Local: "b"}},
+				EndElement{Name: Name{Space: "urn:p", Prefix: "p", Local: "a"}},
+				StartElement{Name: Name{Space: "q", Prefix: "q", Local: "c"}, Attr: []Attr{}, SelfClosing: true},
+				EndElement{Name: Name{Space: "q", Prefix: "q", Local: "c"}},
+				EndElement{Name: Name{Space: "urn:default", Local: "r"}},
+			},
+		},
+		{
+			input: "<élément données=\"ü\">\n</élément>",
+			expected: []interface{}{
+				StartElement{Name: Name{Local: "élément"}, Attr: []Attr{
+					{Name: Name{Local: "données"}, Value: "ü"},
+				}},
+				CharData("\n"),
+				EndElement{Name: Name{Local: "élément"}},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		actual, err := tokens(t, test.input)
+		if err != nil {
+			t.Errorf("test %v: for input '%v' unexpected error: %v", i, test.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("test %v: for input '%v'\nexpected %#v\ngot      %#v", i, test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    `<a>&nbsp;</a>`,
+			expected: "line 1, column 3: unknown entity &nbsp;",
+		},
+		{
+			input:    `<a>AT&T</a>`,
+			expected: "line 1, column 5: invalid entity reference",
+		},
+		{
+			input:    `<a>&#0;</a>`,
+			expected: "line 1, column 3: invalid character reference &#0;",
+		},
+		{
+			input:    "<a>\n  <b></c>\n</a>",
+			expected: "line 2, column 5: element <b> closed by </c>",
+		},
+		{
+			input:    `<a></a></b>`,
+			expected: "line 1, column 7: unexpected end element </b>",
+		},
+		{
+			input:    `<a><b></b>`,
+			expected: "line 1, column 10: unexpected end of input, element <a> is not closed",
+		},
+		{
+			input:    `<a x="1" x="2"/>`,
+			expected: "line 1, column 0: duplicate attribute x in element <a>",
+		},
+		{
+			input:    `<a x=1/>`,
+			expected: "line 1, column 5: unexpected '1'",
+		},
+		{
+			input:    `<a x="<"/>`,
+			expected: "line 1, column 6: unexpected '<'",
+		},
+		{
+			input:    `<a><!-- unterminated`,
+			expected: "line 1, column 20: unexpected end of input",
+		},
+		{
+			input:    `<a>x]]>y</a>`,
+			expected: "line 1, column 4: ']]>' is not allowed in character data",
+		},
+		{
+			input:    `<a>]]]]></a>`,
+			expected: "line 1, column 5: ']]>' is not allowed in character data",
+		},
+		{
+			input:    "text<a/>",
+			expected: "line 1, column 0: text is not allowed outside of the root element",
+		},
+		{
+			input:    "<a/>\n  text",
+			expected: "line 2, column 2: text is not allowed outside of the root element",
+		},
+		{
+			input:    "<a/><b/>",
+			expected: "line 1, column 4: element <b> is outside of the root element",
+		},
+		{
+			input:    "<a></a>\n<b>x</b>",
+			expected: "line 2, column 0: element <b> is outside of the root element",
+		},
+		{
+			input:    "<a><!-- c -- d --></a>",
+			expected: "line 1, column 10: '--' is not allowed in a comment",
+		},
+		{
+			input:    "<a><!-- c ---></a>",
+			expected: "line 1, column 10: '--' is not allowed in a comment",
+		},
+	}
+
+	for i, test := range tests {
+		_, err := tokens(t, test.input)
+		if err == nil {
+			t.Errorf("test %v: for input '%v' expected error %q, got nil", i, test.input, test.expected)
+			continue
+		}
+		if _, ok := err.(*parse.Error); !ok {
+			t.Errorf("test %v: for input '%v' expected *parse.Error, got %T", i, test.input, err)
+		}
+		if err.Error() != test.expected {
+			t.Errorf("test %v: for input '%v' expected error %q, got %q", i, test.input, test.expected, err.Error())
+		}
+	}
+}
+
+func TestErrorsAreRepeated(t *testing.T) {
+	d := NewDecoder(input.NewFromString(`<a>&bad;</a>`))
+	d.Token()
+	_, first := d.Token()
+	_, second := d.Token()
+	if first == nil || first != second {
+		t.Errorf("expected the same error to be returned, got %v and %v", first, second)
+	}
+}
+
+func TestTokenRanges(t *testing.T) {
+	d := NewDecoder(input.NewFromString("<a>\n  <b/>x</a>"))
+	expected := []parse.Range{
+		{Start: parse.Pos{Index: 0, Line: 1, Column: 0}, End: parse.Pos{Index: 3, Line: 1, Column: 3}},
+		{Start: parse.Pos{Index: 3, Line: 1, Column: 3}, End: parse.Pos{Index: 6, Line: 2, Column: 2}},
+		{Start: parse.Pos{Index: 6, Line: 2, Column: 2}, End: parse.Pos{Index: 10, Line: 2, Column: 6}},
+		{Start: parse.Pos{Index: 6, Line: 2, Column: 2}, End: parse.Pos{Index: 10, Line: 2, Column: 6}},
+		{Start: parse.Pos{Index: 10, Line: 2, Column: 6}, End: parse.Pos{Index: 11, Line: 2, Column: 7}},
+		{Start: parse.Pos{Index: 11, Line: 2, Column: 7}, End: parse.Pos{Index: 15, Line: 2, Column: 11}},
+	}
+	for i, e := range expected {
+		tok, err := d.Token()
+		if err != nil {
+			t.Fatalf("token %d: unexpected error: %v", i, err)
+		}
+		if tok.Range != e {
+			t.Errorf("token %d (%#v): expected range %v, got %v", i, tok.Item, e, tok.Range)
+		}
+	}
+	if _, err := d.Token(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestLongTextIsSplit(t *testing.T) {
+	text := strings.Repeat("abcdefgh", maxTextRunes*4)
+	actual, err := tokens(t, "<a>"+text+"</a>")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var sb strings.Builder
+	var count int
+	for _, item := range actual {
+		if cd, ok := item.(CharData); ok {
+			sb.WriteString(string(cd))
+			count++
+		}
+	}
+	if count < 2 {
+		t.Errorf("expected the text to be split into multiple tokens, got %d", count)
+	}
+	if sb.String() != text {
+		t.Errorf("expected the text to be returned in full, got %d runes", sb.Len())
+	}
+}
+
+func TestMaxMarkupRunes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input: "<a><!--12345678--></a>",
+		},
+		{
+			input:    "<a><!--123456789--></a>",
+			expected: "line 1, column 7: comment is longer than 8 runes",
+		},
+		{
+			input: "<a><![CDATA[12345678]]></a>",
+		},
+		{
+			input:    "<a><![CDATA[123456789]]></a>",
+			expected: "line 1, column 12: CDATA section is longer than 8 runes",
+		},
+		{
+			input: "<?pi 12345678?><a/>",
+		},
+		{
+			input:    "<?pi 123456789?><a/>",
+			expected: "line 1, column 5: processing instruction is longer than 8 runes",
+		},
+		{
+			input: "<!DOCTYPE><a/>",
+		},
+		{
+			input:    "<!DOCTYPE a><a/>",
+			expected: "line 1, column 2: directive is longer than 8 runes",
+		},
+	}
+
+	for i, test := range tests {
+		d := NewDecoder(input.NewFromString(test.input))
+		d.MaxMarkupRunes = 8
+		var err error
+		for err == nil {
+			_, err = d.Token()
+		}
+		if err == io.EOF {
+			err = nil
+		}
+		var actual string
+		if err != nil {
+			actual = err.Error()
+		}
+		if actual != test.expected {
+			t.Errorf("test %v: for input '%v' expected error %q, got %q", i, test.input, test.expected, actual)
+		}
+	}
+}
+
+// TestEncodingXML checks that the same elements, text and attributes are returned as
+// encoding/xml.
+func TestEncodingXML(t *testing.T) {
+	doc := `<?xml version="1.0"?>
+<!DOCTYPE catalog>
+<catalog xmlns:h="urn:h">
+  <!-- books -->
+  <book id="1" h:lang="en">Go &amp; XML &#169;</book>
+  <empty/>
+  <code><![CDATA[if a < b {}]]></code>
+</catalog>`
+	var expected []string
+	sd := stdxml.NewDecoder(strings.NewReader(doc))
+	var text strings.Builder
+	for {
+		tok, err := sd.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("encoding/xml: %v", err)
+		}
+		if cd, ok := tok.(stdxml.CharData); ok {
+			text.Write(cd)
+			continue
+		}
+		if text.Len() > 0 {
+			expected = append(expected, "text "+text.String())
+			text.Reset()
+		}
+		switch v := tok.(type) {
+		case stdxml.StartElement:
+			s := "start " + v.Name.Space + " " + v.Name.Local
+			for _, a := range v.Attr {
+				s += " " + a.Name.Space + ":" + a.Name.Local + "=" + a.Value
+			}
+			expected = append(expected, s)
+		case stdxml.EndElement:
+			expected = append(expected, "end "+v.Name.Space+" "+v.Name.Local)
+		}
+	}
+
+	items, err := tokens(t, doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var actual []string
+	text.Reset()
+	for _, item := range items {
+		switch v := item.(type) {
+		case CharData:
+			text.WriteString(string(v))
+			continue
+		case CData:
+			text.WriteString(string(v))
+			continue
+		}
+		if text.Len() > 0 {
+			actual = append(actual, "text "+text.String())
+			text.Reset()
+		}
+		switch v := item.(type) {
+		case StartElement:
+			s := "start " + v.Name.Space + " " + v.Name.Local
+			for _, a := range v.Attr {
+				space := a.Name.Space
+				if space == xmlnsURL {
+					space = "xmlns"
+				}
+				s += " " + space + ":" + a.Name.Local + "=" + a.Value
+			}
+			actual = append(actual, s)
+		case EndElement:
+			actual = append(actual, "end "+v.Name.Space+" "+v.Name.Local)
+		}
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+func BenchmarkDecoder(b *testing.B) {
+	doc := `<catalog>` + strings.Repeat(`<book id="1" lang="en">Go &amp; XML</book><empty/>`, 100) + `</catalog>`
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		d := NewDecoder(input.NewFromString(doc))
+		for {
+			if _, err := d.Token(); err != nil {
+				if err != io.EOF {
+					b.Fatal(err)
+				}
+				break
+			}
+		}
+	}
+}